@@ -2,9 +2,16 @@ package cassandra
 
 import (
 	"context"
-	"errors"
 	"fmt"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+	"kubedb.dev/db-client-go/retry"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -12,16 +19,24 @@ import (
 	"kubedb.dev/apimachinery/apis/kubedb"
 
 	"github.com/gocql/gocql"
+	"k8s.io/client-go/rest"
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type KubeDBClientBuilder struct {
-	kc   client.Client
-	db   *api.Cassandra
-	url  string
-	port *int
-	ctx  context.Context
+	kc             client.Client
+	db             *api.Cassandra
+	url            string
+	resolver       *endpoint.Resolver
+	port           *int
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *api.Cassandra) *KubeDBClientBuilder {
@@ -36,6 +51,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPort(port *int) *KubeDBClientBuilder {
 	o.port = port
 	return o
@@ -45,10 +67,54 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	o.ctx = ctx
 	return o
 }
+
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetCassandraClient() (*Client, error) {
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(api.ResourceKindCassandra, o.db.Namespace, o.db.Name, "")...)
+
 	host := o.url
+	if host == "" {
+		host = o.resolver.ServiceHost(o.db.ServiceName(), o.db.Namespace)
+	}
 	cluster := gocql.NewCluster(host)
-	cluster.Port = kubedb.CassandraNativeTcpPort
+	cluster.Port = o.resolver.Port(api.ResourceKindCassandra, kubedb.CassandraNativeTcpPort)
+	if o.port != nil {
+		cluster.Port = *o.port
+	}
 	cluster.Keyspace = "system"
 	if o.db.Spec.Topology == nil {
 		cluster.Consistency = gocql.One
@@ -58,14 +124,16 @@ func (o *KubeDBClientBuilder) GetCassandraClient() (*Client, error) {
 	if !o.db.Spec.DisableSecurity {
 
 		authSecret := &core.Secret{}
-		err := o.kc.Get(o.ctx, types.NamespacedName{
-			Namespace: o.db.Namespace,
-			Name:      o.db.GetAuthSecretName(),
-		}, authSecret)
+		err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+			return o.kc.Get(ctx, types.NamespacedName{
+				Namespace: o.db.Namespace,
+				Name:      o.db.GetAuthSecretName(),
+			}, authSecret)
+		})
 		if err != nil {
 			if kerr.IsNotFound(err) {
 				klog.Error(err, "AuthSecret not found")
-				return nil, errors.New("auth-secret not found")
+				return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "auth-secret not found")
 			}
 			return nil, err
 		}
@@ -76,9 +144,23 @@ func (o *KubeDBClientBuilder) GetCassandraClient() (*Client, error) {
 			Password: password,
 		}
 	}
-	session, err := cluster.CreateSession()
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		cluster.Dialer = dial
+	}
+	var session *gocql.Session
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpDial, func(ctx context.Context) error {
+			var err error
+			session, err = cluster.CreateSession()
+			return err
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("unable to connect to Cassandra cluster: %v", err)
+		return nil, fmt.Errorf("unable to connect to Cassandra cluster: %w", dberrors.Classify(err))
 	}
 
 	return &Client{session}, nil
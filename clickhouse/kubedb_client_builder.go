@@ -19,9 +19,16 @@ package clickhouse
 import (
 	"context"
 	"database/sql"
-	"fmt"
+
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
 
 	_ "github.com/ClickHouse/clickhouse-go/v2"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 	"kubedb.dev/apimachinery/apis/kubedb"
@@ -30,12 +37,17 @@ import (
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *olddbapi.ClickHouse
-	url     string
-	podName string
-	port    *int
-	ctx     context.Context
+	kc             client.Client
+	db             *olddbapi.ClickHouse
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	port           *int
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *olddbapi.ClickHouse) *KubeDBClientBuilder {
@@ -50,6 +62,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	return o
@@ -65,10 +84,31 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetClickHouseClient() (*Client, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(olddbapi.ResourceKindClickHouse, o.db.Namespace, o.db.Name, o.podName)...)
 
 	connector, err := o.getConnectionString()
 	if err != nil {
@@ -80,22 +120,20 @@ func (o *KubeDBClientBuilder) GetClickHouseClient() (*Client, error) {
 		return nil, err
 	}
 	// ping to database to check the connection
-	if err := db.PingContext(o.ctx); err != nil {
+	if err := o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, db.PingContext)
+	}); err != nil {
 		closeErr := db.Close()
 		if closeErr != nil {
 			klog.Errorf("Failed to close client. error: %v", closeErr)
 		}
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 	return &Client{db}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.db.GoverningServiceName(), o.db.Namespace)
-}
-
 func (o *KubeDBClientBuilder) getPort() *int {
-	chPort := kubedb.ClickHouseNativeTCP
+	chPort := o.resolver.Port(olddbapi.ResourceKindClickHouse, kubedb.ClickHouseNativeTCP)
 	return &chPort
 }
 
@@ -108,17 +146,19 @@ func (o *KubeDBClientBuilder) getClickHouseRootCredentials() (string, string, er
 		return kubedb.ClickHouseDefaultUser, "", nil
 	}
 	var secret core.Secret
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	user, ok := secret.Data[core.BasicAuthUsernameKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root user is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root user is not set")
 	}
 	pass, ok := secret.Data[core.BasicAuthPasswordKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root password is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root password is not set")
 	}
 	return string(user), string(pass), nil
 }
@@ -130,12 +170,14 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	}
 
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodHost(o.podName, o.db.GoverningServiceName(), o.db.Namespace)
+	}
+	if o.url == "" {
+		o.url = o.resolver.ServiceHost(o.db.ServiceName(), o.db.Namespace)
 	}
 
 	if o.port == nil {
 		o.port = o.getPort()
 	}
-	connector := fmt.Sprintf("clickhouse://%s:%d?username=%s&password=%s", o.url, *o.port, user, pass)
-	return connector, nil
+	return dsn.ClickHouse(user, pass, endpoint.JoinHostPort(o.url, *o.port)), nil
 }
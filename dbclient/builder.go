@@ -0,0 +1,239 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbclient
+
+import (
+	"context"
+	"fmt"
+
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+	"kubedb.dev/db-client-go/retry"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Builder is the common interface implemented for every KubeDB database kind.
+// Build returns the same client the database specific KubeDBClientBuilder
+// would return (e.g. *mysql.Client, *elasticsearch.Client, *redis.Client),
+// and Close releases the connection held by the last built client.
+type Builder interface {
+	WithPod(podName string) Builder
+	WithURL(url string) Builder
+	WithContext(ctx context.Context) Builder
+	WithResolver(r *endpoint.Resolver) Builder
+	WithRetryPolicy(p *retry.Policy) Builder
+	WithTracerProvider(tp trace.TracerProvider) Builder
+	WithMeterProvider(mp metric.MeterProvider) Builder
+	WithDialer(dial portforward.DialFunc) Builder
+	WithPortForward(config *rest.Config) Builder
+	Build() (interface{}, error)
+	Close() error
+}
+
+// buildFunc builds the database specific client and returns a function
+// to close it. closer can be nil for clients that hold no connection.
+type buildFunc func(o *builder) (client interface{}, closer func() error, err error)
+
+// the Builder options that a database kind can mark as unsupported
+const (
+	optionPod            = "WithPod"
+	optionRetryPolicy    = "WithRetryPolicy"
+	optionTracerProvider = "WithTracerProvider"
+	optionMeterProvider  = "WithMeterProvider"
+)
+
+type builder struct {
+	ctx            context.Context
+	podName        string
+	url            string
+	resolver       *endpoint.Resolver
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
+	build          buildFunc
+	closer         func() error
+
+	// set by the database kinds that can use dialer and restConfig
+	dialerSupported bool
+	// set by the database kinds that ignore some of the options
+	unsupported []string
+
+	// set by For, used as telemetry attributes
+	kind string
+	obj  client.Object
+}
+
+var _ Builder = &builder{}
+
+func newBuilder(build buildFunc) *builder {
+	return &builder{
+		build: build,
+	}
+}
+
+// newDialerBuilder returns a builder for a database kind that passes
+// dialer and restConfig through to its KubeDBClientBuilder.
+func newDialerBuilder(build buildFunc) *builder {
+	b := newBuilder(build)
+	b.dialerSupported = true
+	return b
+}
+
+// without marks the options that the database kind ignores, Build fails
+// when any of them is set.
+func (o *builder) without(options ...string) *builder {
+	o.unsupported = append(o.unsupported, options...)
+	return o
+}
+
+func (o *builder) isSet(option string) bool {
+	switch option {
+	case optionPod:
+		return o.podName != ""
+	case optionRetryPolicy:
+		return o.retryPolicy != nil
+	case optionTracerProvider:
+		return o.tracerProvider != nil
+	case optionMeterProvider:
+		return o.meterProvider != nil
+	}
+	return false
+}
+
+// WithPod connects to the pod instead of the service.
+// Build fails for the database kinds that always connect to the service.
+func (o *builder) WithPod(podName string) Builder {
+	o.podName = podName
+	return o
+}
+
+func (o *builder) WithURL(url string) Builder {
+	o.url = url
+	return o
+}
+
+func (o *builder) WithContext(ctx context.Context) Builder {
+	o.ctx = ctx
+	return o
+}
+
+// WithResolver sets the resolver of the in-cluster addresses used when url
+// is not set, e.g. to use a custom cluster domain or port.
+func (o *builder) WithResolver(r *endpoint.Resolver) Builder {
+	o.resolver = r
+	return o
+}
+
+// WithRetryPolicy retries the connection to the database with the policy.
+// Build fails for the database kinds whose clients do not connect on Build.
+func (o *builder) WithRetryPolicy(p *retry.Policy) Builder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithTracerProvider enables tracing of Build and of the builder phases, i.e.
+// the secret fetch, the TLS load, the dial and the ping. Build fails for the
+// RestProxy, SchemaRegistry and ElasticsearchDashboard kinds, whose builders
+// are not instrumented.
+func (o *builder) WithTracerProvider(tp trace.TracerProvider) Builder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of Build and of the
+// builder phases, see WithTracerProvider. Build fails for the kinds that
+// WithTracerProvider is not supported for.
+func (o *builder) WithMeterProvider(mp metric.MeterProvider) Builder {
+	o.meterProvider = mp
+	return o
+}
+
+// WithDialer sets the function used to open the connections to the database.
+// Build fails for the database kinds that do not support a custom dialer.
+func (o *builder) WithDialer(dial portforward.DialFunc) Builder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward connects to the database pods through the port-forward API
+// of the Kubernetes API server. It is ignored when a dialer is set.
+// Build fails for the database kinds that do not support a custom dialer.
+func (o *builder) WithPortForward(config *rest.Config) Builder {
+	o.restConfig = config
+	return o
+}
+
+func (o *builder) Build() (interface{}, error) {
+	c, closer, err := o.buildClient()
+	if err != nil {
+		return nil, err
+	}
+	// only the last built client is tracked, close the previous one
+	if err := o.Close(); err != nil {
+		klog.Errorf("Failed to close client. error: %v", err)
+	}
+	o.closer = closer
+	return c, nil
+}
+
+// buildClient builds a client without tracking it, the caller is responsible
+// for closing it with closer.
+func (o *builder) buildClient() (interface{}, func() error, error) {
+	if o.ctx == nil {
+		o.ctx = context.Background()
+	}
+	if !o.dialerSupported && (o.dialer != nil || o.restConfig != nil) {
+		return nil, nil, fmt.Errorf("custom dialer is not supported for %s", o.kind)
+	}
+	for _, option := range o.unsupported {
+		if o.isSet(option) {
+			return nil, nil, fmt.Errorf("%s is not supported for %s", option, o.kind)
+		}
+	}
+
+	var namespace, name string
+	if o.obj != nil {
+		namespace, name = o.obj.GetNamespace(), o.obj.GetName()
+	}
+	tm := telemetry.New(o.tracerProvider, o.meterProvider, telemetry.Attributes(o.kind, namespace, name, o.podName)...)
+	// the span context is only used while building, so that the spans of
+	// the builder phases are children of the build span
+	ctx := o.ctx
+	var end func(error)
+	o.ctx, end = tm.Start(ctx, "build")
+	c, closer, err := o.build(o)
+	end(err)
+	o.ctx = ctx
+	return c, closer, err
+}
+
+func (o *builder) Close() error {
+	if o.closer == nil {
+		return nil
+	}
+	closer := o.closer
+	o.closer = nil
+	return closer()
+}
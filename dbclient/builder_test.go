@@ -0,0 +1,77 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbclient
+
+import (
+	"testing"
+
+	"kubedb.dev/db-client-go/retry"
+
+	"go.opentelemetry.io/otel/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kapi "kubedb.dev/apimachinery/apis/kafka/v1alpha1"
+)
+
+func TestBuildUnsupportedOption(t *testing.T) {
+	restProxy := &kapi.RestProxy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kapi.SchemeGroupVersion.String(), Kind: kapi.ResourceKindRestProxy},
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "restproxy"},
+	}
+	tests := []struct {
+		name    string
+		with    func(b Builder) Builder
+		wantErr string
+	}{
+		{
+			name: "no options",
+			with: func(b Builder) Builder { return b },
+		},
+		{
+			name:    "pod",
+			with:    func(b Builder) Builder { return b.WithPod("restproxy-0") },
+			wantErr: "WithPod is not supported for RestProxy",
+		},
+		{
+			name:    "retry policy",
+			with:    func(b Builder) Builder { return b.WithRetryPolicy(retry.DefaultPolicy()) },
+			wantErr: "WithRetryPolicy is not supported for RestProxy",
+		},
+		{
+			name:    "tracer provider",
+			with:    func(b Builder) Builder { return b.WithTracerProvider(trace.NewNoopTracerProvider()) },
+			wantErr: "WithTracerProvider is not supported for RestProxy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := For(nil, restProxy)
+			if err != nil {
+				t.Fatalf("failed to get builder: %v", err)
+			}
+			_, err = tt.with(b).Build()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.wantErr {
+				t.Fatalf("got error %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,689 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbclient
+
+import (
+	"fmt"
+
+	"kubedb.dev/db-client-go/cassandra"
+	"kubedb.dev/db-client-go/clickhouse"
+	"kubedb.dev/db-client-go/druid"
+	"kubedb.dev/db-client-go/elasticsearch"
+	"kubedb.dev/db-client-go/elasticsearchdashboard"
+	"kubedb.dev/db-client-go/kafka"
+	"kubedb.dev/db-client-go/kafka/connect"
+	"kubedb.dev/db-client-go/kafka/restproxy"
+	"kubedb.dev/db-client-go/kafka/schemaregistry"
+	"kubedb.dev/db-client-go/mariadb"
+	"kubedb.dev/db-client-go/mongodb"
+	mssql "kubedb.dev/db-client-go/mssqlserver"
+	"kubedb.dev/db-client-go/mysql"
+	"kubedb.dev/db-client-go/perconaxtradb"
+	"kubedb.dev/db-client-go/pgbouncer"
+	"kubedb.dev/db-client-go/pgpool"
+	"kubedb.dev/db-client-go/postgres"
+	"kubedb.dev/db-client-go/proxysql"
+	"kubedb.dev/db-client-go/rabbitmq"
+	"kubedb.dev/db-client-go/redis"
+	"kubedb.dev/db-client-go/redissentinel"
+	"kubedb.dev/db-client-go/singlestore"
+	"kubedb.dev/db-client-go/solr"
+	"kubedb.dev/db-client-go/zookeeper"
+
+	catalog "kubedb.dev/apimachinery/apis/catalog/v1alpha1"
+	esapi "kubedb.dev/apimachinery/apis/elasticsearch/v1alpha1"
+	kafkaapi "kubedb.dev/apimachinery/apis/kafka"
+	kapi "kubedb.dev/apimachinery/apis/kafka/v1alpha1"
+	"kubedb.dev/apimachinery/apis/kubedb"
+	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
+	olddbapi "kubedb.dev/apimachinery/apis/kubedb/v1alpha2"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func init() {
+	Register(kubedbKind(olddbapi.ResourceKindCassandra), newCassandraBuilder)
+	Register(kubedbKind(olddbapi.ResourceKindClickHouse), newClickHouseBuilder)
+	Register(kubedbKind(olddbapi.ResourceKindDruid), newDruidBuilder)
+	Register(kubedbKind(dbapi.ResourceKindElasticsearch), newElasticsearchBuilder)
+	Register(esapi.SchemeGroupVersion.WithKind(esapi.ResourceKindElasticsearchDashboard).GroupKind(), newElasticsearchDashboardBuilder)
+	Register(kubedbKind(dbapi.ResourceKindKafka), newKafkaBuilder)
+	Register(kafkaKind(kapi.ResourceKindConnectCluster), newConnectClusterBuilder)
+	Register(kafkaKind(kapi.ResourceKindRestProxy), newRestProxyBuilder)
+	Register(kafkaKind(kapi.ResourceKindSchemaRegistry), newSchemaRegistryBuilder)
+	Register(kubedbKind(dbapi.ResourceKindMariaDB), newMariaDBBuilder)
+	Register(kubedbKind(dbapi.ResourceKindMongoDB), newMongoDBBuilder)
+	Register(kubedbKind(olddbapi.ResourceKindMSSQLServer), newMSSQLServerBuilder)
+	Register(kubedbKind(dbapi.ResourceKindMySQL), newMySQLBuilder)
+	Register(kubedbKind(dbapi.ResourceKindPerconaXtraDB), newPerconaXtraDBBuilder)
+	Register(kubedbKind(dbapi.ResourceKindPgBouncer), newPgBouncerBuilder)
+	Register(kubedbKind(olddbapi.ResourceKindPgpool), newPgpoolBuilder)
+	Register(kubedbKind(dbapi.ResourceKindPostgres), newPostgresBuilder)
+	Register(kubedbKind(dbapi.ResourceKindProxySQL), newProxySQLBuilder)
+	Register(kubedbKind(olddbapi.ResourceKindRabbitmq), newRabbitMQBuilder)
+	Register(kubedbKind(dbapi.ResourceKindRedis), newRedisBuilder)
+	Register(kubedbKind(dbapi.ResourceKindRedisSentinel), newRedisSentinelBuilder)
+	Register(kubedbKind(olddbapi.ResourceKindSinglestore), newSinglestoreBuilder)
+	Register(kubedbKind(olddbapi.ResourceKindSolr), newSolrBuilder)
+	Register(kubedbKind(olddbapi.ResourceKindZooKeeper), newZooKeeperBuilder)
+}
+
+func kubedbKind(kind string) schema.GroupKind {
+	return schema.GroupKind{Group: kubedb.GroupName, Kind: kind}
+}
+
+func kafkaKind(kind string) schema.GroupKind {
+	return schema.GroupKind{Group: kafkaapi.GroupName, Kind: kind}
+}
+
+func newCassandraBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*olddbapi.Cassandra)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindCassandra), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		// url defaults to the primary service
+		c, err := cassandra.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetCassandraClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, func() error {
+			c.Close()
+			return nil
+		}, nil
+	}).without(optionPod), nil
+}
+
+func newClickHouseBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*olddbapi.ClickHouse)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindClickHouse), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		// url defaults to the primary service
+		c, err := clickhouse.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetClickHouseClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newDruidBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*olddbapi.Druid)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindDruid), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		// WithURL falls back to the coordinators address when url is empty
+		c, err := druid.NewKubeDBClientBuilder(kc, db).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithNodeRole(olddbapi.DruidNodeRoleCoordinators).
+			WithURL(o.url).
+			GetDruidClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}).without(optionRetryPolicy), nil
+}
+
+func newElasticsearchBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.Elasticsearch)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindElasticsearch), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := elasticsearch.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetElasticClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, nil, nil
+	}), nil
+}
+
+func newElasticsearchDashboardBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	dashboard, ok := obj.(*esapi.ElasticsearchDashboard)
+	if !ok {
+		return nil, unexpectedType(esapi.SchemeGroupVersion.WithKind(esapi.ResourceKindElasticsearchDashboard).GroupKind(), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		if dashboard.Spec.DatabaseRef == nil {
+			return nil, nil, fmt.Errorf("databaseRef is not set for ElasticsearchDashboard %s/%s", dashboard.Namespace, dashboard.Name)
+		}
+		var db dbapi.Elasticsearch
+		err := kc.Get(o.ctx, client.ObjectKey{Namespace: dashboard.Namespace, Name: dashboard.Spec.DatabaseRef.Name}, &db)
+		if err != nil {
+			return nil, nil, err
+		}
+		var esVersion catalog.ElasticsearchVersion
+		err = kc.Get(o.ctx, client.ObjectKey{Name: db.Spec.Version}, &esVersion)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		b := elasticsearchdashboard.NewKubeDBClientBuilder(kc, dashboard).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithURL(o.url).
+			WithDatabaseRef(&db).
+			WithDbVersion(&esVersion)
+		if !db.Spec.DisableSecurity {
+			secretName := db.GetAuthSecretName()
+			if dashboard.Spec.AuthSecret != nil && dashboard.Spec.AuthSecret.Name != "" {
+				secretName = dashboard.Spec.AuthSecret.Name
+			}
+			var authSecret core.Secret
+			err = kc.Get(o.ctx, client.ObjectKey{Namespace: dashboard.Namespace, Name: secretName}, &authSecret)
+			if err != nil {
+				return nil, nil, err
+			}
+			b = b.WithAuthSecret(&authSecret)
+		}
+
+		c, err := b.GetElasticsearchDashboardClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, nil, nil
+	}).without(optionPod, optionRetryPolicy, optionTracerProvider, optionMeterProvider), nil
+}
+
+func newKafkaBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.Kafka)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindKafka), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		// url defaults to the kafka service
+		c, err := kafka.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetKafkaClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newConnectClusterBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	connectCluster, ok := obj.(*kapi.ConnectCluster)
+	if !ok {
+		return nil, unexpectedType(kafkaKind(kapi.ResourceKindConnectCluster), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := connect.NewKubeDBClientBuilder(kc, connectCluster).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetConnectClusterClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, nil, nil
+	}).without(optionRetryPolicy), nil
+}
+
+func newRestProxyBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	restProxy, ok := obj.(*kapi.RestProxy)
+	if !ok {
+		return nil, unexpectedType(kafkaKind(kapi.ResourceKindRestProxy), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		b := restproxy.NewKubeDBClientBuilder(kc, restProxy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx)
+		// url defaults to the rest proxy service
+		if o.url != "" {
+			b = b.WithURL(o.url)
+		}
+		c, err := b.GetRestProxyClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, nil, nil
+	}).without(optionPod, optionRetryPolicy, optionTracerProvider, optionMeterProvider), nil
+}
+
+func newSchemaRegistryBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	registry, ok := obj.(*kapi.SchemaRegistry)
+	if !ok {
+		return nil, unexpectedType(kafkaKind(kapi.ResourceKindSchemaRegistry), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		b := schemaregistry.NewKubeDBClientBuilder(kc, registry).
+			WithResolver(o.resolver).
+			WithContext(o.ctx)
+		// url defaults to the schema registry service
+		if o.url != "" {
+			b = b.WithURL(o.url)
+		}
+		c, err := b.GetSchemaRegistryClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, nil, nil
+	}).without(optionPod, optionRetryPolicy, optionTracerProvider, optionMeterProvider), nil
+}
+
+func newMariaDBBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.MariaDB)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindMariaDB), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := mariadb.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetMariaDBClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newMongoDBBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.MongoDB)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindMongoDB), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		b := mongodb.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url)
+		// WithPod also switches the client to direct connection
+		if o.podName != "" {
+			b = b.WithPod(o.podName)
+		}
+		c, err := b.GetMongoClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, func() error {
+			c.Close()
+			return nil
+		}, nil
+	}), nil
+}
+
+func newMSSQLServerBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*olddbapi.MSSQLServer)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindMSSQLServer), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := mssql.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetMSSQLXormClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newMySQLBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.MySQL)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindMySQL), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := mysql.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetMySQLClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newPerconaXtraDBBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.PerconaXtraDB)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindPerconaXtraDB), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := perconaxtradb.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetPerconaXtradbClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newPgBouncerBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	pb, ok := obj.(*dbapi.PgBouncer)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindPgBouncer), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := pgbouncer.NewKubeDBClientBuilder(kc, pb).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			WithDatabaseRef(&pb.Spec.Database).
+			WithDatabaseName("").
+			GetPgBouncerXormClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}).without(optionRetryPolicy), nil
+}
+
+func newPgpoolBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	pp, ok := obj.(*olddbapi.Pgpool)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindPgpool), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := pgpool.NewKubeDBClientBuilder(kc, pp).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetPgpoolXormClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newPostgresBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.Postgres)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindPostgres), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := postgres.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetPostgresClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newProxySQLBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.ProxySQL)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindProxySQL), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := proxysql.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetProxySQLClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newRabbitMQBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*olddbapi.RabbitMQ)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindRabbitmq), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := rabbitmq.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithAMQPURL(o.url).
+			GetRabbitMQClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, func() error {
+			if c.AMQPClient.Connection == nil {
+				return nil
+			}
+			return c.AMQPClient.Close()
+		}, nil
+	}), nil
+}
+
+func newRedisBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.Redis)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindRedis), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		b := redis.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig)
+		if db.Spec.Mode == dbapi.RedisModeCluster {
+			c, err := b.GetRedisClusterClient(o.ctx)
+			if err != nil {
+				return nil, nil, err
+			}
+			return c, c.Close, nil
+		}
+		c, err := b.GetRedisClient(o.ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newRedisSentinelBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*dbapi.RedisSentinel)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(dbapi.ResourceKindRedisSentinel), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := redissentinel.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetRedisSentinelClient(o.ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newSinglestoreBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*olddbapi.Singlestore)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindSinglestore), obj)
+	}
+	return newBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := singlestore.NewKubeDBClientBuilder(kc, db).
+			WithRetryPolicy(o.retryPolicy).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetSinglestoreClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, c.Close, nil
+	}), nil
+}
+
+func newSolrBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*olddbapi.Solr)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindSolr), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := solr.NewKubeDBClientBuilder(kc, db).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetSolrClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, nil, nil
+	}).without(optionRetryPolicy), nil
+}
+
+func newZooKeeperBuilder(kc client.Client, obj client.Object) (Builder, error) {
+	db, ok := obj.(*olddbapi.ZooKeeper)
+	if !ok {
+		return nil, unexpectedType(kubedbKind(olddbapi.ResourceKindZooKeeper), obj)
+	}
+	return newDialerBuilder(func(o *builder) (interface{}, func() error, error) {
+		c, err := zookeeper.NewKubeDBClientBuilder(kc, db).
+			WithResolver(o.resolver).
+			WithContext(o.ctx).
+			WithDialer(o.dialer).
+			WithPortForward(o.restConfig).
+			WithPod(o.podName).
+			WithTracerProvider(o.tracerProvider).
+			WithMeterProvider(o.meterProvider).
+			WithURL(o.url).
+			GetZooKeeperClient()
+		if err != nil {
+			return nil, nil, err
+		}
+		return c, func() error {
+			c.Close()
+			return nil
+		}, nil
+	}).without(optionRetryPolicy), nil
+}
@@ -0,0 +1,103 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbclient
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// Factory returns a Builder for the given KubeDB object.
+type Factory func(kc client.Client, obj client.Object) (Builder, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[schema.GroupKind]Factory{}
+)
+
+// Register adds a Factory for the given kind. It overrides any previously
+// registered Factory for the same kind.
+func Register(gk schema.GroupKind, factory Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[gk] = factory
+}
+
+// RegisteredKinds returns the kinds that have a registered Factory.
+func RegisteredKinds() []schema.GroupKind {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	kinds := make([]schema.GroupKind, 0, len(registry))
+	for gk := range registry {
+		kinds = append(kinds, gk)
+	}
+	sort.Slice(kinds, func(i, j int) bool {
+		return kinds[i].String() < kinds[j].String()
+	})
+	return kinds
+}
+
+// For returns a Builder for the given KubeDB object. The kind of the object
+// is resolved from the scheme of kc, falling back to the TypeMeta of obj.
+func For(kc client.Client, obj client.Object) (Builder, error) {
+	gk, err := groupKindForObject(kc, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	registryMu.RLock()
+	factory, ok := registry[gk]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no client builder registered for %s", gk)
+	}
+	b, err := factory(kc, obj)
+	if err != nil {
+		return nil, err
+	}
+	if o, ok := b.(*builder); ok {
+		o.kind = gk.Kind
+		o.obj = obj
+	}
+	return b, nil
+}
+
+func groupKindForObject(kc client.Client, obj client.Object) (schema.GroupKind, error) {
+	if obj == nil {
+		return schema.GroupKind{}, fmt.Errorf("object is nil")
+	}
+	if kc != nil && kc.Scheme() != nil {
+		if gvk, err := apiutil.GVKForObject(obj, kc.Scheme()); err == nil {
+			return gvk.GroupKind(), nil
+		}
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Kind == "" {
+		return schema.GroupKind{}, fmt.Errorf("failed to detect kind of %T %s/%s", obj, obj.GetNamespace(), obj.GetName())
+	}
+	return gvk.GroupKind(), nil
+}
+
+func unexpectedType(gk schema.GroupKind, obj client.Object) error {
+	return fmt.Errorf("unexpected object type %T for %s", obj, gk)
+}
@@ -0,0 +1,383 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbclient
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"sync"
+	"time"
+
+	"kubedb.dev/db-client-go/pgbouncer"
+	"kubedb.dev/db-client-go/rabbitmq"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DefaultCloseDelay is the time a replaced client is kept open, so that the
+// callers still holding it can finish their requests.
+const DefaultCloseDelay = 30 * time.Second
+
+// ReloadFunc is called after the client of a Watcher has been rebuilt.
+// On failure, err is set and the previous client is kept.
+type ReloadFunc func(client interface{}, err error)
+
+// Watcher keeps the client of a Builder up to date with the auth and TLS
+// secrets of a database. Whenever the resourceVersion of one of the secrets
+// changes, the client is rebuilt and the previous one is closed after the
+// close delay.
+type Watcher struct {
+	informer    cache.Informer
+	obj         client.Object
+	builder     Builder
+	secretNames map[string]bool
+	onReload    ReloadFunc
+	closeDelay  time.Duration
+
+	// buildMu serializes the builds, which run without holding mu
+	buildMu sync.Mutex
+
+	mu               sync.RWMutex
+	client           interface{}
+	closer           func() error
+	retired          map[*time.Timer]func() error
+	resourceVersions map[string]string
+	registration     toolscache.ResourceEventHandlerRegistration
+	// set once the initial client is built
+	ready bool
+}
+
+// NewWatcher returns a Watcher for the database obj. informer must be a
+// Secret informer, e.g. the one returned by
+// cache.GetInformer(ctx, &core.Secret{}) of a controller-runtime manager.
+// The auth secret and the TLS certificate secrets are detected from obj,
+// more secrets can be added with WithSecretNames.
+func NewWatcher(informer cache.Informer, obj client.Object, b Builder) *Watcher {
+	w := &Watcher{
+		informer:         informer,
+		obj:              obj,
+		builder:          b,
+		secretNames:      map[string]bool{},
+		closeDelay:       DefaultCloseDelay,
+		retired:          map[*time.Timer]func() error{},
+		resourceVersions: map[string]string{},
+	}
+	for _, name := range secretNamesForObject(obj) {
+		w.secretNames[name] = true
+	}
+	return w
+}
+
+func (w *Watcher) WithSecretNames(names ...string) *Watcher {
+	for _, name := range names {
+		w.secretNames[name] = true
+	}
+	return w
+}
+
+func (w *Watcher) WithReloadFunc(fn ReloadFunc) *Watcher {
+	w.onReload = fn
+	return w
+}
+
+// WithCloseDelay sets the time a replaced client is kept open,
+// DefaultCloseDelay by default.
+func (w *Watcher) WithCloseDelay(d time.Duration) *Watcher {
+	w.closeDelay = d
+	return w
+}
+
+// Start starts watching the secrets and builds the client, see StartContext.
+func (w *Watcher) Start() (interface{}, error) {
+	return w.StartContext(context.Background())
+}
+
+// StartContext starts watching the secrets and builds the client once the
+// informer has delivered the secrets, so that a secret change during the
+// build is not missed. The returned client is the initial one; use Client to
+// get the current client. ctx bounds the wait for the informer.
+//
+// Only the single client returned by the builder is rebuilt, the clients
+// holding a list of connections, i.e. *rabbitmq.ConnectionQueue and
+// *pgbouncer.XormClientList, are rejected.
+func (w *Watcher) StartContext(ctx context.Context) (interface{}, error) {
+	w.buildMu.Lock()
+	defer w.buildMu.Unlock()
+
+	if w.started() {
+		return nil, fmt.Errorf("watcher for %s/%s is already started", w.obj.GetNamespace(), w.obj.GetName())
+	}
+	registration, err := w.informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: w.onSecret,
+		UpdateFunc: func(_, newObj interface{}) {
+			w.onSecret(newObj)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	w.mu.Lock()
+	w.registration = registration
+	w.mu.Unlock()
+
+	c, err := w.start(ctx)
+	if err != nil {
+		if err := w.Stop(); err != nil {
+			klog.Errorf("Failed to stop watcher. error: %v", err)
+		}
+		return nil, err
+	}
+	return c, nil
+}
+
+// start builds the initial client until no secret changes during the build.
+func (w *Watcher) start(ctx context.Context) (interface{}, error) {
+	if !toolscache.WaitForCacheSync(ctx.Done(), w.registration.HasSynced) {
+		return nil, fmt.Errorf("failed to wait for the secrets of %s/%s: %w", w.obj.GetNamespace(), w.obj.GetName(), ctx.Err())
+	}
+	for {
+		w.mu.RLock()
+		resourceVersions := maps.Clone(w.resourceVersions)
+		w.mu.RUnlock()
+
+		c, closer, err := w.build()
+		if err != nil {
+			return nil, err
+		}
+		switch c.(type) {
+		case *rabbitmq.ConnectionQueue, *pgbouncer.XormClientList:
+			closeClient(closer)
+			return nil, fmt.Errorf("%T is not supported by the watcher", c)
+		}
+
+		w.mu.Lock()
+		if maps.Equal(resourceVersions, w.resourceVersions) {
+			w.client, w.closer, w.ready = c, closer, true
+			w.mu.Unlock()
+			return c, nil
+		}
+		w.mu.Unlock()
+		klog.V(3).Infof("Secrets of %s/%s have changed while building the client, rebuilding it", w.obj.GetNamespace(), w.obj.GetName())
+		closeClient(closer)
+	}
+}
+
+// Stop stops watching the secrets and closes the current client, along
+// with the replaced clients that are not closed yet.
+func (w *Watcher) Stop() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.registration != nil {
+		if err := w.informer.RemoveEventHandler(w.registration); err != nil {
+			klog.Errorf("Failed to remove secret event handler. error: %v", err)
+		}
+		w.registration = nil
+	}
+	for t, closer := range w.retired {
+		if t.Stop() {
+			closeClient(closer)
+		}
+	}
+	w.retired = map[*time.Timer]func() error{}
+
+	w.client, w.ready = nil, false
+	if w.closer != nil {
+		closer := w.closer
+		w.closer = nil
+		return closer()
+	}
+	return w.builder.Close()
+}
+
+// Client returns the current client.
+func (w *Watcher) Client() interface{} {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.client
+}
+
+func (w *Watcher) started() bool {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.registration != nil
+}
+
+// build returns a new client and the function to close it. The clients of
+// the builders returned by For are not closed by the builder, so that the
+// previous client can be kept open while the callers are using it.
+func (w *Watcher) build() (interface{}, func() error, error) {
+	if b, ok := w.builder.(*builder); ok {
+		return b.buildClient()
+	}
+	c, err := w.builder.Build()
+	return c, nil, err
+}
+
+func (w *Watcher) onSecret(obj interface{}) {
+	secret, ok := obj.(*core.Secret)
+	if !ok || secret.Namespace != w.obj.GetNamespace() || !w.secretNames[secret.Name] {
+		return
+	}
+
+	w.mu.Lock()
+	// the changes before the initial client is built are handled by start,
+	// the secrets first seen after it are created later
+	prev := w.resourceVersions[secret.Name]
+	w.resourceVersions[secret.Name] = secret.ResourceVersion
+	ready := w.ready
+	w.mu.Unlock()
+	if prev == secret.ResourceVersion || !ready {
+		return
+	}
+
+	klog.V(3).Infof("Secret %s/%s has changed, rebuilding client for %s", secret.Namespace, secret.Name, w.obj.GetName())
+	w.buildMu.Lock()
+	defer w.buildMu.Unlock()
+
+	c, closer, err := w.build()
+	if err != nil {
+		klog.Errorf("Failed to rebuild client for %s/%s. error: %v", w.obj.GetNamespace(), w.obj.GetName(), err)
+	} else if !w.swap(c, closer) {
+		// stopped while building
+		closeClient(closer)
+		return
+	}
+	if w.onReload != nil {
+		w.onReload(c, err)
+	}
+}
+
+// swap replaces the current client with c and closes the previous one after
+// the close delay. It returns false if the watcher is stopped.
+func (w *Watcher) swap(c interface{}, closer func() error) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.registration == nil {
+		return false
+	}
+	prev := w.closer
+	w.client, w.closer = c, closer
+	if prev != nil {
+		// the timer func locks mu, so t is set before it runs
+		var t *time.Timer
+		t = time.AfterFunc(w.closeDelay, func() {
+			w.mu.Lock()
+			_, ok := w.retired[t]
+			delete(w.retired, t)
+			w.mu.Unlock()
+			if ok {
+				closeClient(prev)
+			}
+		})
+		w.retired[t] = prev
+	}
+	return true
+}
+
+func closeClient(closer func() error) {
+	if closer == nil {
+		return
+	}
+	if err := closer(); err != nil {
+		klog.Errorf("Failed to close client. error: %v", err)
+	}
+}
+
+// secretNamesForObject returns the auth secret and TLS certificate secrets
+// of a KubeDB object. The names are resolved by the GetAuthSecretName and
+// GetCertSecretName helpers of the object when it has them, which fall back
+// to the default secret names.
+func secretNamesForObject(obj client.Object) []string {
+	u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		klog.Errorf("Failed to convert %s/%s to unstructured. error: %v", obj.GetNamespace(), obj.GetName(), err)
+		return nil
+	}
+
+	var names []string
+	if o, ok := obj.(interface{ GetAuthSecretName() string }); ok {
+		names = append(names, o.GetAuthSecretName())
+	} else if name, _, _ := unstructured.NestedString(u, "spec", "authSecret", "name"); name != "" {
+		names = append(names, name)
+	}
+
+	tls, found, _ := unstructured.NestedMap(u, "spec", "tls")
+	if !found || tls == nil {
+		return names
+	}
+	// the client certificate is used by the builders even if it is not
+	// listed in the spec
+	aliases := []string{"client"}
+	var secretNames []string
+	certs, _, _ := unstructured.NestedSlice(tls, "certificates")
+	for _, cert := range certs {
+		m, ok := cert.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if alias, _, _ := unstructured.NestedString(m, "alias"); alias != "" && alias != "client" {
+			aliases = append(aliases, alias)
+		}
+		if name, _, _ := unstructured.NestedString(m, "secretName"); name != "" {
+			secretNames = append(secretNames, name)
+		}
+	}
+	if certNames, ok := certSecretNames(obj, aliases); ok {
+		return append(names, certNames...)
+	}
+	return append(names, secretNames...)
+}
+
+// certSecretNames calls the GetCertSecretName helper of obj for every alias.
+// The helper takes a kind specific alias type, and for some kinds more string
+// arguments that are left empty, so it is called through reflection.
+func certSecretNames(obj client.Object, aliases []string) ([]string, bool) {
+	m := reflect.ValueOf(obj).MethodByName("GetCertSecretName")
+	if !m.IsValid() {
+		return nil, false
+	}
+	t := m.Type()
+	if t.NumIn() == 0 || t.NumOut() != 1 || t.Out(0).Kind() != reflect.String {
+		return nil, false
+	}
+	for i := 0; i < t.NumIn(); i++ {
+		if t.In(i).Kind() != reflect.String {
+			return nil, false
+		}
+	}
+
+	names := make([]string, 0, len(aliases))
+	for _, alias := range aliases {
+		args := make([]reflect.Value, t.NumIn())
+		args[0] = reflect.ValueOf(alias).Convert(t.In(0))
+		for i := 1; i < len(args); i++ {
+			args[i] = reflect.Zero(t.In(i))
+		}
+		names = append(names, m.Call(args)[0].String())
+	}
+	return names, true
+}
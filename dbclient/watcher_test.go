@@ -0,0 +1,236 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dbclient
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kubedb.dev/db-client-go/pgbouncer"
+	"kubedb.dev/db-client-go/rabbitmq"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	toolscache "k8s.io/client-go/tools/cache"
+	kmapi "kmodules.xyz/client-go/api/v1"
+	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
+)
+
+type fakeRegistration struct{}
+
+func (fakeRegistration) HasSynced() bool { return true }
+
+// fakeInformer delivers secrets as the initial list of the added handlers
+type fakeInformer struct {
+	mu      sync.Mutex
+	handler toolscache.ResourceEventHandler
+	secrets []*core.Secret
+}
+
+func (f *fakeInformer) AddEventHandler(h toolscache.ResourceEventHandler) (toolscache.ResourceEventHandlerRegistration, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handler = h
+	for _, secret := range f.secrets {
+		h.OnAdd(secret, true)
+	}
+	return fakeRegistration{}, nil
+}
+
+func (f *fakeInformer) AddEventHandlerWithResyncPeriod(h toolscache.ResourceEventHandler, _ time.Duration) (toolscache.ResourceEventHandlerRegistration, error) {
+	return f.AddEventHandler(h)
+}
+
+func (f *fakeInformer) RemoveEventHandler(toolscache.ResourceEventHandlerRegistration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.handler = nil
+	return nil
+}
+
+func (f *fakeInformer) AddIndexers(toolscache.Indexers) error { return nil }
+func (f *fakeInformer) HasSynced() bool                       { return true }
+func (f *fakeInformer) IsStopped() bool                       { return false }
+
+func (f *fakeInformer) update(secret *core.Secret) {
+	f.mu.Lock()
+	h := f.handler
+	f.mu.Unlock()
+	h.OnUpdate(nil, secret)
+}
+
+func newTestMySQL(tls *kmapi.TLSConfig) *dbapi.MySQL {
+	return &dbapi.MySQL{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "mysql"},
+		Spec:       dbapi.MySQLSpec{TLS: tls},
+	}
+}
+
+func TestSecretNamesForObject(t *testing.T) {
+	tests := []struct {
+		name string
+		tls  *kmapi.TLSConfig
+		want []string
+	}{
+		{
+			name: "no tls",
+			want: []string{"mysql-auth"},
+		},
+		{
+			name: "default cert names",
+			tls:  &kmapi.TLSConfig{},
+			want: []string{"mysql-auth", "mysql-client-cert"},
+		},
+		{
+			name: "custom cert names",
+			tls: &kmapi.TLSConfig{
+				Certificates: []kmapi.CertificateSpec{
+					{Alias: "client", SecretName: "my-client"},
+					{Alias: "server"},
+				},
+			},
+			want: []string{"mysql-auth", "my-client", "mysql-server-cert"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := secretNamesForObject(newTestMySQL(tt.tls))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWatcherDelaysClose(t *testing.T) {
+	var built atomic.Int32
+	closed := make(chan int32, 10)
+	b := newBuilder(func(*builder) (interface{}, func() error, error) {
+		n := built.Add(1)
+		return n, func() error {
+			closed <- n
+			return nil
+		}, nil
+	})
+
+	secret := &core.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "mysql-auth", ResourceVersion: "1"}}
+	informer := &fakeInformer{secrets: []*core.Secret{secret}}
+	w := NewWatcher(informer, newTestMySQL(nil), b).WithCloseDelay(50 * time.Millisecond)
+	c, err := w.Start()
+	if err != nil {
+		t.Fatalf("failed to start watcher: %v", err)
+	}
+	if c != int32(1) {
+		t.Fatalf("got initial client %v, want 1", c)
+	}
+
+	informer.update(secret)
+	secret = secret.DeepCopy()
+	secret.ResourceVersion = "2"
+	informer.update(secret)
+
+	if c := w.Client(); c != int32(2) {
+		t.Fatalf("got client %v after the secret changed, want 2", c)
+	}
+	select {
+	case n := <-closed:
+		t.Fatalf("client %d was closed before the close delay", n)
+	default:
+	}
+	select {
+	case n := <-closed:
+		if n != 1 {
+			t.Errorf("got closed client %d, want 1", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("previous client was not closed after the close delay")
+	}
+
+	if err := w.Stop(); err != nil {
+		t.Fatalf("failed to stop watcher: %v", err)
+	}
+	if n := <-closed; n != 2 {
+		t.Errorf("got closed client %d on stop, want 2", n)
+	}
+}
+
+func TestWatcherRebuildsOnChangeDuringStart(t *testing.T) {
+	secret := &core.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "mysql-auth", ResourceVersion: "1"}}
+	informer := &fakeInformer{secrets: []*core.Secret{secret}}
+
+	var built atomic.Int32
+	b := newBuilder(func(*builder) (interface{}, func() error, error) {
+		n := built.Add(1)
+		if n == 1 {
+			// the secret is rotated while the initial client is built
+			rotated := secret.DeepCopy()
+			rotated.ResourceVersion = "2"
+			informer.update(rotated)
+		}
+		return n, nil, nil
+	})
+
+	w := NewWatcher(informer, newTestMySQL(nil), b)
+	c, err := w.Start()
+	if err != nil {
+		t.Fatalf("failed to start watcher: %v", err)
+	}
+	if c != int32(2) {
+		t.Errorf("got initial client %v, want 2", c)
+	}
+	if n := built.Load(); n != 2 {
+		t.Errorf("got %d builds, want 2", n)
+	}
+	if err := w.Stop(); err != nil {
+		t.Fatalf("failed to stop watcher: %v", err)
+	}
+}
+
+func TestWatcherRejectsConnectionLists(t *testing.T) {
+	tests := []struct {
+		name   string
+		client interface{}
+	}{
+		{name: "rabbitmq connection queue", client: rabbitmq.NewConnectionQueue()},
+		{name: "pgbouncer client list", client: &pgbouncer.XormClientList{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			closed := false
+			b := newBuilder(func(*builder) (interface{}, func() error, error) {
+				return tt.client, func() error {
+					closed = true
+					return nil
+				}, nil
+			})
+			informer := &fakeInformer{}
+			w := NewWatcher(informer, newTestMySQL(nil), b)
+			if _, err := w.Start(); err == nil {
+				t.Fatal("expected an error")
+			}
+			if !closed {
+				t.Error("rejected client is not closed")
+			}
+			if informer.handler != nil {
+				t.Error("secret event handler is not removed")
+			}
+		})
+	}
+}
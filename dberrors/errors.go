@@ -0,0 +1,165 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package dberrors defines the errors returned by the KubeDB client builders.
+// Use errors.Is with the sentinel errors to detect the kind of a failure, or
+// errors.As with *Error to get the message and cause.
+package dberrors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"syscall"
+
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+)
+
+var (
+	// ErrSecretNotFound is returned when the auth or TLS secret of a database
+	// is not set or does not exist.
+	ErrSecretNotFound = errors.New("secret not found")
+	// ErrInvalidSecret is returned when a required key is missing in a secret.
+	ErrInvalidSecret = errors.New("invalid secret")
+	// ErrUnreachable is returned when the database can't be connected to.
+	ErrUnreachable = errors.New("database is unreachable")
+	// ErrTLSHandshake is returned when the TLS handshake with the database fails.
+	ErrTLSHandshake = errors.New("tls handshake failed")
+	// ErrAuthRejected is returned when the database rejects the credentials.
+	ErrAuthRejected = errors.New("authentication rejected")
+	// ErrUnsupportedVersion is returned when the database version is not supported by the client.
+	ErrUnsupportedVersion = errors.New("unsupported version")
+)
+
+// Error is an error of a known Kind, which is one of the sentinel errors of
+// this package. Err is the underlying cause, if any.
+type Error struct {
+	Kind error
+	Msg  string
+	Err  error
+}
+
+func (e *Error) Error() string {
+	msg := e.Msg
+	if msg == "" {
+		msg = e.Kind.Error()
+	}
+	if e.Err != nil {
+		return msg + ": " + e.Err.Error()
+	}
+	return msg
+}
+
+func (e *Error) Unwrap() []error {
+	if e.Err == nil {
+		return []error{e.Kind}
+	}
+	return []error{e.Kind, e.Err}
+}
+
+func Errorf(kind error, format string, args ...interface{}) error {
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...)}
+}
+
+func Wrap(kind error, err error, msg string) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Msg: msg, Err: err}
+}
+
+func Wrapf(kind error, err error, format string, args ...interface{}) error {
+	if err == nil {
+		return nil
+	}
+	return &Error{Kind: kind, Msg: fmt.Sprintf(format, args...), Err: err}
+}
+
+// SecretGetError classifies the error returned when getting a secret.
+func SecretGetError(err error) error {
+	if kerr.IsNotFound(err) {
+		return Wrap(ErrSecretNotFound, err, "")
+	}
+	return err
+}
+
+// HTTPStatusError returns an error of the kind matching an unexpected http
+// status code returned by the database.
+func HTTPStatusError(statusCode int, format string, args ...interface{}) error {
+	if statusCode == http.StatusUnauthorized || statusCode == http.StatusForbidden {
+		return Errorf(ErrAuthRejected, format, args...)
+	}
+	return fmt.Errorf(format, args...)
+}
+
+// authFailureMessages are the substrings the supported drivers use to report
+// rejected credentials.
+var authFailureMessages = []string{
+	"authentication failed",     // postgres, mongodb, clickhouse, kafka (sasl)
+	"access denied for user",    // mysql, mariadb, percona xtradb, singlestore, proxysql
+	"login failed for user",     // mssql server
+	"wrongpass",                 // redis
+	"noauth",                    // redis
+	"invalid username-password", // redis
+	"access_refused",            // rabbitmq
+	"username and/or password",  // cassandra
+	"unauthorized",              // http based clients
+}
+
+// Classify wraps err with the kind of failure detected from it. err is
+// returned as is, if the kind can't be detected.
+func Classify(err error) error {
+	if err == nil {
+		return nil
+	}
+	var e *Error
+	if errors.As(err, &e) {
+		return err
+	}
+
+	var (
+		certErr     *tls.CertificateVerificationError
+		recordErr   tls.RecordHeaderError
+		alertErr    tls.AlertError
+		unknownCA   x509.UnknownAuthorityError
+		hostnameErr x509.HostnameError
+		certInvalid x509.CertificateInvalidError
+		netErr      net.Error
+	)
+	// some drivers don't wrap the underlying errors, so the message is checked too
+	msg := strings.ToLower(err.Error())
+	switch {
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &unknownCA), errors.As(err, &hostnameErr), errors.As(err, &certInvalid),
+		strings.Contains(msg, "tls: "), strings.Contains(msg, "x509: "):
+		return Wrap(ErrTLSHandshake, err, "")
+	case errors.Is(err, syscall.ECONNREFUSED), errors.Is(err, syscall.EHOSTUNREACH),
+		errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr),
+		strings.Contains(msg, "connection refused"), strings.Contains(msg, "no such host"):
+		return Wrap(ErrUnreachable, err, "")
+	}
+	for _, authMsg := range authFailureMessages {
+		if strings.Contains(msg, authMsg) {
+			return Wrap(ErrAuthRejected, err, "")
+		}
+	}
+	return err
+}
@@ -17,11 +17,15 @@ limitations under the License.
 package druid
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"time"
 
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
+
 	druidgo "github.com/grafadruid/go-druid"
 	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
@@ -29,6 +33,8 @@ import (
 
 type Client struct {
 	*druidgo.Client
+	telemetry  *telemetry.Telemetry
+	pollPolicy *retry.Policy
 }
 
 type DruidTaskType int32
@@ -128,6 +134,19 @@ func (c *Client) CheckDataSourceExistence() (bool, error) {
 // flag == false, corresponds to write check error
 // flag == true, corresponds to read check error
 func CheckDBReadWriteAccess(druidCoordinatorsClient *Client, druidBrokersClient *Client, druidOverlordsClient *Client) (error, bool) {
+	return CheckDBReadWriteAccessContext(context.Background(), druidCoordinatorsClient, druidBrokersClient, druidOverlordsClient)
+}
+
+// CheckDBReadWriteAccessContext is CheckDBReadWriteAccess, the polls and the
+// waits of the check stop when ctx is done.
+func CheckDBReadWriteAccessContext(ctx context.Context, druidCoordinatorsClient *Client, druidBrokersClient *Client, druidOverlordsClient *Client) (error, bool) {
+	ctx, end := druidBrokersClient.telemetry.Start(ctx, "read_write_access")
+	err, isReadErr := checkDBReadWriteAccess(ctx, druidCoordinatorsClient, druidBrokersClient, druidOverlordsClient)
+	end(err)
+	return err, isReadErr
+}
+
+func checkDBReadWriteAccess(ctx context.Context, druidCoordinatorsClient *Client, druidBrokersClient *Client, druidOverlordsClient *Client) (error, bool) {
 	exist, err := druidBrokersClient.CheckDataSourceExistence()
 	if err != nil {
 		klog.Error(err, "Failed to check the existence of kubedb-datasource")
@@ -159,22 +178,24 @@ func CheckDBReadWriteAccess(druidCoordinatorsClient *Client, druidBrokersClient
 	}
 
 	// Submit Ingestion Task and check status
-	if err := druidOverlordsClient.SubmitTaskRecurrently(DruidIngestionTask, DruidHealthCheckDataSource, newData); err != nil {
+	if err := druidOverlordsClient.SubmitTaskRecurrentlyContext(ctx, DruidIngestionTask, DruidHealthCheckDataSource, newData); err != nil {
 		klog.Error(err, "Ingestion task failed")
 		return err, true
 	}
 
 	if !exist {
-		time.Sleep(5 * time.Second)
+		if err := sleep(ctx, 5*time.Second); err != nil {
+			return err, true
+		}
 	}
 
 	// Check if new data can be read
-	if err := druidBrokersClient.checkDBReadAccess(oldData); err != nil {
+	if err := druidBrokersClient.checkDBReadAccess(ctx, oldData); err != nil {
 		return err, true
 	}
 
 	// Drop the unused segments of previous health checks
-	if err := druidOverlordsClient.SubmitTaskRecurrently(DruidKillTask, DruidHealthCheckDataSource, ""); err != nil {
+	if err := druidOverlordsClient.SubmitTaskRecurrentlyContext(ctx, DruidKillTask, DruidHealthCheckDataSource, ""); err != nil {
 		klog.Error(err, "Kill task for dropping unused segment failed")
 		return err, true
 	}
@@ -242,26 +263,55 @@ func (c *Client) updateCoordinatorDynamicConfig(data map[string]interface{}) err
 }
 
 func (c *Client) SubmitTaskRecurrently(taskType DruidTaskType, dataSource string, data string) error {
+	return c.SubmitTaskRecurrentlyContext(context.Background(), taskType, dataSource, data)
+}
+
+// SubmitTaskRecurrentlyContext submits a task and polls its status until it
+// succeeds, the poll policy gives up or ctx is done.
+func (c *Client) SubmitTaskRecurrentlyContext(ctx context.Context, taskType DruidTaskType, dataSource string, data string) error {
 	taskID, err := c.submitTask(taskType, dataSource, data)
 	if err != nil {
 		klog.Error(err, "Failed to submit task")
 		return err
 	}
 
-	var taskStatus bool
-	for i := 0; i < 10; i++ {
-		taskStatus, err = c.CheckTaskStatus(taskID)
+	err = c.getPollPolicy(10).Poll(ctx, func(context.Context) (bool, error) {
+		taskStatus, err := c.CheckTaskStatus(taskID)
 		if err != nil {
 			klog.Error(err, "Failed to check task status")
-			return err
-		}
-		if taskStatus {
-			klog.V(5).Info("Task successful")
-			return nil
+			return false, retry.Permanent(err)
 		}
-		time.Sleep(6 * time.Second)
+		return taskStatus, nil
+	})
+	if errors.Is(err, retry.ErrNotDone) && ctx.Err() == nil {
+		return errors.New("task status is failed")
+	}
+	if err != nil {
+		return err
+	}
+	klog.V(5).Info("Task successful")
+	return nil
+}
+
+// getPollPolicy returns the poll policy of the client, else a policy that
+// checks every 6 seconds up to attempts times.
+func (c *Client) getPollPolicy(attempts int) *retry.Policy {
+	if c.pollPolicy != nil {
+		return c.pollPolicy
+	}
+	return retry.ConstantPolicy(6*time.Second, attempts)
+}
+
+// sleep waits for d, or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
-	return errors.New("task status is failed")
 }
 
 func (c *Client) submitTask(taskType DruidTaskType, dataSource string, data string) (string, error) {
@@ -353,22 +403,29 @@ func (c *Client) CheckTaskStatus(taskID string) (bool, error) {
 	return status == "SUCCESS", nil
 }
 
-func (c *Client) checkDBReadAccess(oldData string) error {
-	for i := 0; i < 5; i++ {
-		klog.V(5).Info("waiting for the segments to be available for query...")
-		time.Sleep(6 * time.Second)
+func (c *Client) checkDBReadAccess(ctx context.Context, oldData string) error {
+	policy := c.getPollPolicy(5)
+	klog.V(5).Info("waiting for the segments to be available for query...")
+	if err := sleep(ctx, policy.Backoff(1)); err != nil {
+		return err
+	}
 
+	err := policy.Poll(ctx, func(context.Context) (bool, error) {
 		data, err := c.GetData()
 		if err != nil {
 			klog.Error(err, "failed to read ingested data")
-			return err
-		}
-		if data != oldData {
-			klog.V(5).Info("successfully read ingested data")
-			return nil
+			return false, retry.Permanent(err)
 		}
+		return data != oldData, nil
+	})
+	if errors.Is(err, retry.ErrNotDone) && ctx.Err() == nil {
+		return errors.New("failed to read ingested data")
+	}
+	if err != nil {
+		return err
 	}
-	return errors.New("failed to read ingested data")
+	klog.V(5).Info("successfully read ingested data")
+	return nil
 }
 
 // Reference: https://druid.apache.org/docs/latest/development/extensions-core/druid-basic-security/#usercredential-management
@@ -0,0 +1,78 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package druid
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"kubedb.dev/db-client-go/retry"
+
+	druidgo "github.com/grafadruid/go-druid"
+)
+
+// newTaskServer serves an overlord whose tasks report status.
+func newTaskServer(t *testing.T, status string) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPost {
+			_, _ = w.Write([]byte(`{"task":"index-1"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":{"status":"` + status + `"}}`))
+	}))
+	t.Cleanup(server.Close)
+	c, err := druidgo.NewClient(server.URL)
+	if err != nil {
+		t.Fatalf("failed to create druid client: %v", err)
+	}
+	return &Client{Client: c}
+}
+
+func TestSubmitTaskRecurrentlyContext(t *testing.T) {
+	c := newTaskServer(t, "SUCCESS")
+	if err := c.SubmitTaskRecurrentlyContext(context.Background(), DruidKillTask, DruidHealthCheckDataSource, ""); err != nil {
+		t.Errorf("got error %v for a successful task", err)
+	}
+
+	c = newTaskServer(t, "RUNNING")
+	c.pollPolicy = retry.ConstantPolicy(time.Millisecond, 3)
+	if err := c.SubmitTaskRecurrentlyContext(context.Background(), DruidKillTask, DruidHealthCheckDataSource, ""); err == nil {
+		t.Error("expected an error when the poll policy gives up")
+	}
+
+	// a policy without limits polls until the context is done
+	c.pollPolicy = retry.ConstantPolicy(time.Millisecond, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() {
+		done <- c.SubmitTaskRecurrentlyContext(ctx, DruidKillTask, DruidHealthCheckDataSource, "")
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("the task was polled after the context was done")
+	}
+}
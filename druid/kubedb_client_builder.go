@@ -20,12 +20,17 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"errors"
-	"fmt"
 	"net/http"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
+
 	druidgo "github.com/grafadruid/go-druid"
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -36,13 +41,18 @@ import (
 )
 
 type KubeDBClientBuilder struct {
-	kc       client.Client
-	db       *olddbapi.Druid
-	url      string
-	podName  string
-	nodeRole olddbapi.DruidNodeRoleType
-	password string
-	ctx      context.Context
+	kc             client.Client
+	db             *olddbapi.Druid
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	nodeRole       olddbapi.DruidNodeRoleType
+	password       string
+	ctx            context.Context
+	pollPolicy     *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, druid *olddbapi.Druid) *KubeDBClientBuilder {
@@ -53,7 +63,7 @@ func NewKubeDBClientBuilder(kc client.Client, druid *olddbapi.Druid) *KubeDBClie
 }
 
 // WithURL must be called after initializing NodeRole
-// by calling WithNodeRole function
+// by calling WithNodeRole function, and after WithResolver
 func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	if url == "" {
 		url = o.GetNodesAddress()
@@ -62,6 +72,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	return o
@@ -72,6 +89,15 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithPollPolicy polls the status of the submitted tasks and the ingested
+// data with the policy. By default, they are checked every 6 seconds, up to
+// 10 and 5 times. A policy without MaxAttempts or MaxElapsedTime polls until
+// the context of the check is done.
+func (o *KubeDBClientBuilder) WithPollPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.pollPolicy = p
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithNodeRole(nodeRole olddbapi.DruidNodeRoleType) *KubeDBClientBuilder {
 	o.nodeRole = nodeRole
 	return o
@@ -82,7 +108,24 @@ func (o *KubeDBClientBuilder) WithPassword(password string) *KubeDBClientBuilder
 	return o
 }
 
+// WithTracerProvider enables tracing of the builder phases and of the
+// instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases
+// and of the instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetDruidClient() (*Client, error) {
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(olddbapi.ResourceKindDruid, o.db.Namespace, o.db.Name, o.podName)...)
+
 	var druidOpts []druidgo.ClientOption
 	// Add druid auth credential to the client
 	if !o.db.Spec.DisableSecurity {
@@ -104,25 +147,34 @@ func (o *KubeDBClientBuilder) GetDruidClient() (*Client, error) {
 		druidOpts = append(druidOpts, *sslOpts)
 	}
 
-	druidClient, err := druidgo.NewClient(o.url, druidOpts...)
+	var druidClient *druidgo.Client
+	err := o.telemetry.Run(o.ctx, telemetry.OpDial, func(context.Context) error {
+		var err error
+		druidClient, err = druidgo.NewClient(o.url, druidOpts...)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 	return &Client{
-		Client: druidClient,
+		Client:     druidClient,
+		telemetry:  o.telemetry,
+		pollPolicy: o.pollPolicy,
 	}, nil
 }
 
 func (o *KubeDBClientBuilder) getClientAuthOpts() (*druidgo.ClientOption, error) {
 	authSecret := &core.Secret{}
-	err := o.kc.Get(o.ctx, types.NamespacedName{
-		Namespace: o.db.Namespace,
-		Name:      o.db.GetAuthSecretName(),
-	}, authSecret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, types.NamespacedName{
+			Namespace: o.db.Namespace,
+			Name:      o.db.GetAuthSecretName(),
+		}, authSecret)
+	})
 	if err != nil {
 		if kerr.IsNotFound(err) {
 			klog.Error(err, "AuthSecret not found")
-			return nil, errors.New("auth-secret not found")
+			return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "auth-secret not found")
 		}
 		return nil, err
 	}
@@ -139,16 +191,19 @@ func (o *KubeDBClientBuilder) getClientAuthOpts() (*druidgo.ClientOption, error)
 	return &druidAuthOpts, nil
 }
 
-func (o *KubeDBClientBuilder) getClientSSLConfig() (*druidgo.ClientOption, error) {
+func (o *KubeDBClientBuilder) getClientSSLConfig() (_ *druidgo.ClientOption, err error) {
+	ctx, end := o.telemetry.Start(o.ctx, telemetry.OpTLSLoad)
+	defer func() { end(err) }()
+
 	certSecret := &core.Secret{}
-	err := o.kc.Get(o.ctx, types.NamespacedName{
+	err = o.kc.Get(ctx, types.NamespacedName{
 		Namespace: o.db.Namespace,
 		Name:      o.db.GetCertSecretName(olddbapi.DruidClientCert),
 	}, certSecret)
 	if err != nil {
 		if kerr.IsNotFound(err) {
 			klog.Error(err, "Client certificate secret not found")
-			return nil, errors.New("client certificate secret is not found")
+			return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "client certificate secret is not found")
 		}
 		klog.Error(err, "Failed to get client certificate Secret")
 		return nil, err
@@ -190,6 +245,9 @@ func (o *KubeDBClientBuilder) GetNodesAddress() string {
 		scheme = "http"
 	}
 
-	baseUrl := fmt.Sprintf("%s://%s-0.%s.%s.svc.cluster.local:%d", scheme, o.db.PetSetName(o.nodeRole), o.db.GoverningServiceName(), o.db.Namespace, o.db.DruidNodeContainerPort(o.nodeRole))
+	// the port depends on the node role and is not overridden by the resolver,
+	// the host ends with .svc.cluster.local unless the cluster domain is set
+	host := o.resolver.OrClusterDomain(endpoint.DefaultClusterDomain).PodHost(o.db.PetSetName(o.nodeRole)+"-0", o.db.GoverningServiceName(), o.db.Namespace)
+	baseUrl := endpoint.URL(scheme, endpoint.JoinHostPort(host, int(o.db.DruidNodeContainerPort(o.nodeRole))))
 	return baseUrl
 }
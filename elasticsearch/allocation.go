@@ -0,0 +1,155 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"kubedb.dev/db-client-go/retry"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+// Values of the cluster.routing.allocation.enable setting.
+const (
+	AllocationEnableAll          = "all"
+	AllocationEnablePrimaries    = "primaries"
+	AllocationEnableNewPrimaries = "new_primaries"
+	AllocationEnableNone         = "none"
+)
+
+const (
+	settingAllocationEnable      = "cluster.routing.allocation.enable"
+	settingAllocationExcludeName = "cluster.routing.allocation.exclude._name"
+)
+
+// allocationPollInterval is the wait between the checks of WaitForGreen and
+// DrainNode. Both wait until the context is done.
+const allocationPollInterval = 5 * time.Second
+
+type clusterSettingsRequest struct {
+	Persistent map[string]interface{} `json:"persistent"`
+}
+
+// persistentSetting returns the body that sets a persistent cluster setting,
+// a nil value resets it to the default.
+func persistentSetting(key string, value interface{}) clusterSettingsRequest {
+	return clusterSettingsRequest{
+		Persistent: map[string]interface{}{key: value},
+	}
+}
+
+// clusterSettingsClient reads and updates the cluster settings.
+type clusterSettingsClient interface {
+	getClusterSettings(ctx context.Context) (*clusterSettingsResponse, error)
+	putClusterSettings(ctx context.Context, settings clusterSettingsRequest) error
+}
+
+// updateAllocationExclude adds and removes nodes from the nodes excluded from
+// allocation, keeping the other excluded nodes. The setting is reset when no
+// node is left.
+func updateAllocationExclude(ctx context.Context, client clusterSettingsClient, add, remove []string) error {
+	settings, err := client.getClusterSettings(ctx)
+	if err != nil {
+		return err
+	}
+	return client.putClusterSettings(ctx, allocationExcludeSetting(mergeNodes(settings.get(settingAllocationExcludeName), add, remove)))
+}
+
+// mergeNodes returns the comma separated nodes of current with add appended
+// and remove dropped, without duplicates.
+func mergeNodes(current string, add, remove []string) []string {
+	removed := map[string]bool{}
+	for _, node := range remove {
+		removed[node] = true
+	}
+	seen := map[string]bool{}
+	var nodes []string
+	for _, node := range append(strings.Split(current, ","), add...) {
+		node = strings.TrimSpace(node)
+		if node == "" || removed[node] || seen[node] {
+			continue
+		}
+		seen[node] = true
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func allocationExcludeSetting(nodes []string) clusterSettingsRequest {
+	if len(nodes) == 0 {
+		return persistentSetting(settingAllocationExcludeName, nil)
+	}
+	return persistentSetting(settingAllocationExcludeName, strings.Join(nodes, ","))
+}
+
+func validateAllocationEnable(mode string) error {
+	switch mode {
+	case AllocationEnableAll, AllocationEnablePrimaries, AllocationEnableNewPrimaries, AllocationEnableNone:
+		return nil
+	}
+	return errors.Errorf("invalid shard allocation mode %q", mode)
+}
+
+func pollAllocation(ctx context.Context, condition func(ctx context.Context) (bool, error)) error {
+	policy := &retry.Policy{
+		InitialInterval: allocationPollInterval,
+		Multiplier:      1,
+	}
+	return policy.Poll(ctx, condition)
+}
+
+// countShardsOnNode returns the number of shards that are allocated to node,
+// including the ones relocating away from it. The node of a relocating shard
+// is reported as "<source> -> <target address> <target id> <target>".
+func countShardsOnNode(shards []ShardInfo, node string) int {
+	count := 0
+	for _, shard := range shards {
+		if fields := strings.Fields(shard.Node); len(fields) > 0 && fields[0] == node {
+			count++
+		}
+	}
+	return count
+}
+
+// waitForNodeDrained waits until no shard is allocated to node.
+func waitForNodeDrained(ctx context.Context, client ESClient, node string) error {
+	return pollAllocation(ctx, func(ctx context.Context) (bool, error) {
+		shards, err := client.ShardStats()
+		if err != nil {
+			return false, errors.Wrap(err, "failed to get shard stats")
+		}
+		count := countShardsOnNode(shards, node)
+		klog.V(5).Infof("%d shards left on node %s", count, node)
+		return count == 0, nil
+	})
+}
+
+// waitForGreen waits until the cluster health is green.
+func waitForGreen(ctx context.Context, client ESClient) error {
+	return pollAllocation(ctx, func(ctx context.Context) (bool, error) {
+		status, err := client.ClusterStatus()
+		if err != nil {
+			return false, errors.Wrap(err, "failed to get cluster status")
+		}
+		klog.V(5).Infof("cluster status is %s", status)
+		return status == ClusterHealthGreen, nil
+	})
+}
@@ -0,0 +1,201 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ShardStateUnassigned   = "UNASSIGNED"
+	ShardStateInitializing = "INITIALIZING"
+	ShardStateStarted      = "STARTED"
+	ShardStateRelocating   = "RELOCATING"
+)
+
+const (
+	AllocationDecisionYes      = "YES"
+	AllocationDecisionNo       = "NO"
+	AllocationDecisionThrottle = "THROTTLE"
+)
+
+// DeciderDiskThreshold is the allocation decider of the disk watermarks.
+const DeciderDiskThreshold = "disk_threshold"
+
+// AllocationExplanation is the response of the cluster allocation explain
+// API for a shard.
+type AllocationExplanation struct {
+	Index                   string                   `json:"index"`
+	Shard                   int                      `json:"shard"`
+	Primary                 bool                     `json:"primary"`
+	CurrentState            string                   `json:"current_state"`
+	CurrentNode             *AllocationNode          `json:"current_node,omitempty"`
+	UnassignedInfo          *UnassignedInfo          `json:"unassigned_info,omitempty"`
+	CanAllocate             string                   `json:"can_allocate,omitempty"`
+	AllocateExplanation     string                   `json:"allocate_explanation,omitempty"`
+	CanRemainOnCurrentNode  string                   `json:"can_remain_on_current_node,omitempty"`
+	CanRebalanceCluster     string                   `json:"can_rebalance_cluster,omitempty"`
+	CanMoveToOtherNode      string                   `json:"can_move_to_other_node,omitempty"`
+	MoveExplanation         string                   `json:"move_explanation,omitempty"`
+	RebalanceExplanation    string                   `json:"rebalance_explanation,omitempty"`
+	NodeAllocationDecisions []NodeAllocationDecision `json:"node_allocation_decisions,omitempty"`
+}
+
+type AllocationNode struct {
+	ID               string `json:"id"`
+	Name             string `json:"name"`
+	TransportAddress string `json:"transport_address"`
+}
+
+type UnassignedInfo struct {
+	Reason               string `json:"reason"`
+	At                   string `json:"at"`
+	Details              string `json:"details,omitempty"`
+	LastAllocationStatus string `json:"last_allocation_status,omitempty"`
+}
+
+type NodeAllocationDecision struct {
+	NodeID           string              `json:"node_id"`
+	NodeName         string              `json:"node_name"`
+	TransportAddress string              `json:"transport_address"`
+	NodeDecision     string              `json:"node_decision"`
+	WeightRanking    int                 `json:"weight_ranking,omitempty"`
+	Deciders         []AllocationDecider `json:"deciders,omitempty"`
+}
+
+type AllocationDecider struct {
+	Decider     string `json:"decider"`
+	Decision    string `json:"decision"`
+	Explanation string `json:"explanation"`
+}
+
+// UnassignedShardDiagnosis explains why a shard copy is unassigned.
+type UnassignedShardDiagnosis struct {
+	Index   string
+	Shard   int
+	Primary bool
+	// Reason is the reason the shard became unassigned, e.g. NODE_LEFT.
+	Reason      string
+	Details     string
+	CanAllocate string
+	Explanation string
+	// NodeDecisions are the nodes that refused the shard, with only the
+	// deciders that refused it.
+	NodeDecisions []NodeAllocationDecision
+	// DiskThresholdExceeded is set when a disk watermark kept the shard off
+	// any node.
+	DiskThresholdExceeded bool
+	// Error is set when the shard copy could not be explained, only Index,
+	// Shard and Primary are set then.
+	Error error
+}
+
+type allocationExplainRequest struct {
+	Index   string `json:"index"`
+	Shard   int    `json:"shard"`
+	Primary bool   `json:"primary"`
+}
+
+func (e *AllocationExplanation) diagnosis() UnassignedShardDiagnosis {
+	d := UnassignedShardDiagnosis{
+		Index:       e.Index,
+		Shard:       e.Shard,
+		Primary:     e.Primary,
+		CanAllocate: e.CanAllocate,
+		Explanation: e.AllocateExplanation,
+	}
+	if e.UnassignedInfo != nil {
+		d.Reason = e.UnassignedInfo.Reason
+		d.Details = e.UnassignedInfo.Details
+	}
+	for _, node := range e.NodeAllocationDecisions {
+		var deciders []AllocationDecider
+		for _, decider := range node.Deciders {
+			if decider.Decision == AllocationDecisionYes {
+				continue
+			}
+			deciders = append(deciders, decider)
+			if decider.Decider == DeciderDiskThreshold && decider.Decision == AllocationDecisionNo {
+				d.DiskThresholdExceeded = true
+			}
+		}
+		if len(deciders) == 0 {
+			continue
+		}
+		node.Deciders = deciders
+		d.NodeDecisions = append(d.NodeDecisions, node)
+	}
+	return d
+}
+
+// diagnoseUnassignedShards explains every unassigned shard copy reported by
+// ShardStats. The replicas of a shard are explained once, as the allocation
+// explain API does not tell them apart. A shard copy that fails to be
+// explained is reported with its error, e.g. when it got assigned meanwhile.
+func diagnoseUnassignedShards(ctx context.Context, client ESClient) ([]UnassignedShardDiagnosis, error) {
+	shards, err := client.ShardStats()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get shard stats")
+	}
+
+	type shardCopy struct {
+		index   string
+		shard   int
+		primary bool
+	}
+	seen := map[shardCopy]struct{}{}
+	var diagnoses []UnassignedShardDiagnosis
+	for _, info := range shards {
+		if info.State != ShardStateUnassigned {
+			continue
+		}
+		primary := info.Prirep == "p"
+		shard, err := strconv.Atoi(info.Shard)
+		if err != nil {
+			diagnoses = append(diagnoses, UnassignedShardDiagnosis{
+				Index:   info.Index,
+				Primary: primary,
+				Error:   errors.Wrapf(err, "failed to parse shard number of index %s", info.Index),
+			})
+			continue
+		}
+		key := shardCopy{index: info.Index, shard: shard, primary: primary}
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+
+		explanation, err := client.ExplainAllocation(ctx, key.index, key.shard, key.primary)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			diagnoses = append(diagnoses, UnassignedShardDiagnosis{
+				Index:   key.index,
+				Shard:   key.shard,
+				Primary: key.primary,
+				Error:   err,
+			})
+			continue
+		}
+		diagnoses = append(diagnoses, explanation.diagnosis())
+	}
+	return diagnoses, nil
+}
@@ -0,0 +1,89 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"testing"
+)
+
+type fakeSettingsClient struct {
+	settings clusterSettingsResponse
+	put      []clusterSettingsRequest
+}
+
+func (f *fakeSettingsClient) getClusterSettings(context.Context) (*clusterSettingsResponse, error) {
+	return &f.settings, nil
+}
+
+func (f *fakeSettingsClient) putClusterSettings(_ context.Context, settings clusterSettingsRequest) error {
+	f.put = append(f.put, settings)
+	return nil
+}
+
+func TestUpdateAllocationExclude(t *testing.T) {
+	tests := []struct {
+		name    string
+		current interface{}
+		add     []string
+		remove  []string
+		want    interface{}
+	}{
+		{
+			name: "exclude from none",
+			add:  []string{"es-0"},
+			want: "es-0",
+		},
+		{
+			name:    "exclude keeps the other nodes",
+			current: "es-0,es-1",
+			add:     []string{"es-2", "es-1"},
+			want:    "es-0,es-1,es-2",
+		},
+		{
+			name:    "include keeps the other nodes",
+			current: "es-0, es-1,es-2",
+			remove:  []string{"es-1"},
+			want:    "es-0,es-2",
+		},
+		{
+			name:    "include the last node",
+			current: "es-1",
+			remove:  []string{"es-1"},
+			want:    nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeSettingsClient{
+				settings: clusterSettingsResponse{
+					Persistent: map[string]interface{}{settingAllocationExcludeName: tt.current},
+					Defaults:   map[string]interface{}{settingAllocationExcludeName: ""},
+				},
+			}
+			if err := updateAllocationExclude(context.Background(), client, tt.add, tt.remove); err != nil {
+				t.Fatalf("failed to update allocation exclude: %v", err)
+			}
+			if len(client.put) != 1 {
+				t.Fatalf("got %d settings updates, want 1", len(client.put))
+			}
+			if got := client.put[0].Persistent[settingAllocationExcludeName]; got != tt.want {
+				t.Errorf("got excluded nodes %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
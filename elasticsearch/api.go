@@ -78,21 +78,86 @@ type WriteRequestIndexBody struct {
 	Type string `json:"_type,omitempty"`
 }
 
+// ESClient is implemented for every supported major version of Elasticsearch
+// and OpenSearch. ClusterHealthInfo, NodesStats and GetIndicesInfo return the
+// raw responses, GetClusterHealth, GetNodesStats and GetIndices return them
+// decoded. ExcludeNodesFromAllocation and IncludeNodesInAllocation add and
+// remove nodes from the excluded nodes, keeping the other excluded nodes.
+// DrainNode and WaitForGreen poll the shards and the cluster health until the
+// context is done, so they should be called with a deadline, as WaitForTask.
+// Reindex, UpdateByQuery and DeleteByQuery start a task and return its id
+// without waiting for it.
 type ESClient interface {
+	AttachLifecyclePolicy(ctx context.Context, template, policy, rolloverAlias string) error
+	CancelTask(ctx context.Context, taskID string) error
 	ClusterHealthInfo() (map[string]interface{}, error)
 	ClusterStatus() (string, error)
 	CountData(index string) (int, error)
 	CreateDBUserRole(ctx context.Context) error
 	CreateIndex(index string) error
+	CreateIndexWithBody(ctx context.Context, index string, definition IndexBody) error
+	CreateSnapshot(ctx context.Context, repository, snapshot string, opts SnapshotOptions) (*SnapshotInfo, error)
+	CreateSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository, verify bool) error
+	DeleteByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error)
 	DeleteIndex(index string) error
+	DeleteAutoFollowPattern(ctx context.Context, remoteCluster, name string) error
+	DeleteComponentTemplate(ctx context.Context, name string) error
+	DeleteIndexTemplate(ctx context.Context, name string) error
+	DeleteLegacyIndexTemplate(ctx context.Context, name string) error
+	DeleteLifecyclePolicy(ctx context.Context, name string) error
+	DeleteRemoteCluster(ctx context.Context, name string) error
+	DeleteSnapshot(ctx context.Context, repository, snapshot string) error
+	DeleteSnapshotRepository(ctx context.Context, name string) error
+	DiagnoseUnassignedShards(ctx context.Context) ([]UnassignedShardDiagnosis, error)
+	DrainNode(ctx context.Context, node string) error
+	ExcludeNodesFromAllocation(ctx context.Context, nodes ...string) error
+	ExplainAllocation(ctx context.Context, index string, shard int, primary bool) (*AllocationExplanation, error)
+	ExplainLifecycle(ctx context.Context, index string) ([]LifecycleExplanation, error)
+	Flush(ctx context.Context, synced bool, indices ...string) error
+	FollowIndex(ctx context.Context, followerIndex string, req FollowRequest) error
+	GetClusterHealth(ctx context.Context) (*ClusterHealth, error)
+	GetComponentTemplate(ctx context.Context, name string) (*ComponentTemplate, error)
+	GetIndices(ctx context.Context) ([]IndexInfo, error)
+	GetIndexTemplate(ctx context.Context, name string) (*IndexTemplate, error)
 	GetIndicesInfo() ([]interface{}, error)
+	GetLifecyclePolicy(ctx context.Context, name string) (*LifecyclePolicyInfo, error)
+	GetLegacyIndexTemplate(ctx context.Context, name string) (*LegacyIndexTemplate, error)
+	GetNodesStats(ctx context.Context) (*NodesStats, error)
 	GetClusterWriteStatus(ctx context.Context, db *dbapi.Elasticsearch) error
 	GetClusterReadStatus(ctx context.Context, db *dbapi.Elasticsearch) error
+	GetTask(ctx context.Context, taskID string) (*TaskResult, error)
 	GetTotalDiskUsage(ctx context.Context) (string, error)
 	GetDBUserRole(ctx context.Context) (error, bool)
+	GetDiskUsageReport(ctx context.Context) (*DiskUsageReport, error)
+	GetFollowerStats(ctx context.Context, followerIndex string) (*FollowerStats, error)
+	GetSnapshotStatus(ctx context.Context, repository, snapshot string) (*SnapshotStatus, error)
+	IncludeNodesInAllocation(ctx context.Context, nodes ...string) error
 	IndexExistsOrNot(index string) error
+	ListSnapshots(ctx context.Context, repository string) ([]SnapshotInfo, error)
+	ListTasks(ctx context.Context, actions ...string) ([]TaskInfo, error)
+	NewBulkIndexer(config BulkIndexerConfig) (BulkIndexer, error)
 	NodesStats() (map[string]interface{}, error)
+	PauseFollow(ctx context.Context, followerIndex string) error
 	ShardStats() ([]ShardInfo, error)
 	PutData(index, id string, data map[string]interface{}) error
+	PutAutoFollowPattern(ctx context.Context, name string, pattern AutoFollowPattern) error
+	PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error
+	PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error
+	PutLegacyIndexTemplate(ctx context.Context, name string, template LegacyIndexTemplate) error
+	PutLifecyclePolicy(ctx context.Context, name string, policy LifecyclePolicy) error
+	PutRemoteCluster(ctx context.Context, cluster RemoteCluster) error
+	Reindex(ctx context.Context, request ReindexRequest) (string, error)
+	ReloadSecureSettings(ctx context.Context, keystorePassword string, nodes ...string) (*ReloadSecureSettingsResult, error)
+	RetryLifecycle(ctx context.Context, index string) error
+	RestoreSnapshot(ctx context.Context, repository, snapshot string, opts RestoreOptions) error
+	ResumeFollow(ctx context.Context, followerIndex string) error
+	RolloverAlias(ctx context.Context, alias, newIndex string, conditions *RolloverConditions) (*RolloverResult, error)
+	SetShardAllocation(ctx context.Context, mode string) error
 	SyncCredentialFromSecret(secret *core.Secret) error
+	Unfollow(ctx context.Context, followerIndex string) error
+	UpdateByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error)
+	UpdateAliases(ctx context.Context, actions ...AliasAction) error
+	VerifySnapshotRepository(ctx context.Context, name string) ([]string, error)
+	WaitForGreen(ctx context.Context) error
+	WaitForTask(ctx context.Context, taskID string) (*TaskResult, error)
 }
@@ -0,0 +1,432 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"kubedb.dev/db-client-go/retry"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	BulkActionIndex  = "index"
+	BulkActionCreate = "create"
+	BulkActionUpdate = "update"
+	BulkActionDelete = "delete"
+)
+
+const (
+	defaultBulkFlushItems    = 1000
+	defaultBulkFlushBytes    = 5 * 1024 * 1024
+	defaultBulkFlushInterval = 30 * time.Second
+)
+
+// errBulkTooManyRequests is returned for the bulk requests and the items that
+// are rejected with status code 429, which are retried.
+var errBulkTooManyRequests = errors.New("bulk request rejected with too many requests")
+
+var errBulkIndexerClosed = errors.New("bulk indexer is closed")
+
+// BulkIndexerConfig configures a BulkIndexer. The zero value of every field
+// uses its default.
+type BulkIndexerConfig struct {
+	// Index is the index of the items that have none.
+	Index string
+	// NumWorkers is the number of workers that flush the items concurrently,
+	// runtime.NumCPU() by default.
+	NumWorkers int
+	// FlushItems and FlushBytes flush the items of a worker when either is
+	// reached, 1000 items or 5MB by default.
+	FlushItems int
+	FlushBytes int
+	// FlushInterval flushes the items of a worker periodically, every 30s by
+	// default.
+	FlushInterval time.Duration
+	// RetryPolicy retries the bulk requests and the items that are rejected
+	// with status code 429. retry.DefaultPolicy() is used by default.
+	RetryPolicy *retry.Policy
+	// Refresh is the refresh parameter of the bulk requests, e.g. "wait_for".
+	Refresh string
+	// OnError is called when a bulk request fails, after its items are
+	// reported as failed.
+	OnError func(ctx context.Context, err error)
+}
+
+// BulkIndexerItem is a document operation. Body is encoded as json, use
+// json.RawMessage for encoded documents. For updates, Body is the body of the
+// update API, e.g. {"doc": ...}. Deletes have no body.
+type BulkIndexerItem struct {
+	// Action is one of the BulkAction constants.
+	Action     string
+	Index      string
+	DocumentID string
+	Body       interface{}
+	// OnSuccess and OnFailure are called from the workers with the result
+	// of the item. err is the error of the bulk request for the items that
+	// were not processed.
+	OnSuccess func(ctx context.Context, item BulkIndexerItem, res BulkResponseItem)
+	OnFailure func(ctx context.Context, item BulkIndexerItem, res BulkResponseItem, err error)
+}
+
+type BulkResponseItem struct {
+	Index      string `json:"_index"`
+	DocumentID string `json:"_id"`
+	Version    int64  `json:"_version"`
+	Result     string `json:"result"`
+	Status     int    `json:"status"`
+	Error      *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
+
+type BulkIndexerStats struct {
+	NumAdded    uint64
+	NumIndexed  uint64
+	NumFailed   uint64
+	NumRetried  uint64
+	NumRequests uint64
+}
+
+// BulkIndexer indexes documents in bulk requests. Add blocks while all the
+// workers are busy, so the producers are slowed down to the pace of the
+// cluster.
+type BulkIndexer interface {
+	Add(ctx context.Context, item BulkIndexerItem) error
+	// Close flushes the added items and stops the workers. When ctx is done
+	// first, the pending bulk requests are cancelled, and Close returns after
+	// the workers have reported their items as failed.
+	Close(ctx context.Context) error
+	Stats() BulkIndexerStats
+}
+
+type bulkResponse struct {
+	Errors bool                          `json:"errors"`
+	Items  []map[string]BulkResponseItem `json:"items"`
+}
+
+// bulkFunc sends a bulk request through a versioned client.
+type bulkFunc func(ctx context.Context, body io.Reader, refresh string) (*bulkResponse, error)
+
+type bulkMeta struct {
+	Index      string `json:"_index,omitempty"`
+	Type       string `json:"_type,omitempty"`
+	DocumentID string `json:"_id,omitempty"`
+}
+
+// bulkItem is an item with its lines of the bulk request body.
+type bulkItem struct {
+	BulkIndexerItem
+	lines []byte
+}
+
+type bulkIndexer struct {
+	config       BulkIndexerConfig
+	bulk         bulkFunc
+	documentType string
+
+	// ctx is the context of the bulk requests, cancelled by Close.
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// closing is closed by Close, which closes queue once the pending Adds
+	// have returned.
+	closing   chan struct{}
+	closeOnce sync.Once
+	adding    sync.WaitGroup
+	mu        sync.Mutex
+	queue     chan bulkItem
+	wg        sync.WaitGroup
+
+	added    atomic.Uint64
+	indexed  atomic.Uint64
+	failed   atomic.Uint64
+	retried  atomic.Uint64
+	requests atomic.Uint64
+}
+
+var _ BulkIndexer = &bulkIndexer{}
+
+// newBulkIndexer starts the workers of a bulk indexer. documentType is the
+// mapping type of the items, required before version 7.
+func newBulkIndexer(config BulkIndexerConfig, bulk bulkFunc, documentType string) (*bulkIndexer, error) {
+	if config.NumWorkers < 0 || config.FlushItems < 0 || config.FlushBytes < 0 || config.FlushInterval < 0 {
+		return nil, errors.New("bulk indexer config must not be negative")
+	}
+	if config.NumWorkers == 0 {
+		config.NumWorkers = runtime.NumCPU()
+	}
+	if config.FlushItems == 0 {
+		config.FlushItems = defaultBulkFlushItems
+	}
+	if config.FlushBytes == 0 {
+		config.FlushBytes = defaultBulkFlushBytes
+	}
+	if config.FlushInterval == 0 {
+		config.FlushInterval = defaultBulkFlushInterval
+	}
+	policy := retry.DefaultPolicy()
+	if config.RetryPolicy != nil {
+		p := *config.RetryPolicy
+		policy = &p
+	}
+	policy.Retryable = func(err error) bool {
+		return errors.Is(err, errBulkTooManyRequests)
+	}
+	config.RetryPolicy = policy
+
+	ctx, cancel := context.WithCancel(context.Background())
+	bi := &bulkIndexer{
+		config:       config,
+		bulk:         bulk,
+		documentType: documentType,
+		ctx:          ctx,
+		cancel:       cancel,
+		closing:      make(chan struct{}),
+		queue:        make(chan bulkItem, config.NumWorkers),
+	}
+	for i := 0; i < config.NumWorkers; i++ {
+		bi.wg.Add(1)
+		go bi.work()
+	}
+	return bi, nil
+}
+
+func (bi *bulkIndexer) Add(ctx context.Context, item BulkIndexerItem) error {
+	lines, err := bi.encode(item)
+	if err != nil {
+		return err
+	}
+
+	bi.mu.Lock()
+	select {
+	case <-bi.closing:
+		bi.mu.Unlock()
+		return errBulkIndexerClosed
+	default:
+	}
+	bi.adding.Add(1)
+	bi.mu.Unlock()
+	defer bi.adding.Done()
+
+	select {
+	case bi.queue <- bulkItem{BulkIndexerItem: item, lines: lines}:
+		bi.added.Add(1)
+		return nil
+	case <-bi.closing:
+		return errBulkIndexerClosed
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (bi *bulkIndexer) Close(ctx context.Context) error {
+	bi.closeOnce.Do(func() {
+		bi.mu.Lock()
+		close(bi.closing)
+		bi.mu.Unlock()
+		go func() {
+			// the blocked Adds return on closing, no item is sent after
+			bi.adding.Wait()
+			close(bi.queue)
+		}()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		bi.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		bi.cancel()
+		return nil
+	case <-ctx.Done():
+		bi.cancel()
+		<-done
+		return ctx.Err()
+	}
+}
+
+func (bi *bulkIndexer) Stats() BulkIndexerStats {
+	return BulkIndexerStats{
+		NumAdded:    bi.added.Load(),
+		NumIndexed:  bi.indexed.Load(),
+		NumFailed:   bi.failed.Load(),
+		NumRetried:  bi.retried.Load(),
+		NumRequests: bi.requests.Load(),
+	}
+}
+
+// encode returns the action line and the body line, if any, of item.
+func (bi *bulkIndexer) encode(item BulkIndexerItem) ([]byte, error) {
+	switch item.Action {
+	case BulkActionIndex, BulkActionCreate, BulkActionUpdate, BulkActionDelete:
+	default:
+		return nil, errors.Errorf("invalid bulk action %q", item.Action)
+	}
+	meta := bulkMeta{
+		Index:      item.Index,
+		Type:       bi.documentType,
+		DocumentID: item.DocumentID,
+	}
+	if meta.Index == "" {
+		meta.Index = bi.config.Index
+	}
+	if meta.Index == "" {
+		return nil, errors.New("index of bulk item is missing")
+	}
+	if meta.DocumentID == "" && item.Action != BulkActionIndex {
+		return nil, errors.Errorf("document id of bulk %s item is missing", item.Action)
+	}
+
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(map[string]bulkMeta{item.Action: meta}); err != nil {
+		return nil, errors.Wrap(err, "failed to encode bulk item")
+	}
+	if item.Action != BulkActionDelete {
+		if err := json.NewEncoder(&buf).Encode(item.Body); err != nil {
+			return nil, errors.Wrap(err, "failed to encode bulk item body")
+		}
+	}
+	return buf.Bytes(), nil
+}
+
+func (bi *bulkIndexer) work() {
+	defer bi.wg.Done()
+
+	ticker := time.NewTicker(bi.config.FlushInterval)
+	defer ticker.Stop()
+
+	var items []bulkItem
+	size := 0
+	flush := func() {
+		if len(items) > 0 {
+			bi.flush(bi.ctx, items)
+		}
+		items, size = nil, 0
+	}
+	for {
+		select {
+		case item, ok := <-bi.queue:
+			if !ok {
+				flush()
+				return
+			}
+			if len(items) > 0 && size+len(item.lines) > bi.config.FlushBytes {
+				flush()
+			}
+			items = append(items, item)
+			size += len(item.lines)
+			if len(items) >= bi.config.FlushItems {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// flush sends items in a bulk request, retrying the ones rejected with
+// status code 429.
+func (bi *bulkIndexer) flush(ctx context.Context, items []bulkItem) {
+	pending := items
+	attempt := 0
+	err := bi.config.RetryPolicy.Do(ctx, func(ctx context.Context) error {
+		attempt++
+		if attempt > 1 {
+			bi.retried.Add(uint64(len(pending)))
+		}
+
+		var body bytes.Buffer
+		for _, item := range pending {
+			body.Write(item.lines)
+		}
+		bi.requests.Add(1)
+		res, err := bi.bulk(ctx, &body, bi.config.Refresh)
+		if err != nil {
+			return err
+		}
+		if len(res.Items) != len(pending) {
+			return fmt.Errorf("bulk response has %d items for %d requested", len(res.Items), len(pending))
+		}
+
+		var rejected []bulkItem
+		for i, result := range res.Items {
+			item := pending[i]
+			r := result[item.Action]
+			switch {
+			case r.Status == http.StatusTooManyRequests:
+				rejected = append(rejected, item)
+			case r.Status >= http.StatusMultipleChoices || r.Error != nil:
+				bi.failed.Add(1)
+				if item.OnFailure != nil {
+					item.OnFailure(ctx, item.BulkIndexerItem, r, nil)
+				}
+			default:
+				bi.indexed.Add(1)
+				if item.OnSuccess != nil {
+					item.OnSuccess(ctx, item.BulkIndexerItem, r)
+				}
+			}
+		}
+		pending = rejected
+		if len(pending) > 0 {
+			return errBulkTooManyRequests
+		}
+		return nil
+	})
+	if err == nil {
+		return
+	}
+
+	klog.Errorf("bulk request of %d items failed, reason: %s", len(pending), err)
+	for _, item := range pending {
+		bi.failed.Add(1)
+		if item.OnFailure != nil {
+			item.OnFailure(ctx, item.BulkIndexerItem, BulkResponseItem{}, err)
+		}
+	}
+	if bi.config.OnError != nil {
+		bi.config.OnError(ctx, err)
+	}
+}
+
+// decodeBulkResponse decodes the response of a bulk request. The requests
+// rejected with status code 429 return errBulkTooManyRequests.
+func decodeBulkResponse(statusCode int, body io.Reader) (*bulkResponse, error) {
+	if statusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%w: %s", errBulkTooManyRequests, responseError(statusCode, body, "bulk"))
+	}
+	var response bulkResponse
+	if err := decodeResponse(statusCode, body, &response, "bulk"); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
@@ -0,0 +1,240 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"kubedb.dev/db-client-go/retry"
+)
+
+// fakeBulk answers the bulk requests with the status returned by status for
+// the document id of every item.
+func fakeBulk(status func(id string) int) bulkFunc {
+	return func(ctx context.Context, body io.Reader, refresh string) (*bulkResponse, error) {
+		var response bulkResponse
+		scanner := bufio.NewScanner(body)
+		for scanner.Scan() {
+			var line map[string]bulkMeta
+			if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+				return nil, err
+			}
+			for action, meta := range line {
+				r := BulkResponseItem{Index: meta.Index, DocumentID: meta.DocumentID, Status: status(meta.DocumentID)}
+				response.Items = append(response.Items, map[string]BulkResponseItem{action: r})
+				if action != BulkActionDelete && !scanner.Scan() {
+					return nil, errors.New("bulk item body is missing")
+				}
+			}
+		}
+		return &response, scanner.Err()
+	}
+}
+
+func TestBulkIndexer(t *testing.T) {
+	bi, err := newBulkIndexer(BulkIndexerConfig{
+		Index:      "logs",
+		NumWorkers: 2,
+		FlushItems: 2,
+	}, fakeBulk(func(id string) int {
+		if id == "bad" {
+			return http.StatusBadRequest
+		}
+		return http.StatusCreated
+	}), "")
+	if err != nil {
+		t.Fatalf("failed to create bulk indexer: %v", err)
+	}
+
+	var mu sync.Mutex
+	succeeded := map[string]bool{}
+	failed := map[string]bool{}
+	for _, id := range []string{"1", "2", "bad", "3", "4"} {
+		err := bi.Add(context.Background(), BulkIndexerItem{
+			Action:     BulkActionCreate,
+			DocumentID: id,
+			Body:       map[string]string{"message": id},
+			OnSuccess: func(_ context.Context, item BulkIndexerItem, _ BulkResponseItem) {
+				mu.Lock()
+				defer mu.Unlock()
+				succeeded[item.DocumentID] = true
+			},
+			OnFailure: func(_ context.Context, item BulkIndexerItem, _ BulkResponseItem, _ error) {
+				mu.Lock()
+				defer mu.Unlock()
+				failed[item.DocumentID] = true
+			},
+		})
+		if err != nil {
+			t.Fatalf("failed to add item %s: %v", id, err)
+		}
+	}
+	if err := bi.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close bulk indexer: %v", err)
+	}
+
+	if len(succeeded) != 4 || !failed["bad"] || len(failed) != 1 {
+		t.Errorf("got succeeded %v and failed %v, want 4 succeeded and bad failed", succeeded, failed)
+	}
+	stats := bi.Stats()
+	if stats.NumAdded != 5 || stats.NumIndexed != 4 || stats.NumFailed != 1 || stats.NumRetried != 0 {
+		t.Errorf("got stats %+v", stats)
+	}
+	if err := bi.Add(context.Background(), BulkIndexerItem{Action: BulkActionIndex}); err == nil {
+		t.Error("expected an error when adding to a closed bulk indexer")
+	}
+}
+
+func TestBulkIndexerRetry(t *testing.T) {
+	var rejected atomic.Bool
+	bi, err := newBulkIndexer(BulkIndexerConfig{
+		Index:       "logs",
+		NumWorkers:  1,
+		RetryPolicy: retry.ConstantPolicy(time.Millisecond, 3),
+	}, fakeBulk(func(id string) int {
+		if id == "2" && !rejected.Swap(true) {
+			return http.StatusTooManyRequests
+		}
+		return http.StatusOK
+	}), "")
+	if err != nil {
+		t.Fatalf("failed to create bulk indexer: %v", err)
+	}
+	for _, id := range []string{"1", "2"} {
+		if err := bi.Add(context.Background(), BulkIndexerItem{Action: BulkActionDelete, DocumentID: id}); err != nil {
+			t.Fatalf("failed to add item %s: %v", id, err)
+		}
+	}
+	if err := bi.Close(context.Background()); err != nil {
+		t.Fatalf("failed to close bulk indexer: %v", err)
+	}
+
+	stats := bi.Stats()
+	if stats.NumIndexed != 2 || stats.NumFailed != 0 || stats.NumRetried != 1 || stats.NumRequests != 2 {
+		t.Errorf("got stats %+v, want 2 indexed and 1 retried in 2 requests", stats)
+	}
+}
+
+func TestBulkIndexerCloseContext(t *testing.T) {
+	bulk := func(ctx context.Context, _ io.Reader, _ string) (*bulkResponse, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	bi, err := newBulkIndexer(BulkIndexerConfig{Index: "logs", NumWorkers: 1, FlushItems: 1}, bulk, "")
+	if err != nil {
+		t.Fatalf("failed to create bulk indexer: %v", err)
+	}
+
+	var failed atomic.Int32
+	item := BulkIndexerItem{
+		Action: BulkActionIndex,
+		Body:   map[string]string{"message": "hello"},
+		OnFailure: func(_ context.Context, _ BulkIndexerItem, _ BulkResponseItem, err error) {
+			if errors.Is(err, context.Canceled) {
+				failed.Add(1)
+			}
+		},
+	}
+	// the worker blocks on the first item and the second fills the queue
+	for i := 0; i < 2; i++ {
+		if err := bi.Add(context.Background(), item); err != nil {
+			t.Fatalf("failed to add item: %v", err)
+		}
+	}
+	// a producer blocks on the full queue without a deadline
+	blocked := make(chan error, 1)
+	go func() {
+		blocked <- bi.Add(context.Background(), item)
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	closed := make(chan error, 1)
+	go func() {
+		closed <- bi.Close(ctx)
+	}()
+	select {
+	case err := <-closed:
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("got error %v, want %v", err, context.DeadlineExceeded)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return after its context expired")
+	}
+	if got := failed.Load(); got != 2 {
+		t.Errorf("got %d failed items when Close returned, want 2", got)
+	}
+	select {
+	case err := <-blocked:
+		if err == nil {
+			t.Error("expected an error for the item added during Close")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Add did not return after Close")
+	}
+}
+
+func TestBulkIndexerEncode(t *testing.T) {
+	bi := &bulkIndexer{config: BulkIndexerConfig{Index: "logs"}, documentType: "_doc"}
+
+	lines, err := bi.encode(BulkIndexerItem{Action: BulkActionUpdate, DocumentID: "1", Body: map[string]interface{}{"doc": map[string]int{"count": 1}}})
+	if err != nil {
+		t.Fatalf("failed to encode item: %v", err)
+	}
+	want := `{"update":{"_index":"logs","_type":"_doc","_id":"1"}}` + "\n" + `{"doc":{"count":1}}` + "\n"
+	if string(lines) != want {
+		t.Errorf("got lines %q, want %q", lines, want)
+	}
+
+	for _, item := range []BulkIndexerItem{
+		{Action: "upsert", DocumentID: "1"},
+		{Action: BulkActionDelete},
+	} {
+		if _, err := bi.encode(item); err == nil {
+			t.Errorf("expected an error for %+v", item)
+		}
+	}
+	bi.config.Index = ""
+	if _, err := bi.encode(BulkIndexerItem{Action: BulkActionIndex}); err == nil {
+		t.Error("expected an error for an item without index")
+	}
+}
+
+func TestDecodeBulkResponse(t *testing.T) {
+	_, err := decodeBulkResponse(http.StatusTooManyRequests, strings.NewReader(`{"error":"rejected"}`))
+	if !errors.Is(err, errBulkTooManyRequests) {
+		t.Errorf("got error %v, want %v", err, errBulkTooManyRequests)
+	}
+
+	response, err := decodeBulkResponse(http.StatusOK, strings.NewReader(`{"errors":false,"items":[{"index":{"_index":"logs","_id":"1","status":201}}]}`))
+	if err != nil {
+		t.Fatalf("failed to decode bulk response: %v", err)
+	}
+	if len(response.Items) != 1 || response.Items[0][BulkActionIndex].Status != http.StatusCreated {
+		t.Errorf("got response %+v", response)
+	}
+}
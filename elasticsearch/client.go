@@ -16,10 +16,25 @@ limitations under the License.
 
 package elasticsearch
 
-import "github.com/go-resty/resty/v2"
+import (
+	"context"
+
+	"kubedb.dev/db-client-go/internal/telemetry"
+
+	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
+
+	"github.com/go-resty/resty/v2"
+)
 
 type Client struct {
 	ESClient
+	telemetry *telemetry.Telemetry
+}
+
+func (c *Client) GetClusterWriteStatus(ctx context.Context, db *dbapi.Elasticsearch) error {
+	return c.telemetry.Run(ctx, "cluster.write_status", func(ctx context.Context) error {
+		return c.ESClient.GetClusterWriteStatus(ctx, db)
+	})
 }
 
 type ESRestyClient struct {
@@ -32,5 +47,6 @@ type ShardInfo struct {
 	Shard            string `json:"shard,omitempty"`
 	Prirep           string `json:"prirep,omitempty"`
 	State            string `json:"state,omitempty"`
+	Node             string `json:"node,omitempty"`
 	UnassignedReason string `json:"unassigned.reason,omitempty"`
 }
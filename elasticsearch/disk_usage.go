@@ -0,0 +1,181 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+const (
+	settingDiskThresholdEnabled    = "cluster.routing.allocation.disk.threshold_enabled"
+	settingDiskWatermarkLow        = "cluster.routing.allocation.disk.watermark.low"
+	settingDiskWatermarkHigh       = "cluster.routing.allocation.disk.watermark.high"
+	settingDiskWatermarkFloodStage = "cluster.routing.allocation.disk.watermark.flood_stage"
+)
+
+// DiskUsageReport is the disk usage of the indices and the nodes of a
+// cluster, along with the disk watermarks that limit the shard allocation.
+type DiskUsageReport struct {
+	Indices []IndexDiskUsage
+	Nodes   []NodeDiskUsage
+	// StoreSizeInBytes is the size of all the indices, including replicas.
+	StoreSizeInBytes        int64
+	PrimaryStoreSizeInBytes int64
+	Watermarks              DiskWatermarks
+}
+
+type IndexDiskUsage struct {
+	Index                   string
+	PrimaryStoreSizeInBytes int64
+	StoreSizeInBytes        int64
+}
+
+type NodeDiskUsage struct {
+	Node             string
+	TotalInBytes     int64
+	UsedInBytes      int64
+	AvailableInBytes int64
+}
+
+// DiskWatermarks are the values of the disk watermark settings, either
+// percentages of the used disk, e.g. "85%", or free space, e.g. "10gb".
+// FloodStage is empty before version 6.
+type DiskWatermarks struct {
+	ThresholdEnabled bool
+	Low              string
+	High             string
+	FloodStage       string
+}
+
+// DiskSizeEstimator recommends the size of the volumes of a cluster from its
+// disk usage.
+type DiskSizeEstimator interface {
+	EstimateSize(report *DiskUsageReport) resource.Quantity
+}
+
+// SafetyFactorEstimator recommends the store size of all the indices plus
+// SafetyFactor of it, e.g. 0.2 for 20%, rounded up to Mi and no less than
+// MinimumSize.
+type SafetyFactorEstimator struct {
+	SafetyFactor float64
+	MinimumSize  resource.Quantity
+}
+
+var _ DiskSizeEstimator = SafetyFactorEstimator{}
+
+// DefaultDiskSizeEstimator returns an estimator with the safety factor and
+// the minimum size of GetTotalDiskUsage.
+func DefaultDiskSizeEstimator() SafetyFactorEstimator {
+	return SafetyFactorEstimator{
+		SafetyFactor: diskUsageSafetyFactor,
+		MinimumSize:  *resource.NewQuantity(int64(diskUsageDefaultMi)*1024*1024, resource.BinarySI),
+	}
+}
+
+func (e SafetyFactorEstimator) EstimateSize(report *DiskUsageReport) resource.Quantity {
+	mi := int64(math.Ceil(float64(report.StoreSizeInBytes) * (1 + e.SafetyFactor) / (1024 * 1024)))
+	size := *resource.NewQuantity(mi*1024*1024, resource.BinarySI)
+	if size.Cmp(e.MinimumSize) < 0 {
+		return e.MinimumSize.DeepCopy()
+	}
+	return size
+}
+
+// clusterSettingsResponse is the response of the cluster get settings API
+// with flat settings and defaults.
+type clusterSettingsResponse struct {
+	Persistent map[string]interface{} `json:"persistent"`
+	Transient  map[string]interface{} `json:"transient"`
+	Defaults   map[string]interface{} `json:"defaults"`
+}
+
+// get returns the effective value of a setting.
+func (r *clusterSettingsResponse) get(key string) string {
+	for _, settings := range []map[string]interface{}{r.Transient, r.Persistent, r.Defaults} {
+		if value, ok := settings[key].(string); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+func (r *clusterSettingsResponse) diskWatermarks() (DiskWatermarks, error) {
+	watermarks := DiskWatermarks{
+		ThresholdEnabled: true,
+		Low:              r.get(settingDiskWatermarkLow),
+		High:             r.get(settingDiskWatermarkHigh),
+		FloodStage:       r.get(settingDiskWatermarkFloodStage),
+	}
+	if value := r.get(settingDiskThresholdEnabled); value != "" {
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return watermarks, errors.Wrapf(err, "failed to parse %s", settingDiskThresholdEnabled)
+		}
+		watermarks.ThresholdEnabled = enabled
+	}
+	return watermarks, nil
+}
+
+func diskUsageReport(ctx context.Context, client ESClient, settings *clusterSettingsResponse) (*DiskUsageReport, error) {
+	var report DiskUsageReport
+	var err error
+	report.Watermarks, err = settings.diskWatermarks()
+	if err != nil {
+		return nil, err
+	}
+
+	indices, err := client.GetIndices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, index := range indices {
+		report.Indices = append(report.Indices, IndexDiskUsage{
+			Index:                   index.Index,
+			PrimaryStoreSizeInBytes: index.PriStoreSizeInBytes,
+			StoreSizeInBytes:        index.StoreSizeInBytes,
+		})
+		report.StoreSizeInBytes += index.StoreSizeInBytes
+		report.PrimaryStoreSizeInBytes += index.PriStoreSizeInBytes
+	}
+	sort.Slice(report.Indices, func(i, j int) bool {
+		return report.Indices[i].Index < report.Indices[j].Index
+	})
+
+	stats, err := client.GetNodesStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range stats.Nodes {
+		fs := node.FS.Total
+		report.Nodes = append(report.Nodes, NodeDiskUsage{
+			Node:             node.Name,
+			TotalInBytes:     fs.TotalInBytes,
+			UsedInBytes:      fs.TotalInBytes - fs.FreeInBytes,
+			AvailableInBytes: fs.AvailableInBytes,
+		})
+	}
+	sort.Slice(report.Nodes, func(i, j int) bool {
+		return report.Nodes[i].Node < report.Nodes[j].Node
+	})
+	return &report, nil
+}
@@ -19,14 +19,27 @@ package elasticsearch
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"kubedb.dev/db-client-go/dberrors"
 
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
 	esv5 "github.com/elastic/go-elasticsearch/v5"
+	"github.com/elastic/go-elasticsearch/v5/esapi"
 	"github.com/pkg/errors"
 	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	kutil "kmodules.xyz/client-go"
 )
 
+// documentTypeV5 is the mapping type of the documents written by ESClientV5.
+// Version 5 requires a type in the document APIs and doesn't allow "_doc".
+const documentTypeV5 = "doc"
+
 var _ ESClient = &ESClientV5{}
 
 type ESClientV5 struct {
@@ -34,21 +47,84 @@ type ESClientV5 struct {
 }
 
 func (es *ESClientV5) ClusterHealthInfo() (map[string]interface{}, error) {
-	return nil, nil
+	res, err := es.client.Cluster.Health(
+		es.client.Cluster.Health.WithPretty(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(res.Body)
+
+	response := make(map[string]interface{})
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get cluster health"); err != nil {
+		return nil, err
+	}
+	return response, nil
 }
 
 func (es *ESClientV5) NodesStats() (map[string]interface{}, error) {
-	// Todo: need to implement for version 5
-	return nil, nil
+	req := esapi.NodesStatsRequest{
+		Pretty: true,
+		Human:  true,
+	}
+
+	resp, err := req.Do(context.Background(), es.client)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp.Body)
+
+	nodesStats := make(map[string]interface{})
+	if err := decodeResponse(resp.StatusCode, resp.Body, &nodesStats, "get nodes stats"); err != nil {
+		return nil, err
+	}
+
+	return nodesStats, nil
 }
 
 func (es *ESClientV5) ShardStats() ([]ShardInfo, error) {
-	return nil, nil
+	req := esapi.CatShardsRequest{
+		Bytes:  "b",
+		Format: "json",
+		Pretty: true,
+		Human:  true,
+		H:      []string{"index", "shard", "prirep", "state", "node", "unassigned.reason"},
+	}
+
+	resp, err := req.Do(context.Background(), es.client)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var shardStats []ShardInfo
+	if err := decodeResponse(resp.StatusCode, resp.Body, &shardStats, "get shard stats"); err != nil {
+		return nil, err
+	}
+	return shardStats, nil
 }
 
 // GetIndicesInfo will return the indices info of an Elasticsearch database
 func (es *ESClientV5) GetIndicesInfo() ([]interface{}, error) {
-	return nil, nil
+	req := esapi.CatIndicesRequest{
+		Bytes:  "b", // will return resource size field into byte unit
+		Format: "json",
+		Pretty: true,
+		Human:  true,
+	}
+
+	resp, err := req.Do(context.Background(), es.client)
+	if err != nil {
+		return nil, err
+	}
+	defer closeBody(resp.Body)
+
+	indicesInfo := make([]interface{}, 0)
+	if err := decodeResponse(resp.StatusCode, resp.Body, &indicesInfo, "get indices info"); err != nil {
+		return nil, err
+	}
+
+	return indicesInfo, nil
 }
 
 func (es *ESClientV5) ClusterStatus() (string, error) {
@@ -74,7 +150,7 @@ func (es *ESClientV5) ClusterStatus() (string, error) {
 }
 
 // kibana_system, logstash_system etc. internal users
-// are not supported for versions 6.x.x and,
+// are not supported for versions 5.x.x and,
 // kibana, logstash can be accessed using elastic superuser
 // so, sysncing is not required for other builtin users
 func (es *ESClientV5) SyncCredentialFromSecret(secret *core.Secret) error {
@@ -82,41 +158,917 @@ func (es *ESClientV5) SyncCredentialFromSecret(secret *core.Secret) error {
 }
 
 func (es *ESClientV5) GetClusterWriteStatus(ctx context.Context, db *dbapi.Elasticsearch) error {
-	return nil
+	// Build the request index & request body
+	// send the db specs as body
+	indexBody := WriteRequestIndexBody{
+		ID:   writeRequestID,
+		Type: documentTypeV5,
+	}
+
+	indexReq := WriteRequestIndex{indexBody}
+	ReqBody := db.Spec
+
+	// encode the request index & request body
+	index, err1 := json.Marshal(indexReq)
+	if err1 != nil {
+		return errors.Wrap(err1, "Failed to encode index for performing write request")
+	}
+	body, err2 := json.Marshal(ReqBody)
+	if err2 != nil {
+		return errors.Wrap(err2, "Failed to encode request body for performing write request")
+	}
+
+	// make write request & fetch response
+	// check for write request failure & error from response body
+	// If the document exists, replaces the document and increments the version
+	res, err3 := esapi.BulkRequest{
+		Index:  writeRequestIndex,
+		Body:   strings.NewReader(strings.Join([]string{string(index), string(body)}, "\n") + "\n"),
+		Pretty: true,
+	}.Do(ctx, es.client.Transport)
+	if err3 != nil {
+		return errors.Wrap(err3, "Failed to perform write request")
+	}
+	if res.IsError() {
+		return fmt.Errorf("failed to get response from write request with error statuscode %d", res.StatusCode)
+	}
+
+	defer func(res *esapi.Response) {
+		if res != nil {
+			err3 = res.Body.Close()
+			if err3 != nil {
+				klog.Errorf("Failed to close write request response body, reason: %s", err3)
+			}
+		}
+	}(res)
+
+	responseBody := make(map[string]interface{})
+	if err4 := json.NewDecoder(res.Body).Decode(&responseBody); err4 != nil {
+		return errors.Wrap(err4, "Failed to decode response from write request")
+	}
+
+	// Parse the responseBody to check if write operation failed after request being successful
+	// `errors` field(boolean) in the json response becomes true if there's and error caused, otherwise it stays nil
+	if value, ok := responseBody["errors"]; ok {
+		if strValue, ok := value.(bool); ok {
+			if !strValue {
+				return nil
+			}
+			return errors.Errorf("Write request responded with error, %v", responseBody)
+		}
+		return errors.New("Failed to parse value for `errors` in response from write request")
+	}
+	return errors.New("Failed to parse key `errors` in response from write request")
 }
 
 func (es *ESClientV5) GetClusterReadStatus(ctx context.Context, db *dbapi.Elasticsearch) error {
+	// Perform a read request in writeRequestIndex/documentTypeV5/writeRequestID (kubedb-system/doc/info) API
+	// Handle error specifically if index has not been created yet
+	res, err := esapi.GetRequest{
+		Index:        writeRequestIndex,
+		DocumentType: documentTypeV5,
+		DocumentID:   writeRequestID,
+	}.Do(ctx, es.client.Transport)
+	if err != nil {
+		return errors.Wrap(err, "Failed to perform read request")
+	}
+
+	defer func(res *esapi.Response) {
+		if res != nil {
+			err = res.Body.Close()
+			if err != nil {
+				klog.Errorf("failed to close read request response body, reason: %s", err)
+			}
+		}
+	}(res)
+
+	if res.StatusCode == http.StatusNotFound {
+		return kutil.ErrNotFound
+	}
+	if res.IsError() {
+		return fmt.Errorf("failed to get response from write request with error statuscode %d", res.StatusCode)
+	}
+
 	return nil
 }
 
 func (es *ESClientV5) GetTotalDiskUsage(ctx context.Context) (string, error) {
-	return "", nil
+	// The disk usage API is not available in version 5, so the store size
+	// of all the indices is taken from the index stats.
+	res, err := esapi.IndicesStatsRequest{
+		Index:  []string{diskUsageRequestIndex},
+		Metric: []string{"store"},
+	}.Do(ctx, es.client.Transport)
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to perform Indices Stats Request")
+	}
+	defer closeBody(res.Body)
+
+	var response struct {
+		All struct {
+			Total struct {
+				Store struct {
+					SizeInBytes float64 `json:"size_in_bytes"`
+				} `json:"store"`
+			} `json:"total"`
+		} `json:"_all"`
+	}
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get indices store stats"); err != nil {
+		return "", err
+	}
+	return diskUsageQuantity(response.All.Total.Store.SizeInBytes), nil
 }
 
 func (es *ESClientV5) GetDBUserRole(ctx context.Context) (error, bool) {
-	return errors.New("not supported in es version 5"), false
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 5"), false
 }
 
 func (es *ESClientV5) CreateDBUserRole(ctx context.Context) error {
-	return errors.New("not supported in es version 5")
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 5")
 }
 
 func (es *ESClientV5) IndexExistsOrNot(index string) error {
-	return errors.New("not supported in es version 5")
+	req := esapi.IndicesExistsRequest{
+		Index: []string{index},
+	}
+	res, err := req.Do(context.Background(), es.client)
+	if err != nil {
+		klog.Errorf("failed to get response while checking either index exists or not %v", err)
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err = Body.Close()
+		if err != nil {
+			klog.Errorf("failed to close response body for checking the existence of index, reason: %s", err)
+		}
+	}(res.Body)
+
+	if res.IsError() {
+		klog.Errorf("failed to get index with statuscode %d", res.StatusCode)
+		return errors.New("index does not exist")
+	}
+	return nil
 }
 
 func (es *ESClientV5) CreateIndex(index string) error {
-	return errors.New("not supported in es version 5")
+	req := esapi.IndicesCreateRequest{
+		Index:  index,
+		Pretty: true,
+		Human:  true,
+	}
+
+	res, err := req.Do(context.Background(), es.client)
+	if err != nil {
+		klog.Errorf("failed to apply create index request, reason: %s", err)
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err = Body.Close()
+		if err != nil {
+			klog.Errorf("failed to close response body for creating index, reason: %s", err)
+		}
+	}(res.Body)
+
+	if res.IsError() {
+		klog.Errorf("creating index failed with statuscode %d", res.StatusCode)
+		return errors.New("failed to create index")
+	}
+
+	return nil
 }
 
 func (es *ESClientV5) DeleteIndex(index string) error {
-	return errors.New("not supported in es version 5")
+	req := esapi.IndicesDeleteRequest{
+		Index: []string{index},
+	}
+
+	res, err := req.Do(context.Background(), es.client)
+	if err != nil {
+		klog.Errorf("failed to apply delete index request, reason: %s", err)
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err = Body.Close()
+		if err != nil {
+			klog.Errorf("failed to close response body for deleting index, reason: %s", err)
+		}
+	}(res.Body)
+
+	if res.IsError() {
+		klog.Errorf("failed to delete index with status code %d", res.StatusCode)
+		return errors.New("failed to delete index")
+	}
+
+	return nil
 }
 
 func (es *ESClientV5) CountData(index string) (int, error) {
-	return 0, errors.New("not supported in es version 5")
+	req := esapi.CountRequest{
+		Index: []string{index},
+	}
+
+	res, err := req.Do(context.Background(), es.client)
+	if err != nil {
+		return 0, err
+	}
+	defer closeBody(res.Body)
+
+	var response map[string]interface{}
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "count documents of index "+index); err != nil {
+		return 0, err
+	}
+
+	count, ok := response["count"].(float64)
+	if !ok {
+		return 0, errors.New("failed to parse value for index count in response body")
+	}
+	return int(count), nil
 }
 
 func (es *ESClientV5) PutData(index, id string, data map[string]interface{}) error {
-	return errors.New("not supported in es version 5")
+	var b strings.Builder
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return errors.Wrap(err, "failed to Marshal data")
+	}
+	b.Write(dataBytes)
+
+	req := esapi.CreateRequest{
+		Index:        index,
+		DocumentType: documentTypeV5,
+		DocumentID:   id,
+		Body:         strings.NewReader(b.String()),
+		Pretty:       true,
+		Human:        true,
+	}
+
+	res, err := req.Do(context.Background(), es.client)
+	if err != nil {
+		klog.Errorf("failed to put data in the index, reason: %s", err)
+		return err
+	}
+	defer func(Body io.ReadCloser) {
+		err = Body.Close()
+		if err != nil {
+			klog.Errorf("failed to close response body for putting data in the index, reason: %s", err)
+		}
+	}(res.Body)
+
+	if res.IsError() {
+		klog.Errorf("failed to put data in an index with statuscode %d", res.StatusCode)
+		return errors.New("failed to put data in an index")
+	}
+	return nil
+}
+
+func (es *ESClientV5) CreateSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository, verify bool) error {
+	body, err := jsonBody(repository)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.SnapshotCreateRepositoryRequest{
+		Repository: name,
+		Body:       body,
+		Verify:     &verify,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform create snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "create snapshot repository "+name)
+}
+
+func (es *ESClientV5) VerifySnapshotRepository(ctx context.Context, name string) ([]string, error) {
+	res, err := esapi.SnapshotVerifyRepositoryRequest{
+		Repository: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform verify snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	var response verifyRepositoryResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "verify snapshot repository "+name); err != nil {
+		return nil, err
+	}
+	return response.nodeNames(), nil
+}
+
+func (es *ESClientV5) DeleteSnapshotRepository(ctx context.Context, name string) error {
+	res, err := esapi.SnapshotDeleteRepositoryRequest{
+		Repository: []string{name},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete snapshot repository "+name)
+}
+
+func (es *ESClientV5) CreateSnapshot(ctx context.Context, repository, snapshot string, opts SnapshotOptions) (*SnapshotInfo, error) {
+	body, err := jsonBody(opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.SnapshotCreateRequest{
+		Repository:        repository,
+		Snapshot:          snapshot,
+		Body:              body,
+		WaitForCompletion: &opts.WaitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform create snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotCreateResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "create snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.info(repository, snapshot), nil
+}
+
+func (es *ESClientV5) ListSnapshots(ctx context.Context, repository string) ([]SnapshotInfo, error) {
+	res, err := esapi.SnapshotGetRequest{
+		Repository: repository,
+		Snapshot:   []string{"_all"},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get snapshots request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotGetResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "list snapshots of repository "+repository); err != nil {
+		return nil, err
+	}
+	return response.Snapshots, nil
+}
+
+func (es *ESClientV5) GetSnapshotStatus(ctx context.Context, repository, snapshot string) (*SnapshotStatus, error) {
+	res, err := esapi.SnapshotStatusRequest{
+		Repository: repository,
+		Snapshot:   []string{snapshot},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform snapshot status request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotStatusResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get status of snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.status(snapshot)
+}
+
+func (es *ESClientV5) DeleteSnapshot(ctx context.Context, repository, snapshot string) error {
+	res, err := esapi.SnapshotDeleteRequest{
+		Repository: repository,
+		Snapshot:   snapshot,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete snapshot "+snapshot)
+}
+
+func (es *ESClientV5) RestoreSnapshot(ctx context.Context, repository, snapshot string, opts RestoreOptions) error {
+	body, err := jsonBody(opts)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.SnapshotRestoreRequest{
+		Repository:        repository,
+		Snapshot:          snapshot,
+		Body:              body,
+		WaitForCompletion: &opts.WaitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform restore snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "restore snapshot "+snapshot)
+}
+
+func (es *ESClientV5) GetClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	res, err := esapi.ClusterHealthRequest{}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cluster health request")
+	}
+	defer closeBody(res.Body)
+
+	var health ClusterHealth
+	if err := decodeResponse(res.StatusCode, res.Body, &health, "get cluster health"); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+func (es *ESClientV5) GetNodesStats(ctx context.Context) (*NodesStats, error) {
+	res, err := esapi.NodesStatsRequest{
+		Metric: []string{"jvm", "fs", "thread_pool", "breaker"},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform nodes stats request")
+	}
+	defer closeBody(res.Body)
+
+	var stats NodesStats
+	if err := decodeResponse(res.StatusCode, res.Body, &stats, "get nodes stats"); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (es *ESClientV5) GetIndices(ctx context.Context) ([]IndexInfo, error) {
+	res, err := esapi.CatIndicesRequest{
+		Bytes:  "b",
+		Format: "json",
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cat indices request")
+	}
+	defer closeBody(res.Body)
+
+	var indices []IndexInfo
+	if err := decodeResponse(res.StatusCode, res.Body, &indices, "get indices"); err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+func (es *ESClientV5) PutLifecyclePolicy(ctx context.Context, name string, policy LifecyclePolicy) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 5")
+}
+
+func (es *ESClientV5) GetLifecyclePolicy(ctx context.Context, name string) (*LifecyclePolicyInfo, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 5")
+}
+
+func (es *ESClientV5) DeleteLifecyclePolicy(ctx context.Context, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 5")
+}
+
+func (es *ESClientV5) AttachLifecyclePolicy(ctx context.Context, template, policy, rolloverAlias string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 5")
+}
+
+func (es *ESClientV5) ExplainLifecycle(ctx context.Context, index string) ([]LifecycleExplanation, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 5")
+}
+
+func (es *ESClientV5) RetryLifecycle(ctx context.Context, index string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 5")
+}
+
+func (es *ESClientV5) PutLegacyIndexTemplate(ctx context.Context, name string, template LegacyIndexTemplate) error {
+	v5, err := template.toV5()
+	if err != nil {
+		return err
+	}
+	body, err := jsonBody(v5)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesPutTemplateRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put template "+name)
+}
+
+func (es *ESClientV5) GetLegacyIndexTemplate(ctx context.Context, name string) (*LegacyIndexTemplate, error) {
+	res, err := esapi.IndicesGetTemplateRequest{
+		Name: []string{name},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get template request")
+	}
+	defer closeBody(res.Body)
+
+	var response map[string]legacyIndexTemplateV5
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get template "+name); err != nil {
+		return nil, err
+	}
+	template, err := legacyTemplate(response, name)
+	if err != nil {
+		return nil, err
+	}
+	return template.toLegacyIndexTemplate(), nil
+}
+
+func (es *ESClientV5) DeleteLegacyIndexTemplate(ctx context.Context, name string) error {
+	res, err := esapi.IndicesDeleteTemplateRequest{
+		Name: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete template "+name)
+}
+
+func (es *ESClientV5) CreateIndexWithBody(ctx context.Context, index string, definition IndexBody) error {
+	body, err := jsonBody(definition)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesCreateRequest{
+		Index: index,
+		Body:  body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform create index request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "create index "+index)
+}
+
+func (es *ESClientV5) UpdateAliases(ctx context.Context, actions ...AliasAction) error {
+	body, err := jsonBody(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesUpdateAliasesRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform update aliases request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "update aliases")
+}
+
+func (es *ESClientV5) RolloverAlias(ctx context.Context, alias, newIndex string, conditions *RolloverConditions) (*RolloverResult, error) {
+	body, err := jsonBody(rolloverRequest{Conditions: conditions})
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.IndicesRolloverRequest{
+		Alias:    alias,
+		NewIndex: newIndex,
+		Body:     body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform rollover request")
+	}
+	defer closeBody(res.Body)
+
+	var result RolloverResult
+	if err := decodeResponse(res.StatusCode, res.Body, &result, "roll over alias "+alias); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (es *ESClientV5) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "composable index templates are not supported in es version 5")
+}
+
+func (es *ESClientV5) GetIndexTemplate(ctx context.Context, name string) (*IndexTemplate, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "composable index templates are not supported in es version 5")
+}
+
+func (es *ESClientV5) DeleteIndexTemplate(ctx context.Context, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "composable index templates are not supported in es version 5")
+}
+
+func (es *ESClientV5) PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "component templates are not supported in es version 5")
+}
+
+func (es *ESClientV5) GetComponentTemplate(ctx context.Context, name string) (*ComponentTemplate, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "component templates are not supported in es version 5")
+}
+
+func (es *ESClientV5) DeleteComponentTemplate(ctx context.Context, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "component templates are not supported in es version 5")
+}
+
+func (es *ESClientV5) putClusterSettings(ctx context.Context, settings clusterSettingsRequest) error {
+	body, err := jsonBody(settings)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.ClusterPutSettingsRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform cluster put settings request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "update cluster settings")
+}
+
+func (es *ESClientV5) ExcludeNodesFromAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, es, nodes, nil)
+}
+
+func (es *ESClientV5) IncludeNodesInAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, es, nil, nodes)
+}
+
+func (es *ESClientV5) DrainNode(ctx context.Context, node string) error {
+	if err := es.ExcludeNodesFromAllocation(ctx, node); err != nil {
+		return err
+	}
+	return waitForNodeDrained(ctx, es, node)
+}
+
+func (es *ESClientV5) SetShardAllocation(ctx context.Context, mode string) error {
+	if err := validateAllocationEnable(mode); err != nil {
+		return err
+	}
+	return es.putClusterSettings(ctx, persistentSetting(settingAllocationEnable, mode))
+}
+
+func (es *ESClientV5) WaitForGreen(ctx context.Context) error {
+	return waitForGreen(ctx, es)
+}
+
+func (es *ESClientV5) Flush(ctx context.Context, synced bool, indices ...string) error {
+	if !synced {
+		return es.flush(ctx, indices)
+	}
+	res, err := esapi.IndicesFlushSyncedRequest{
+		Index: indices,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform synced flush request")
+	}
+	defer closeBody(res.Body)
+
+	if res.StatusCode == http.StatusConflict {
+		// shards with ongoing indexing are not sync flushed, they only
+		// recover slower
+		klog.Warningf("synced flush failed for some shards, status code: %d", res.StatusCode)
+		return nil
+	}
+	return decodeResponse(res.StatusCode, res.Body, nil, "synced flush")
+}
+
+func (es *ESClientV5) flush(ctx context.Context, indices []string) error {
+	waitIfOngoing := true
+	res, err := esapi.IndicesFlushRequest{
+		Index:         indices,
+		WaitIfOngoing: &waitIfOngoing,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform flush request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "flush")
+}
+
+func (es *ESClientV5) ExplainAllocation(ctx context.Context, index string, shard int, primary bool) (*AllocationExplanation, error) {
+	body, err := jsonBody(allocationExplainRequest{Index: index, Shard: shard, Primary: primary})
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.ClusterAllocationExplainRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform allocation explain request")
+	}
+	defer closeBody(res.Body)
+
+	var explanation AllocationExplanation
+	if err := decodeResponse(res.StatusCode, res.Body, &explanation, fmt.Sprintf("explain allocation of shard %d of index %s", shard, index)); err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
+
+func (es *ESClientV5) DiagnoseUnassignedShards(ctx context.Context) ([]UnassignedShardDiagnosis, error) {
+	return diagnoseUnassignedShards(ctx, es)
+}
+
+func (es *ESClientV5) getClusterSettings(ctx context.Context) (*clusterSettingsResponse, error) {
+	includeDefaults, flatSettings := true, true
+	res, err := esapi.ClusterGetSettingsRequest{
+		IncludeDefaults: &includeDefaults,
+		FlatSettings:    &flatSettings,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cluster get settings request")
+	}
+	defer closeBody(res.Body)
+
+	var settings clusterSettingsResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &settings, "get cluster settings"); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (es *ESClientV5) GetDiskUsageReport(ctx context.Context) (*DiskUsageReport, error) {
+	settings, err := es.getClusterSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return diskUsageReport(ctx, es, settings)
+}
+
+func (es *ESClientV5) PutRemoteCluster(ctx context.Context, cluster RemoteCluster) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "remote clusters are not supported in es version 5")
+}
+
+func (es *ESClientV5) DeleteRemoteCluster(ctx context.Context, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "remote clusters are not supported in es version 5")
+}
+
+func (es *ESClientV5) FollowIndex(ctx context.Context, followerIndex string, req FollowRequest) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 5")
+}
+
+func (es *ESClientV5) PauseFollow(ctx context.Context, followerIndex string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 5")
+}
+
+func (es *ESClientV5) ResumeFollow(ctx context.Context, followerIndex string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 5")
+}
+
+func (es *ESClientV5) Unfollow(ctx context.Context, followerIndex string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 5")
+}
+
+func (es *ESClientV5) PutAutoFollowPattern(ctx context.Context, name string, pattern AutoFollowPattern) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 5")
+}
+
+func (es *ESClientV5) DeleteAutoFollowPattern(ctx context.Context, remoteCluster, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 5")
+}
+
+func (es *ESClientV5) GetFollowerStats(ctx context.Context, followerIndex string) (*FollowerStats, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 5")
+}
+
+func (es *ESClientV5) bulk(ctx context.Context, body io.Reader, refresh string) (*bulkResponse, error) {
+	res, err := esapi.BulkRequest{
+		Body:    body,
+		Refresh: refresh,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform bulk request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeBulkResponse(res.StatusCode, res.Body)
+}
+
+func (es *ESClientV5) NewBulkIndexer(config BulkIndexerConfig) (BulkIndexer, error) {
+	return newBulkIndexer(config, es.bulk, documentTypeV5)
+}
+
+func (es *ESClientV5) Reindex(ctx context.Context, request ReindexRequest) (string, error) {
+	body, err := jsonBody(request)
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.ReindexRequest{
+		Body:              body,
+		Slices:            slicesV6(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform reindex request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "reindex to "+request.Dest.Index); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV5) UpdateByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	body, err := jsonBody(request)
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.UpdateByQueryRequest{
+		Index:             indices,
+		Body:              body,
+		Conflicts:         request.Conflicts,
+		Slices:            slicesV6(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform update by query request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "update by query"); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV5) DeleteByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	body, err := jsonBody(request.withQuery())
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.DeleteByQueryRequest{
+		Index:             indices,
+		Body:              body,
+		Conflicts:         request.Conflicts,
+		Slices:            slicesV6(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform delete by query request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "delete by query"); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV5) GetTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	res, err := esapi.TasksGetRequest{
+		TaskID: taskID,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get task request")
+	}
+	defer closeBody(res.Body)
+
+	var result TaskResult
+	if err := decodeResponse(res.StatusCode, res.Body, &result, "get task "+taskID); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (es *ESClientV5) ListTasks(ctx context.Context, actions ...string) ([]TaskInfo, error) {
+	detailed := true
+	res, err := esapi.TasksListRequest{
+		Actions:  actions,
+		Detailed: &detailed,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform list tasks request")
+	}
+	defer closeBody(res.Body)
+
+	var response tasksListResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "list tasks"); err != nil {
+		return nil, err
+	}
+	return response.tasks(), nil
+}
+
+func (es *ESClientV5) CancelTask(ctx context.Context, taskID string) error {
+	res, err := esapi.TasksCancelRequest{
+		TaskID: taskID,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform cancel task request")
+	}
+	defer closeBody(res.Body)
+
+	var response tasksCancelResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "cancel task "+taskID); err != nil {
+		return err
+	}
+	return response.err(taskID)
+}
+
+func (es *ESClientV5) WaitForTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	return waitForTask(ctx, es, taskID)
+}
+
+func (es *ESClientV5) ReloadSecureSettings(ctx context.Context, keystorePassword string, nodes ...string) (*ReloadSecureSettingsResult, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "reloading secure settings is not supported in es version 5")
 }
@@ -24,6 +24,8 @@ import (
 	"net/http"
 	"strings"
 
+	"kubedb.dev/db-client-go/dberrors"
+
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
 	esv6 "github.com/elastic/go-elasticsearch/v6"
@@ -67,7 +69,7 @@ func (es *ESClientV6) ShardStats() ([]ShardInfo, error) {
 		Format: "json",
 		Pretty: true,
 		Human:  true,
-		H:      []string{"index", "shard", "prirep", "state", "unassigned.reason"},
+		H:      []string{"index", "shard", "prirep", "state", "node", "unassigned.reason"},
 	}
 
 	resp, err := req.Do(context.Background(), es.client)
@@ -243,29 +245,673 @@ func (es *ESClientV6) GetTotalDiskUsage(ctx context.Context) (string, error) {
 }
 
 func (es *ESClientV6) GetDBUserRole(ctx context.Context) (error, bool) {
-	return errors.New("not supported in es version 6"), false
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6"), false
 }
 
 func (es *ESClientV6) CreateDBUserRole(ctx context.Context) error {
-	return errors.New("not supported in es version 6")
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
 }
 
 func (es *ESClientV6) IndexExistsOrNot(index string) error {
-	return errors.New("not supported in es version 6")
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
 }
 
 func (es *ESClientV6) CreateIndex(index string) error {
-	return errors.New("not supported in es version 6")
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
 }
 
 func (es *ESClientV6) DeleteIndex(index string) error {
-	return errors.New("not supported in es version 6")
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
 }
 
 func (es *ESClientV6) CountData(index string) (int, error) {
-	return 0, errors.New("not supported in es version 6")
+	return 0, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
 }
 
 func (es *ESClientV6) PutData(index, id string, data map[string]interface{}) error {
-	return errors.New("not supported in es version 6")
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
+}
+
+func (es *ESClientV6) CreateSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository, verify bool) error {
+	body, err := jsonBody(repository)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.SnapshotCreateRepositoryRequest{
+		Repository: name,
+		Body:       body,
+		Verify:     &verify,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform create snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "create snapshot repository "+name)
+}
+
+func (es *ESClientV6) VerifySnapshotRepository(ctx context.Context, name string) ([]string, error) {
+	res, err := esapi.SnapshotVerifyRepositoryRequest{
+		Repository: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform verify snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	var response verifyRepositoryResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "verify snapshot repository "+name); err != nil {
+		return nil, err
+	}
+	return response.nodeNames(), nil
+}
+
+func (es *ESClientV6) DeleteSnapshotRepository(ctx context.Context, name string) error {
+	res, err := esapi.SnapshotDeleteRepositoryRequest{
+		Repository: []string{name},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete snapshot repository "+name)
+}
+
+func (es *ESClientV6) CreateSnapshot(ctx context.Context, repository, snapshot string, opts SnapshotOptions) (*SnapshotInfo, error) {
+	body, err := jsonBody(opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.SnapshotCreateRequest{
+		Repository:        repository,
+		Snapshot:          snapshot,
+		Body:              body,
+		WaitForCompletion: &opts.WaitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform create snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotCreateResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "create snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.info(repository, snapshot), nil
+}
+
+func (es *ESClientV6) ListSnapshots(ctx context.Context, repository string) ([]SnapshotInfo, error) {
+	res, err := esapi.SnapshotGetRequest{
+		Repository: repository,
+		Snapshot:   []string{"_all"},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get snapshots request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotGetResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "list snapshots of repository "+repository); err != nil {
+		return nil, err
+	}
+	return response.Snapshots, nil
+}
+
+func (es *ESClientV6) GetSnapshotStatus(ctx context.Context, repository, snapshot string) (*SnapshotStatus, error) {
+	res, err := esapi.SnapshotStatusRequest{
+		Repository: repository,
+		Snapshot:   []string{snapshot},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform snapshot status request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotStatusResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get status of snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.status(snapshot)
+}
+
+func (es *ESClientV6) DeleteSnapshot(ctx context.Context, repository, snapshot string) error {
+	res, err := esapi.SnapshotDeleteRequest{
+		Repository: repository,
+		Snapshot:   snapshot,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete snapshot "+snapshot)
+}
+
+func (es *ESClientV6) RestoreSnapshot(ctx context.Context, repository, snapshot string, opts RestoreOptions) error {
+	body, err := jsonBody(opts)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.SnapshotRestoreRequest{
+		Repository:        repository,
+		Snapshot:          snapshot,
+		Body:              body,
+		WaitForCompletion: &opts.WaitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform restore snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "restore snapshot "+snapshot)
+}
+
+func (es *ESClientV6) GetClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	res, err := esapi.ClusterHealthRequest{}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cluster health request")
+	}
+	defer closeBody(res.Body)
+
+	var health ClusterHealth
+	if err := decodeResponse(res.StatusCode, res.Body, &health, "get cluster health"); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+func (es *ESClientV6) GetNodesStats(ctx context.Context) (*NodesStats, error) {
+	res, err := esapi.NodesStatsRequest{
+		Metric: []string{"jvm", "fs", "thread_pool", "breaker"},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform nodes stats request")
+	}
+	defer closeBody(res.Body)
+
+	var stats NodesStats
+	if err := decodeResponse(res.StatusCode, res.Body, &stats, "get nodes stats"); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (es *ESClientV6) GetIndices(ctx context.Context) ([]IndexInfo, error) {
+	res, err := esapi.CatIndicesRequest{
+		Bytes:  "b",
+		Format: "json",
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cat indices request")
+	}
+	defer closeBody(res.Body)
+
+	var indices []IndexInfo
+	if err := decodeResponse(res.StatusCode, res.Body, &indices, "get indices"); err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+func (es *ESClientV6) PutLifecyclePolicy(ctx context.Context, name string, policy LifecyclePolicy) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
+}
+
+func (es *ESClientV6) GetLifecyclePolicy(ctx context.Context, name string) (*LifecyclePolicyInfo, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
+}
+
+func (es *ESClientV6) DeleteLifecyclePolicy(ctx context.Context, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
+}
+
+func (es *ESClientV6) AttachLifecyclePolicy(ctx context.Context, template, policy, rolloverAlias string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
+}
+
+func (es *ESClientV6) ExplainLifecycle(ctx context.Context, index string) ([]LifecycleExplanation, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
+}
+
+func (es *ESClientV6) RetryLifecycle(ctx context.Context, index string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "not supported in es version 6")
+}
+
+func (es *ESClientV6) PutLegacyIndexTemplate(ctx context.Context, name string, template LegacyIndexTemplate) error {
+	body, err := jsonBody(template)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesPutTemplateRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put template "+name)
+}
+
+func (es *ESClientV6) GetLegacyIndexTemplate(ctx context.Context, name string) (*LegacyIndexTemplate, error) {
+	res, err := esapi.IndicesGetTemplateRequest{
+		Name: []string{name},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get template request")
+	}
+	defer closeBody(res.Body)
+
+	var response map[string]LegacyIndexTemplate
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get template "+name); err != nil {
+		return nil, err
+	}
+	return legacyTemplate(response, name)
+}
+
+func (es *ESClientV6) DeleteLegacyIndexTemplate(ctx context.Context, name string) error {
+	res, err := esapi.IndicesDeleteTemplateRequest{
+		Name: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete template "+name)
+}
+
+func (es *ESClientV6) CreateIndexWithBody(ctx context.Context, index string, definition IndexBody) error {
+	body, err := jsonBody(definition)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesCreateRequest{
+		Index: index,
+		Body:  body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform create index request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "create index "+index)
+}
+
+func (es *ESClientV6) UpdateAliases(ctx context.Context, actions ...AliasAction) error {
+	body, err := jsonBody(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesUpdateAliasesRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform update aliases request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "update aliases")
+}
+
+func (es *ESClientV6) RolloverAlias(ctx context.Context, alias, newIndex string, conditions *RolloverConditions) (*RolloverResult, error) {
+	body, err := jsonBody(rolloverRequest{Conditions: conditions})
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.IndicesRolloverRequest{
+		Alias:    alias,
+		NewIndex: newIndex,
+		Body:     body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform rollover request")
+	}
+	defer closeBody(res.Body)
+
+	var result RolloverResult
+	if err := decodeResponse(res.StatusCode, res.Body, &result, "roll over alias "+alias); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (es *ESClientV6) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "composable index templates are not supported in es version 6")
+}
+
+func (es *ESClientV6) GetIndexTemplate(ctx context.Context, name string) (*IndexTemplate, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "composable index templates are not supported in es version 6")
+}
+
+func (es *ESClientV6) DeleteIndexTemplate(ctx context.Context, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "composable index templates are not supported in es version 6")
+}
+
+func (es *ESClientV6) PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "component templates are not supported in es version 6")
+}
+
+func (es *ESClientV6) GetComponentTemplate(ctx context.Context, name string) (*ComponentTemplate, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "component templates are not supported in es version 6")
+}
+
+func (es *ESClientV6) DeleteComponentTemplate(ctx context.Context, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "component templates are not supported in es version 6")
+}
+
+func (es *ESClientV6) putClusterSettings(ctx context.Context, settings clusterSettingsRequest) error {
+	body, err := jsonBody(settings)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.ClusterPutSettingsRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform cluster put settings request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "update cluster settings")
+}
+
+func (es *ESClientV6) ExcludeNodesFromAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, es, nodes, nil)
+}
+
+func (es *ESClientV6) IncludeNodesInAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, es, nil, nodes)
+}
+
+func (es *ESClientV6) DrainNode(ctx context.Context, node string) error {
+	if err := es.ExcludeNodesFromAllocation(ctx, node); err != nil {
+		return err
+	}
+	return waitForNodeDrained(ctx, es, node)
+}
+
+func (es *ESClientV6) SetShardAllocation(ctx context.Context, mode string) error {
+	if err := validateAllocationEnable(mode); err != nil {
+		return err
+	}
+	return es.putClusterSettings(ctx, persistentSetting(settingAllocationEnable, mode))
+}
+
+func (es *ESClientV6) WaitForGreen(ctx context.Context) error {
+	return waitForGreen(ctx, es)
+}
+
+func (es *ESClientV6) Flush(ctx context.Context, synced bool, indices ...string) error {
+	if !synced {
+		return es.flush(ctx, indices)
+	}
+	res, err := esapi.IndicesFlushSyncedRequest{
+		Index: indices,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform synced flush request")
+	}
+	defer closeBody(res.Body)
+
+	if res.StatusCode == http.StatusConflict {
+		// shards with ongoing indexing are not sync flushed, they only
+		// recover slower
+		klog.Warningf("synced flush failed for some shards, status code: %d", res.StatusCode)
+		return nil
+	}
+	return decodeResponse(res.StatusCode, res.Body, nil, "synced flush")
+}
+
+func (es *ESClientV6) flush(ctx context.Context, indices []string) error {
+	waitIfOngoing := true
+	res, err := esapi.IndicesFlushRequest{
+		Index:         indices,
+		WaitIfOngoing: &waitIfOngoing,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform flush request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "flush")
+}
+
+func (es *ESClientV6) ExplainAllocation(ctx context.Context, index string, shard int, primary bool) (*AllocationExplanation, error) {
+	body, err := jsonBody(allocationExplainRequest{Index: index, Shard: shard, Primary: primary})
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.ClusterAllocationExplainRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform allocation explain request")
+	}
+	defer closeBody(res.Body)
+
+	var explanation AllocationExplanation
+	if err := decodeResponse(res.StatusCode, res.Body, &explanation, fmt.Sprintf("explain allocation of shard %d of index %s", shard, index)); err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
+
+func (es *ESClientV6) DiagnoseUnassignedShards(ctx context.Context) ([]UnassignedShardDiagnosis, error) {
+	return diagnoseUnassignedShards(ctx, es)
+}
+
+func (es *ESClientV6) getClusterSettings(ctx context.Context) (*clusterSettingsResponse, error) {
+	includeDefaults, flatSettings := true, true
+	res, err := esapi.ClusterGetSettingsRequest{
+		IncludeDefaults: &includeDefaults,
+		FlatSettings:    &flatSettings,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cluster get settings request")
+	}
+	defer closeBody(res.Body)
+
+	var settings clusterSettingsResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &settings, "get cluster settings"); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (es *ESClientV6) GetDiskUsageReport(ctx context.Context) (*DiskUsageReport, error) {
+	settings, err := es.getClusterSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return diskUsageReport(ctx, es, settings)
+}
+
+func (es *ESClientV6) PutRemoteCluster(ctx context.Context, cluster RemoteCluster) error {
+	return es.putClusterSettings(ctx, remoteClusterSettings(cluster))
+}
+
+func (es *ESClientV6) DeleteRemoteCluster(ctx context.Context, name string) error {
+	return es.putClusterSettings(ctx, deleteRemoteClusterSettings(name))
+}
+
+func (es *ESClientV6) FollowIndex(ctx context.Context, followerIndex string, req FollowRequest) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 6")
+}
+
+func (es *ESClientV6) PauseFollow(ctx context.Context, followerIndex string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 6")
+}
+
+func (es *ESClientV6) ResumeFollow(ctx context.Context, followerIndex string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 6")
+}
+
+func (es *ESClientV6) Unfollow(ctx context.Context, followerIndex string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 6")
+}
+
+func (es *ESClientV6) PutAutoFollowPattern(ctx context.Context, name string, pattern AutoFollowPattern) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 6")
+}
+
+func (es *ESClientV6) DeleteAutoFollowPattern(ctx context.Context, remoteCluster, name string) error {
+	return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 6")
+}
+
+func (es *ESClientV6) GetFollowerStats(ctx context.Context, followerIndex string) (*FollowerStats, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "cross-cluster replication is not supported in es version 6")
+}
+
+func (es *ESClientV6) bulk(ctx context.Context, body io.Reader, refresh string) (*bulkResponse, error) {
+	res, err := esapi.BulkRequest{
+		Body:    body,
+		Refresh: refresh,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform bulk request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeBulkResponse(res.StatusCode, res.Body)
+}
+
+func (es *ESClientV6) NewBulkIndexer(config BulkIndexerConfig) (BulkIndexer, error) {
+	return newBulkIndexer(config, es.bulk, writeRequestType)
+}
+
+func (es *ESClientV6) Reindex(ctx context.Context, request ReindexRequest) (string, error) {
+	body, err := jsonBody(request)
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.ReindexRequest{
+		Body:              body,
+		Slices:            slicesV6(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform reindex request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "reindex to "+request.Dest.Index); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV6) UpdateByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	body, err := jsonBody(request)
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.UpdateByQueryRequest{
+		Index:             indices,
+		Body:              body,
+		Conflicts:         request.Conflicts,
+		Slices:            slicesV6(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform update by query request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "update by query"); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV6) DeleteByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	body, err := jsonBody(request.withQuery())
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.DeleteByQueryRequest{
+		Index:             indices,
+		Body:              body,
+		Conflicts:         request.Conflicts,
+		Slices:            slicesV6(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform delete by query request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "delete by query"); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV6) GetTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	res, err := esapi.TasksGetRequest{
+		TaskID: taskID,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get task request")
+	}
+	defer closeBody(res.Body)
+
+	var result TaskResult
+	if err := decodeResponse(res.StatusCode, res.Body, &result, "get task "+taskID); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (es *ESClientV6) ListTasks(ctx context.Context, actions ...string) ([]TaskInfo, error) {
+	detailed := true
+	res, err := esapi.TasksListRequest{
+		Actions:  actions,
+		Detailed: &detailed,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform list tasks request")
+	}
+	defer closeBody(res.Body)
+
+	var response tasksListResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "list tasks"); err != nil {
+		return nil, err
+	}
+	return response.tasks(), nil
+}
+
+func (es *ESClientV6) CancelTask(ctx context.Context, taskID string) error {
+	res, err := esapi.TasksCancelRequest{
+		TaskID: taskID,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform cancel task request")
+	}
+	defer closeBody(res.Body)
+
+	var response tasksCancelResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "cancel task "+taskID); err != nil {
+		return err
+	}
+	return response.err(taskID)
+}
+
+func (es *ESClientV6) WaitForTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	return waitForTask(ctx, es, taskID)
+}
+
+func (es *ESClientV6) ReloadSecureSettings(ctx context.Context, keystorePassword string, nodes ...string) (*ReloadSecureSettingsResult, error) {
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "reloading secure settings is not supported in es version 6")
 }
@@ -25,6 +25,8 @@ import (
 	"net/http"
 	"strings"
 
+	"kubedb.dev/db-client-go/dberrors"
+
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
 	esv7 "github.com/elastic/go-elasticsearch/v7"
@@ -35,10 +37,16 @@ import (
 	kutil "kmodules.xyz/client-go"
 )
 
-var _ ESClient = &ESClientV7{}
+var (
+	_ ESClient   = &ESClientV7{}
+	_ RoleMapper = &ESClientV7{}
+)
 
 type ESClientV7 struct {
 	client *esv7.Client
+	// security is set for the OpenDistro security plugin, which replaces
+	// the X-Pack security APIs.
+	security *securityPlugin
 }
 
 func (es *ESClientV7) ClusterHealthInfo() (map[string]interface{}, error) {
@@ -83,7 +91,7 @@ func (es *ESClientV7) ShardStats() ([]ShardInfo, error) {
 		Format: "json",
 		Pretty: true,
 		Human:  true,
-		H:      []string{"index", "shard", "prirep", "state", "unassigned.reason"},
+		H:      []string{"index", "shard", "prirep", "state", "node", "unassigned.reason"},
 	}
 
 	resp, err := req.Do(context.Background(), es.client)
@@ -151,17 +159,21 @@ func (es *ESClientV7) ClusterStatus() (string, error) {
 }
 
 func (es *ESClientV7) SyncCredentialFromSecret(secret *core.Secret) error {
+	if es.security != nil {
+		return es.security.syncCredentialFromSecret(context.Background(), secret)
+	}
+
 	// get auth creds from secret
 	var username, password string
 	if value, ok := secret.Data[core.BasicAuthUsernameKey]; ok {
 		username = string(value)
 	} else {
-		return errors.New("username is missing")
+		return dberrors.Errorf(dberrors.ErrInvalidSecret, "username is missing")
 	}
 	if value, ok := secret.Data[core.BasicAuthPasswordKey]; ok {
 		password = string(value)
 	} else {
-		return errors.New("password is missing")
+		return dberrors.Errorf(dberrors.ErrInvalidSecret, "password is missing")
 	}
 
 	// Build the request body.
@@ -200,7 +212,7 @@ func (es *ESClientV7) SyncCredentialFromSecret(secret *core.Secret) error {
 	}
 
 	klog.V(5).Infoln("Failed to sync", username, "credentials")
-	return errors.New("CredSyncFailed")
+	return dberrors.HTTPStatusError(res.StatusCode, "CredSyncFailed")
 }
 
 func (es *ESClientV7) GetClusterWriteStatus(ctx context.Context, db *dbapi.Elasticsearch) error {
@@ -332,6 +344,10 @@ func (es *ESClientV7) GetTotalDiskUsage(ctx context.Context) (string, error) {
 }
 
 func (es *ESClientV7) GetDBUserRole(ctx context.Context) (error, bool) {
+	if es.security != nil {
+		return es.security.getDBUserRole(ctx)
+	}
+
 	req := esapi.SecurityGetRoleRequest{
 		Name: []string{CustomRoleName},
 	}
@@ -357,6 +373,10 @@ func (es *ESClientV7) GetDBUserRole(ctx context.Context) (error, bool) {
 }
 
 func (es *ESClientV7) CreateDBUserRole(ctx context.Context) error {
+	if es.security != nil {
+		return es.security.createDBUserRole(ctx)
+	}
+
 	userRoleReqStruct := UserRoleReq{
 		[]string{PrivilegeCreateSnapshot, PrivilegeManage, PrivilegeManageILM, PrivilegeManageRoleup, PrivilegeMonitor, PrivilegeManageCCR},
 		[]DBPrivileges{
@@ -410,6 +430,16 @@ func (es *ESClientV7) CreateDBUserRole(ctx context.Context) error {
 	return nil
 }
 
+// MapDBUserRole maps the custom role created by CreateDBUserRole to users.
+// It is only supported with the OpenDistro security plugin, X-Pack roles are
+// assigned to the users themselves.
+func (es *ESClientV7) MapDBUserRole(ctx context.Context, users ...string) error {
+	if es.security == nil {
+		return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "role mapping is only supported with the OpenDistro security plugin")
+	}
+	return es.security.mapDBUserRole(ctx, users...)
+}
+
 func (es *ESClientV7) IndexExistsOrNot(index string) error {
 	req := esapi.IndicesExistsRequest{
 		Index: []string{index},
@@ -553,3 +583,959 @@ func (es *ESClientV7) PutData(index, id string, data map[string]interface{}) err
 	}
 	return nil
 }
+
+func (es *ESClientV7) CreateSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository, verify bool) error {
+	body, err := jsonBody(repository)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.SnapshotCreateRepositoryRequest{
+		Repository: name,
+		Body:       body,
+		Verify:     &verify,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform create snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "create snapshot repository "+name)
+}
+
+func (es *ESClientV7) VerifySnapshotRepository(ctx context.Context, name string) ([]string, error) {
+	res, err := esapi.SnapshotVerifyRepositoryRequest{
+		Repository: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform verify snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	var response verifyRepositoryResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "verify snapshot repository "+name); err != nil {
+		return nil, err
+	}
+	return response.nodeNames(), nil
+}
+
+func (es *ESClientV7) DeleteSnapshotRepository(ctx context.Context, name string) error {
+	res, err := esapi.SnapshotDeleteRepositoryRequest{
+		Repository: []string{name},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete snapshot repository "+name)
+}
+
+func (es *ESClientV7) CreateSnapshot(ctx context.Context, repository, snapshot string, opts SnapshotOptions) (*SnapshotInfo, error) {
+	body, err := jsonBody(opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.SnapshotCreateRequest{
+		Repository:        repository,
+		Snapshot:          snapshot,
+		Body:              body,
+		WaitForCompletion: &opts.WaitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform create snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotCreateResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "create snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.info(repository, snapshot), nil
+}
+
+func (es *ESClientV7) ListSnapshots(ctx context.Context, repository string) ([]SnapshotInfo, error) {
+	res, err := esapi.SnapshotGetRequest{
+		Repository: repository,
+		Snapshot:   []string{"_all"},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get snapshots request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotGetResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "list snapshots of repository "+repository); err != nil {
+		return nil, err
+	}
+	return response.Snapshots, nil
+}
+
+func (es *ESClientV7) GetSnapshotStatus(ctx context.Context, repository, snapshot string) (*SnapshotStatus, error) {
+	res, err := esapi.SnapshotStatusRequest{
+		Repository: repository,
+		Snapshot:   []string{snapshot},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform snapshot status request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotStatusResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get status of snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.status(snapshot)
+}
+
+func (es *ESClientV7) DeleteSnapshot(ctx context.Context, repository, snapshot string) error {
+	res, err := esapi.SnapshotDeleteRequest{
+		Repository: repository,
+		Snapshot:   snapshot,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete snapshot "+snapshot)
+}
+
+func (es *ESClientV7) RestoreSnapshot(ctx context.Context, repository, snapshot string, opts RestoreOptions) error {
+	body, err := jsonBody(opts)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.SnapshotRestoreRequest{
+		Repository:        repository,
+		Snapshot:          snapshot,
+		Body:              body,
+		WaitForCompletion: &opts.WaitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform restore snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "restore snapshot "+snapshot)
+}
+
+func (es *ESClientV7) GetClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	res, err := esapi.ClusterHealthRequest{}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cluster health request")
+	}
+	defer closeBody(res.Body)
+
+	var health ClusterHealth
+	if err := decodeResponse(res.StatusCode, res.Body, &health, "get cluster health"); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+func (es *ESClientV7) GetNodesStats(ctx context.Context) (*NodesStats, error) {
+	res, err := esapi.NodesStatsRequest{
+		Metric: []string{"jvm", "fs", "thread_pool", "breaker"},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform nodes stats request")
+	}
+	defer closeBody(res.Body)
+
+	var stats NodesStats
+	if err := decodeResponse(res.StatusCode, res.Body, &stats, "get nodes stats"); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (es *ESClientV7) GetIndices(ctx context.Context) ([]IndexInfo, error) {
+	res, err := esapi.CatIndicesRequest{
+		Bytes:  "b",
+		Format: "json",
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cat indices request")
+	}
+	defer closeBody(res.Body)
+
+	var indices []IndexInfo
+	if err := decodeResponse(res.StatusCode, res.Body, &indices, "get indices"); err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+func (es *ESClientV7) PutLifecyclePolicy(ctx context.Context, name string, policy LifecyclePolicy) error {
+	body, err := jsonBody(policy.ilmPolicy())
+	if err != nil {
+		return err
+	}
+	res, err := esapi.ILMPutLifecycleRequest{
+		Policy: name,
+		Body:   body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put lifecycle policy request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put lifecycle policy "+name)
+}
+
+func (es *ESClientV7) GetLifecyclePolicy(ctx context.Context, name string) (*LifecyclePolicyInfo, error) {
+	res, err := esapi.ILMGetLifecycleRequest{
+		Policy: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get lifecycle policy request")
+	}
+	defer closeBody(res.Body)
+
+	var response ilmPolicyResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get lifecycle policy "+name); err != nil {
+		return nil, err
+	}
+	return response.info(name)
+}
+
+func (es *ESClientV7) DeleteLifecyclePolicy(ctx context.Context, name string) error {
+	res, err := esapi.ILMDeleteLifecycleRequest{
+		Policy: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete lifecycle policy request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete lifecycle policy "+name)
+}
+
+// AttachLifecyclePolicy sets the lifecycle policy and the rollover alias in
+// the settings of a composable index template.
+func (es *ESClientV7) AttachLifecyclePolicy(ctx context.Context, template, policy, rolloverAlias string) error {
+	res, err := esapi.IndicesGetIndexTemplateRequest{
+		Name: []string{template},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform get index template request")
+	}
+	defer closeBody(res.Body)
+
+	var templates indexTemplatesResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &templates, "get index template "+template); err != nil {
+		return err
+	}
+	indexTemplate, err := templates.template(template)
+	if err != nil {
+		return err
+	}
+	setTemplateSettings(indexTemplate, ilmTemplateSettings(policy, rolloverAlias))
+
+	body, err := jsonBody(indexTemplate)
+	if err != nil {
+		return err
+	}
+	putRes, err := esapi.IndicesPutIndexTemplateRequest{
+		Name: template,
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put index template request")
+	}
+	defer closeBody(putRes.Body)
+
+	return decodeResponse(putRes.StatusCode, putRes.Body, nil, "put index template "+template)
+}
+
+func (es *ESClientV7) ExplainLifecycle(ctx context.Context, index string) ([]LifecycleExplanation, error) {
+	res, err := esapi.ILMExplainLifecycleRequest{
+		Index: index,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform explain lifecycle request")
+	}
+	defer closeBody(res.Body)
+
+	var response ilmExplainResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "explain lifecycle of "+index); err != nil {
+		return nil, err
+	}
+	return response.explanations(), nil
+}
+
+func (es *ESClientV7) RetryLifecycle(ctx context.Context, index string) error {
+	res, err := esapi.ILMRetryRequest{
+		Index: index,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform retry lifecycle request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "retry lifecycle of "+index)
+}
+
+func (es *ESClientV7) PutLegacyIndexTemplate(ctx context.Context, name string, template LegacyIndexTemplate) error {
+	body, err := jsonBody(template)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesPutTemplateRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put template "+name)
+}
+
+func (es *ESClientV7) GetLegacyIndexTemplate(ctx context.Context, name string) (*LegacyIndexTemplate, error) {
+	res, err := esapi.IndicesGetTemplateRequest{
+		Name: []string{name},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get template request")
+	}
+	defer closeBody(res.Body)
+
+	var response map[string]LegacyIndexTemplate
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get template "+name); err != nil {
+		return nil, err
+	}
+	return legacyTemplate(response, name)
+}
+
+func (es *ESClientV7) DeleteLegacyIndexTemplate(ctx context.Context, name string) error {
+	res, err := esapi.IndicesDeleteTemplateRequest{
+		Name: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete template "+name)
+}
+
+func (es *ESClientV7) CreateIndexWithBody(ctx context.Context, index string, definition IndexBody) error {
+	body, err := jsonBody(definition)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesCreateRequest{
+		Index: index,
+		Body:  body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform create index request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "create index "+index)
+}
+
+func (es *ESClientV7) UpdateAliases(ctx context.Context, actions ...AliasAction) error {
+	body, err := jsonBody(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesUpdateAliasesRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform update aliases request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "update aliases")
+}
+
+func (es *ESClientV7) RolloverAlias(ctx context.Context, alias, newIndex string, conditions *RolloverConditions) (*RolloverResult, error) {
+	body, err := jsonBody(rolloverRequest{Conditions: conditions})
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.IndicesRolloverRequest{
+		Alias:    alias,
+		NewIndex: newIndex,
+		Body:     body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform rollover request")
+	}
+	defer closeBody(res.Body)
+
+	var result RolloverResult
+	if err := decodeResponse(res.StatusCode, res.Body, &result, "roll over alias "+alias); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (es *ESClientV7) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	body, err := jsonBody(template)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.IndicesPutIndexTemplateRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put index template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put index template "+name)
+}
+
+func (es *ESClientV7) GetIndexTemplate(ctx context.Context, name string) (*IndexTemplate, error) {
+	res, err := esapi.IndicesGetIndexTemplateRequest{
+		Name: []string{name},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get index template request")
+	}
+	defer closeBody(res.Body)
+
+	var response indexTemplateGetResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get index template "+name); err != nil {
+		return nil, err
+	}
+	return response.template(name)
+}
+
+func (es *ESClientV7) DeleteIndexTemplate(ctx context.Context, name string) error {
+	res, err := esapi.IndicesDeleteIndexTemplateRequest{
+		Name: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete index template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete index template "+name)
+}
+
+func (es *ESClientV7) PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error {
+	body, err := jsonBody(template)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.ClusterPutComponentTemplateRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put component template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put component template "+name)
+}
+
+func (es *ESClientV7) GetComponentTemplate(ctx context.Context, name string) (*ComponentTemplate, error) {
+	res, err := esapi.ClusterGetComponentTemplateRequest{
+		Name: []string{name},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get component template request")
+	}
+	defer closeBody(res.Body)
+
+	var response componentTemplateGetResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get component template "+name); err != nil {
+		return nil, err
+	}
+	return response.template(name)
+}
+
+func (es *ESClientV7) DeleteComponentTemplate(ctx context.Context, name string) error {
+	res, err := esapi.ClusterDeleteComponentTemplateRequest{
+		Name: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete component template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete component template "+name)
+}
+
+func (es *ESClientV7) putClusterSettings(ctx context.Context, settings clusterSettingsRequest) error {
+	body, err := jsonBody(settings)
+	if err != nil {
+		return err
+	}
+	res, err := esapi.ClusterPutSettingsRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform cluster put settings request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "update cluster settings")
+}
+
+func (es *ESClientV7) ExcludeNodesFromAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, es, nodes, nil)
+}
+
+func (es *ESClientV7) IncludeNodesInAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, es, nil, nodes)
+}
+
+func (es *ESClientV7) DrainNode(ctx context.Context, node string) error {
+	if err := es.ExcludeNodesFromAllocation(ctx, node); err != nil {
+		return err
+	}
+	return waitForNodeDrained(ctx, es, node)
+}
+
+func (es *ESClientV7) SetShardAllocation(ctx context.Context, mode string) error {
+	if err := validateAllocationEnable(mode); err != nil {
+		return err
+	}
+	return es.putClusterSettings(ctx, persistentSetting(settingAllocationEnable, mode))
+}
+
+func (es *ESClientV7) WaitForGreen(ctx context.Context) error {
+	return waitForGreen(ctx, es)
+}
+
+func (es *ESClientV7) Flush(ctx context.Context, synced bool, indices ...string) error {
+	if !synced {
+		return es.flush(ctx, indices)
+	}
+	res, err := esapi.IndicesFlushSyncedRequest{
+		Index: indices,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform synced flush request")
+	}
+	defer closeBody(res.Body)
+
+	if res.StatusCode == http.StatusConflict {
+		// shards with ongoing indexing are not sync flushed, they only
+		// recover slower
+		klog.Warningf("synced flush failed for some shards, status code: %d", res.StatusCode)
+		return nil
+	}
+	return decodeResponse(res.StatusCode, res.Body, nil, "synced flush")
+}
+
+func (es *ESClientV7) flush(ctx context.Context, indices []string) error {
+	waitIfOngoing := true
+	res, err := esapi.IndicesFlushRequest{
+		Index:         indices,
+		WaitIfOngoing: &waitIfOngoing,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform flush request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "flush")
+}
+
+func (es *ESClientV7) ExplainAllocation(ctx context.Context, index string, shard int, primary bool) (*AllocationExplanation, error) {
+	body, err := jsonBody(allocationExplainRequest{Index: index, Shard: shard, Primary: primary})
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.ClusterAllocationExplainRequest{
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform allocation explain request")
+	}
+	defer closeBody(res.Body)
+
+	var explanation AllocationExplanation
+	if err := decodeResponse(res.StatusCode, res.Body, &explanation, fmt.Sprintf("explain allocation of shard %d of index %s", shard, index)); err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
+
+func (es *ESClientV7) DiagnoseUnassignedShards(ctx context.Context) ([]UnassignedShardDiagnosis, error) {
+	return diagnoseUnassignedShards(ctx, es)
+}
+
+func (es *ESClientV7) getClusterSettings(ctx context.Context) (*clusterSettingsResponse, error) {
+	includeDefaults, flatSettings := true, true
+	res, err := esapi.ClusterGetSettingsRequest{
+		IncludeDefaults: &includeDefaults,
+		FlatSettings:    &flatSettings,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cluster get settings request")
+	}
+	defer closeBody(res.Body)
+
+	var settings clusterSettingsResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &settings, "get cluster settings"); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (es *ESClientV7) GetDiskUsageReport(ctx context.Context) (*DiskUsageReport, error) {
+	settings, err := es.getClusterSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return diskUsageReport(ctx, es, settings)
+}
+
+func (es *ESClientV7) PutRemoteCluster(ctx context.Context, cluster RemoteCluster) error {
+	return es.putClusterSettings(ctx, remoteClusterSettings(cluster))
+}
+
+func (es *ESClientV7) DeleteRemoteCluster(ctx context.Context, name string) error {
+	return es.putClusterSettings(ctx, deleteRemoteClusterSettings(name))
+}
+
+func (es *ESClientV7) FollowIndex(ctx context.Context, followerIndex string, req FollowRequest) error {
+	body, err := jsonBody(ccrFollowRequest{RemoteCluster: req.RemoteCluster, LeaderIndex: req.LeaderIndex})
+	if err != nil {
+		return err
+	}
+	res, err := esapi.CCRFollowRequest{
+		Index:               followerIndex,
+		Body:                body,
+		WaitForActiveShards: "1",
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform follow request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "follow index "+req.LeaderIndex)
+}
+
+func (es *ESClientV7) PauseFollow(ctx context.Context, followerIndex string) error {
+	res, err := esapi.CCRPauseFollowRequest{
+		Index: followerIndex,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform pause follow request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "pause follower index "+followerIndex)
+}
+
+func (es *ESClientV7) ResumeFollow(ctx context.Context, followerIndex string) error {
+	res, err := esapi.CCRResumeFollowRequest{
+		Index: followerIndex,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform resume follow request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "resume follower index "+followerIndex)
+}
+
+// Unfollow stops the replication and turns the follower into a regular index.
+// The follower is paused, if it is active, and reopened.
+func (es *ESClientV7) Unfollow(ctx context.Context, followerIndex string) error {
+	info, err := es.getFollowInfo(ctx, followerIndex)
+	if err != nil {
+		return err
+	}
+	stats, err := followerStats(followerIndex, info, nil)
+	if err != nil {
+		return err
+	}
+	if stats.Status == FollowerStatusActive {
+		if err := es.PauseFollow(ctx, followerIndex); err != nil {
+			return err
+		}
+	}
+
+	closeRes, err := esapi.IndicesCloseRequest{
+		Index: []string{followerIndex},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform close index request")
+	}
+	defer closeBody(closeRes.Body)
+	if err := decodeResponse(closeRes.StatusCode, closeRes.Body, nil, "close index "+followerIndex); err != nil {
+		return err
+	}
+
+	res, err := esapi.CCRUnfollowRequest{
+		Index: followerIndex,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform unfollow request")
+	}
+	defer closeBody(res.Body)
+	if err := decodeResponse(res.StatusCode, res.Body, nil, "unfollow index "+followerIndex); err != nil {
+		return err
+	}
+
+	openRes, err := esapi.IndicesOpenRequest{
+		Index: []string{followerIndex},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform open index request")
+	}
+	defer closeBody(openRes.Body)
+
+	return decodeResponse(openRes.StatusCode, openRes.Body, nil, "open index "+followerIndex)
+}
+
+// PutAutoFollowPattern creates or updates an auto-follow pattern.
+func (es *ESClientV7) PutAutoFollowPattern(ctx context.Context, name string, pattern AutoFollowPattern) error {
+	body, err := jsonBody(ccrAutoFollowPattern{
+		RemoteCluster:       pattern.RemoteCluster,
+		LeaderIndexPatterns: pattern.LeaderIndexPatterns,
+		FollowIndexPattern:  pattern.FollowIndexPattern,
+	})
+	if err != nil {
+		return err
+	}
+	res, err := esapi.CCRPutAutoFollowPatternRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put auto-follow pattern request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put auto-follow pattern "+name)
+}
+
+// DeleteAutoFollowPattern deletes an auto-follow pattern, the remote cluster
+// is not needed.
+func (es *ESClientV7) DeleteAutoFollowPattern(ctx context.Context, remoteCluster, name string) error {
+	res, err := esapi.CCRDeleteAutoFollowPatternRequest{
+		Name: name,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete auto-follow pattern request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete auto-follow pattern "+name)
+}
+
+func (es *ESClientV7) getFollowInfo(ctx context.Context, followerIndex string) (*ccrFollowInfoResponse, error) {
+	res, err := esapi.CCRFollowInfoRequest{
+		Index: []string{followerIndex},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform follow info request")
+	}
+	defer closeBody(res.Body)
+
+	var info ccrFollowInfoResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &info, "get follow info of "+followerIndex); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+func (es *ESClientV7) GetFollowerStats(ctx context.Context, followerIndex string) (*FollowerStats, error) {
+	info, err := es.getFollowInfo(ctx, followerIndex)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := followerStats(followerIndex, info, nil)
+	if err != nil || stats.Status != FollowerStatusActive {
+		return stats, err
+	}
+
+	res, err := esapi.CCRFollowStatsRequest{
+		Index: []string{followerIndex},
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform follow stats request")
+	}
+	defer closeBody(res.Body)
+
+	var response ccrFollowStatsResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get follow stats of "+followerIndex); err != nil {
+		return nil, err
+	}
+	return followerStats(followerIndex, info, &response)
+}
+
+func (es *ESClientV7) bulk(ctx context.Context, body io.Reader, refresh string) (*bulkResponse, error) {
+	res, err := esapi.BulkRequest{
+		Body:    body,
+		Refresh: refresh,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform bulk request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeBulkResponse(res.StatusCode, res.Body)
+}
+
+func (es *ESClientV7) NewBulkIndexer(config BulkIndexerConfig) (BulkIndexer, error) {
+	return newBulkIndexer(config, es.bulk, "")
+}
+
+func (es *ESClientV7) Reindex(ctx context.Context, request ReindexRequest) (string, error) {
+	body, err := jsonBody(request)
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.ReindexRequest{
+		Body:              body,
+		Slices:            slicesParam(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform reindex request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "reindex to "+request.Dest.Index); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV7) UpdateByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	body, err := jsonBody(request)
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.UpdateByQueryRequest{
+		Index:             indices,
+		Body:              body,
+		Conflicts:         request.Conflicts,
+		Slices:            slicesParam(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform update by query request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "update by query"); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV7) DeleteByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	body, err := jsonBody(request.withQuery())
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := esapi.DeleteByQueryRequest{
+		Index:             indices,
+		Body:              body,
+		Conflicts:         request.Conflicts,
+		Slices:            slicesParam(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform delete by query request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "delete by query"); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (es *ESClientV7) GetTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	res, err := esapi.TasksGetRequest{
+		TaskID: taskID,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get task request")
+	}
+	defer closeBody(res.Body)
+
+	var result TaskResult
+	if err := decodeResponse(res.StatusCode, res.Body, &result, "get task "+taskID); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (es *ESClientV7) ListTasks(ctx context.Context, actions ...string) ([]TaskInfo, error) {
+	detailed := true
+	res, err := esapi.TasksListRequest{
+		Actions:  actions,
+		Detailed: &detailed,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform list tasks request")
+	}
+	defer closeBody(res.Body)
+
+	var response tasksListResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "list tasks"); err != nil {
+		return nil, err
+	}
+	return response.tasks(), nil
+}
+
+func (es *ESClientV7) CancelTask(ctx context.Context, taskID string) error {
+	res, err := esapi.TasksCancelRequest{
+		TaskID: taskID,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform cancel task request")
+	}
+	defer closeBody(res.Body)
+
+	var response tasksCancelResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "cancel task "+taskID); err != nil {
+		return err
+	}
+	return response.err(taskID)
+}
+
+func (es *ESClientV7) WaitForTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	return waitForTask(ctx, es, taskID)
+}
+
+// ReloadSecureSettings reloads the keystore of nodes, all the nodes when none
+// is given. keystorePassword is empty for a keystore without a password.
+func (es *ESClientV7) ReloadSecureSettings(ctx context.Context, keystorePassword string, nodes ...string) (*ReloadSecureSettingsResult, error) {
+	body, err := jsonBody(reloadSecureSettingsRequest{SecureSettingsPassword: keystorePassword})
+	if err != nil {
+		return nil, err
+	}
+	res, err := esapi.NodesReloadSecureSettingsRequest{
+		NodeID: nodes,
+		Body:   body,
+	}.Do(ctx, es.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform reload secure settings request")
+	}
+	defer closeBody(res.Body)
+
+	var response reloadSecureSettingsResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "reload secure settings"); err != nil {
+		return nil, err
+	}
+	return response.result(), nil
+}
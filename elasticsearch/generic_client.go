@@ -0,0 +1,895 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"kubedb.dev/db-client-go/dberrors"
+
+	catalog "kubedb.dev/apimachinery/apis/catalog/v1alpha1"
+	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	kutil "kmodules.xyz/client-go"
+)
+
+const (
+	DistributionElasticsearch = "elasticsearch"
+	DistributionOpenSearch    = "opensearch"
+)
+
+// ServerInfo is the response of the root endpoint.
+type ServerInfo struct {
+	Name        string        `json:"name"`
+	ClusterName string        `json:"cluster_name"`
+	ClusterUUID string        `json:"cluster_uuid"`
+	Version     ServerVersion `json:"version"`
+	Tagline     string        `json:"tagline"`
+}
+
+type ServerVersion struct {
+	Number string `json:"number"`
+	// Distribution is only reported by OpenSearch.
+	Distribution string `json:"distribution,omitempty"`
+	BuildFlavor  string `json:"build_flavor,omitempty"`
+}
+
+func (i *ServerInfo) distribution() string {
+	if i.Version.Distribution == DistributionOpenSearch {
+		return DistributionOpenSearch
+	}
+	return DistributionElasticsearch
+}
+
+// detectAuthPlugin returns the auth plugin of the catalog that matches the
+// database. Elasticsearch is checked for the OpenDistro and Search Guard
+// security plugins and for X-Pack, which is bundled with the default
+// distribution since 6.3. The plugin is empty for an Elasticsearch with none
+// of them, e.g. the oss distribution.
+func detectAuthPlugin(ctx context.Context, client performer, info *ServerInfo) (catalog.ElasticsearchAuthPlugin, error) {
+	if info.distribution() == DistributionOpenSearch {
+		return catalog.ElasticsearchAuthPluginOpenSearch, nil
+	}
+	var plugins []struct {
+		Component string `json:"component"`
+	}
+	if err := performJSON(ctx, client, http.MethodGet, "/_cat/plugins?format=json&h=component", nil, &plugins, "list plugins"); err != nil {
+		return "", err
+	}
+	xpack := info.Version.BuildFlavor == "default"
+	for _, plugin := range plugins {
+		switch {
+		case strings.HasPrefix(plugin.Component, "opendistro_security"), strings.HasPrefix(plugin.Component, "opendistro-security"):
+			return catalog.ElasticsearchAuthPluginOpenDistro, nil
+		case strings.HasPrefix(plugin.Component, "search-guard"):
+			return catalog.ElasticsearchAuthPluginSearchGuard, nil
+		case strings.HasPrefix(plugin.Component, "x-pack"):
+			xpack = true
+		}
+	}
+	if xpack {
+		return catalog.ElasticsearchAuthPluginXpack, nil
+	}
+	return "", nil
+}
+
+func (i *ServerInfo) version() (*semver.Version, error) {
+	version, err := semver.NewVersion(i.Version.Number)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to parse version %q", i.Version.Number)
+	}
+	return version, nil
+}
+
+// restyPerformer sends the requests of the shared helpers through a resty
+// client, which sets the base URL and the credentials.
+type restyPerformer struct {
+	client *resty.Client
+}
+
+func (p restyPerformer) Perform(req *http.Request) (*http.Response, error) {
+	r := p.client.R().
+		SetContext(req.Context()).
+		SetDoNotParseResponse(true)
+	for key, values := range req.Header {
+		r.Header[key] = values
+	}
+	if req.Body != nil {
+		r.SetBody(req.Body)
+	}
+	res, err := r.Execute(req.Method, req.URL.String())
+	if err != nil {
+		return nil, err
+	}
+	return res.RawResponse, nil
+}
+
+func getServerInfo(ctx context.Context, client performer) (*ServerInfo, error) {
+	var info ServerInfo
+	if err := performJSON(ctx, client, http.MethodGet, "/", nil, &info, "get server info"); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+var _ ESClient = &GenericClient{}
+
+// GenericClient implements ESClient with plain REST calls for the versions
+// that have no versioned client, e.g. a new major version. It uses the APIs
+// of the latest Elasticsearch and OpenSearch versions.
+type GenericClient struct {
+	client   performer
+	info     ServerInfo
+	security *securityPlugin
+}
+
+// newGenericClient detects the distribution and the version of the database
+// from the root endpoint, and the auth plugin when it is empty.
+func newGenericClient(ctx context.Context, client *resty.Client, authPlugin catalog.ElasticsearchAuthPlugin) (*GenericClient, error) {
+	p := restyPerformer{client: client}
+	info, err := getServerInfo(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	c := &GenericClient{client: p, info: *info}
+	if authPlugin == "" {
+		authPlugin, err = detectAuthPlugin(ctx, p, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if authPlugin == catalog.ElasticsearchAuthPluginOpenSearch || authPlugin == catalog.ElasticsearchAuthPluginOpenDistro {
+		c.security = newSecurityPlugin(p)
+	}
+	klog.V(5).Infof("using generic client for %s version %s", info.distribution(), info.Version.Number)
+	return c, nil
+}
+
+// Info returns the info of the database detected by the client.
+func (c *GenericClient) Info() ServerInfo {
+	return c.info
+}
+
+func (c *GenericClient) isOpenSearch() bool {
+	return c.info.distribution() == DistributionOpenSearch
+}
+
+func (c *GenericClient) do(ctx context.Context, method, path string, body, out interface{}, action string) error {
+	return performJSON(ctx, c.client, method, path, body, out, action)
+}
+
+func withQuery(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// joinEscaped escapes the names of a comma separated path segment.
+func joinEscaped(names []string) string {
+	escaped := make([]string, 0, len(names))
+	for _, name := range names {
+		escaped = append(escaped, url.PathEscape(name))
+	}
+	return strings.Join(escaped, ",")
+}
+
+func (c *GenericClient) ClusterHealthInfo() (map[string]interface{}, error) {
+	response := make(map[string]interface{})
+	if err := c.do(context.Background(), http.MethodGet, "/_cluster/health", nil, &response, "get cluster health"); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+func (c *GenericClient) ClusterStatus() (string, error) {
+	health, err := c.GetClusterHealth(context.Background())
+	if err != nil {
+		return "", err
+	}
+	if health.Status == "" {
+		return "", errors.New("status is missing")
+	}
+	return health.Status, nil
+}
+
+func (c *GenericClient) NodesStats() (map[string]interface{}, error) {
+	nodesStats := make(map[string]interface{})
+	if err := c.do(context.Background(), http.MethodGet, "/_nodes/stats", nil, &nodesStats, "get nodes stats"); err != nil {
+		return nil, err
+	}
+	return nodesStats, nil
+}
+
+func (c *GenericClient) ShardStats() ([]ShardInfo, error) {
+	query := url.Values{
+		"format": {"json"},
+		"bytes":  {"b"},
+		"h":      {"index,shard,prirep,state,node,unassigned.reason"},
+	}
+	var shardStats []ShardInfo
+	if err := c.do(context.Background(), http.MethodGet, withQuery("/_cat/shards", query), nil, &shardStats, "get shard stats"); err != nil {
+		return nil, err
+	}
+	return shardStats, nil
+}
+
+func (c *GenericClient) GetIndicesInfo() ([]interface{}, error) {
+	query := url.Values{
+		"format": {"json"},
+		"bytes":  {"b"},
+	}
+	indicesInfo := make([]interface{}, 0)
+	if err := c.do(context.Background(), http.MethodGet, withQuery("/_cat/indices", query), nil, &indicesInfo, "get indices info"); err != nil {
+		return nil, err
+	}
+	return indicesInfo, nil
+}
+
+func (c *GenericClient) SyncCredentialFromSecret(secret *core.Secret) error {
+	if c.security != nil {
+		return c.security.syncCredentialFromSecret(context.Background(), secret)
+	}
+
+	var username, password string
+	if value, ok := secret.Data[core.BasicAuthUsernameKey]; ok {
+		username = string(value)
+	} else {
+		return dberrors.Errorf(dberrors.ErrInvalidSecret, "username is missing")
+	}
+	if value, ok := secret.Data[core.BasicAuthPasswordKey]; ok {
+		password = string(value)
+	} else {
+		return dberrors.Errorf(dberrors.ErrInvalidSecret, "password is missing")
+	}
+
+	body := map[string]string{"password": password}
+	err := c.do(context.Background(), http.MethodPut, "/_security/user/"+url.PathEscape(username)+"/_password", body, nil, "sync credentials of user "+username)
+	if err != nil {
+		klog.V(5).Infoln("Failed to sync", username, "credentials")
+		return err
+	}
+	klog.V(5).Infoln(username, "user credentials successfully synced")
+	return nil
+}
+
+func (c *GenericClient) GetClusterWriteStatus(ctx context.Context, db *dbapi.Elasticsearch) error {
+	// index the db specs as the document, replacing the existing one
+	path := "/" + writeRequestIndex + "/" + writeRequestType + "/" + writeRequestID
+	return c.do(ctx, http.MethodPut, path, db.Spec, nil, "perform write request")
+}
+
+func (c *GenericClient) GetClusterReadStatus(ctx context.Context, db *dbapi.Elasticsearch) error {
+	path := "/" + writeRequestIndex + "/" + writeRequestType + "/" + writeRequestID
+	err := c.do(ctx, http.MethodGet, path, nil, nil, "perform read request")
+	if isNotFound(err) {
+		return kutil.ErrNotFound
+	}
+	return err
+}
+
+func (c *GenericClient) GetTotalDiskUsage(ctx context.Context) (string, error) {
+	// The disk usage API is expensive and missing in OpenSearch, so the store
+	// size of all the indices is taken from the index stats.
+	var response struct {
+		All struct {
+			Total struct {
+				Store struct {
+					SizeInBytes float64 `json:"size_in_bytes"`
+				} `json:"store"`
+			} `json:"total"`
+		} `json:"_all"`
+	}
+	path := "/" + diskUsageRequestIndex + "/_stats/store"
+	if err := c.do(ctx, http.MethodGet, path, nil, &response, "get indices store stats"); err != nil {
+		return "", err
+	}
+	return diskUsageQuantity(response.All.Total.Store.SizeInBytes), nil
+}
+
+func (c *GenericClient) GetDBUserRole(ctx context.Context) (error, bool) {
+	if c.security != nil {
+		return c.security.getDBUserRole(ctx)
+	}
+
+	err := c.do(ctx, http.MethodGet, "/_security/role/"+CustomRoleName, nil, nil, "get DB user role")
+	if isNotFound(err) {
+		return nil, false
+	}
+	if err != nil {
+		klog.Errorf("failed to get existing DB user role, reason: %s", err)
+		return err, false
+	}
+	return nil, true
+}
+
+func (c *GenericClient) CreateDBUserRole(ctx context.Context) error {
+	if c.security != nil {
+		return c.security.createDBUserRole(ctx)
+	}
+
+	role := UserRoleReq{
+		Cluster: []string{PrivilegeCreateSnapshot, PrivilegeManage, PrivilegeManageILM, PrivilegeManageRoleup, PrivilegeMonitor, PrivilegeManageCCR},
+		Indices: []DBPrivileges{
+			{
+				Names:      []string{PrivilegeIndexAny},
+				Privileges: []string{PrivilegeRead, PrivilegeWrite, PrivilegeCreateIndex},
+			},
+		},
+		Applications: []ApplicationPrivileges{
+			{
+				Application: ApplicationKibana,
+				Privileges:  []string{PrivilegeRead, PrivilegeWrite},
+				Resources:   []string{PrivilegeIndexAny},
+			},
+		},
+		RunAs:             []string{},
+		TransientMetaData: TransientMetaPrivileges{Enabled: true},
+	}
+	if err := c.do(ctx, http.MethodPut, "/_security/role/"+CustomRoleName, role, nil, "create DB user role"); err != nil {
+		klog.Errorf("Failed to create DB user role, reason: %s", err)
+		return err
+	}
+	return nil
+}
+
+// MapDBUserRole maps the custom role created by CreateDBUserRole to users.
+// It is only supported with the OpenSearch security plugin.
+func (c *GenericClient) MapDBUserRole(ctx context.Context, users ...string) error {
+	if c.security == nil {
+		return dberrors.Errorf(dberrors.ErrUnsupportedVersion, "role mapping is only supported with the OpenSearch security plugin")
+	}
+	return c.security.mapDBUserRole(ctx, users...)
+}
+
+func (c *GenericClient) IndexExistsOrNot(index string) error {
+	err := c.do(context.Background(), http.MethodHead, "/"+url.PathEscape(index), nil, nil, "check index "+index)
+	if isNotFound(err) {
+		return errors.New("index does not exist")
+	}
+	if err != nil {
+		klog.Errorf("failed to check the existence of index %s, reason: %s", index, err)
+		return err
+	}
+	return nil
+}
+
+func (c *GenericClient) CreateIndex(index string) error {
+	return c.do(context.Background(), http.MethodPut, "/"+url.PathEscape(index), nil, nil, "create index "+index)
+}
+
+func (c *GenericClient) DeleteIndex(index string) error {
+	return c.do(context.Background(), http.MethodDelete, "/"+url.PathEscape(index), nil, nil, "delete index "+index)
+}
+
+func (c *GenericClient) CountData(index string) (int, error) {
+	var response struct {
+		Count int `json:"count"`
+	}
+	if err := c.do(context.Background(), http.MethodGet, "/"+url.PathEscape(index)+"/_count", nil, &response, "count documents of index "+index); err != nil {
+		return 0, err
+	}
+	return response.Count, nil
+}
+
+func (c *GenericClient) PutData(index, id string, data map[string]interface{}) error {
+	return c.do(context.Background(), http.MethodPut, "/"+url.PathEscape(index)+"/_create/"+url.PathEscape(id), data, nil, "put data in index "+index)
+}
+
+func (c *GenericClient) CreateSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository, verify bool) error {
+	path := withQuery("/_snapshot/"+url.PathEscape(name), url.Values{"verify": {strconv.FormatBool(verify)}})
+	return c.do(ctx, http.MethodPut, path, repository, nil, "create snapshot repository "+name)
+}
+
+func (c *GenericClient) VerifySnapshotRepository(ctx context.Context, name string) ([]string, error) {
+	var response verifyRepositoryResponse
+	if err := c.do(ctx, http.MethodPost, "/_snapshot/"+url.PathEscape(name)+"/_verify", nil, &response, "verify snapshot repository "+name); err != nil {
+		return nil, err
+	}
+	return response.nodeNames(), nil
+}
+
+func (c *GenericClient) DeleteSnapshotRepository(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/_snapshot/"+url.PathEscape(name), nil, nil, "delete snapshot repository "+name)
+}
+
+func (c *GenericClient) CreateSnapshot(ctx context.Context, repository, snapshot string, opts SnapshotOptions) (*SnapshotInfo, error) {
+	path := withQuery("/_snapshot/"+url.PathEscape(repository)+"/"+url.PathEscape(snapshot), url.Values{"wait_for_completion": {strconv.FormatBool(opts.WaitForCompletion)}})
+	var response snapshotCreateResponse
+	if err := c.do(ctx, http.MethodPut, path, opts, &response, "create snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.info(repository, snapshot), nil
+}
+
+func (c *GenericClient) ListSnapshots(ctx context.Context, repository string) ([]SnapshotInfo, error) {
+	var response snapshotGetResponse
+	if err := c.do(ctx, http.MethodGet, "/_snapshot/"+url.PathEscape(repository)+"/_all", nil, &response, "list snapshots of repository "+repository); err != nil {
+		return nil, err
+	}
+	return response.Snapshots, nil
+}
+
+func (c *GenericClient) GetSnapshotStatus(ctx context.Context, repository, snapshot string) (*SnapshotStatus, error) {
+	var response snapshotStatusResponse
+	if err := c.do(ctx, http.MethodGet, "/_snapshot/"+url.PathEscape(repository)+"/"+url.PathEscape(snapshot)+"/_status", nil, &response, "get status of snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.status(snapshot)
+}
+
+func (c *GenericClient) DeleteSnapshot(ctx context.Context, repository, snapshot string) error {
+	return c.do(ctx, http.MethodDelete, "/_snapshot/"+url.PathEscape(repository)+"/"+url.PathEscape(snapshot), nil, nil, "delete snapshot "+snapshot)
+}
+
+func (c *GenericClient) RestoreSnapshot(ctx context.Context, repository, snapshot string, opts RestoreOptions) error {
+	path := withQuery("/_snapshot/"+url.PathEscape(repository)+"/"+url.PathEscape(snapshot)+"/_restore", url.Values{"wait_for_completion": {strconv.FormatBool(opts.WaitForCompletion)}})
+	return c.do(ctx, http.MethodPost, path, opts, nil, "restore snapshot "+snapshot)
+}
+
+func (c *GenericClient) GetClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	var health ClusterHealth
+	if err := c.do(ctx, http.MethodGet, "/_cluster/health", nil, &health, "get cluster health"); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+func (c *GenericClient) GetNodesStats(ctx context.Context) (*NodesStats, error) {
+	var stats NodesStats
+	if err := c.do(ctx, http.MethodGet, "/_nodes/stats/jvm,fs,thread_pool,breaker", nil, &stats, "get nodes stats"); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (c *GenericClient) GetIndices(ctx context.Context) ([]IndexInfo, error) {
+	query := url.Values{
+		"format": {"json"},
+		"bytes":  {"b"},
+	}
+	var indices []IndexInfo
+	if err := c.do(ctx, http.MethodGet, withQuery("/_cat/indices", query), nil, &indices, "get indices"); err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+func (c *GenericClient) PutLifecyclePolicy(ctx context.Context, name string, policy LifecyclePolicy) error {
+	if c.isOpenSearch() {
+		return putISMPolicy(ctx, c.client, name, policy)
+	}
+	return c.do(ctx, http.MethodPut, "/_ilm/policy/"+url.PathEscape(name), policy.ilmPolicy(), nil, "put lifecycle policy "+name)
+}
+
+func (c *GenericClient) GetLifecyclePolicy(ctx context.Context, name string) (*LifecyclePolicyInfo, error) {
+	if c.isOpenSearch() {
+		return getISMPolicyInfo(ctx, c.client, name)
+	}
+	var response ilmPolicyResponse
+	if err := c.do(ctx, http.MethodGet, "/_ilm/policy/"+url.PathEscape(name), nil, &response, "get lifecycle policy "+name); err != nil {
+		return nil, err
+	}
+	return response.info(name)
+}
+
+func (c *GenericClient) DeleteLifecyclePolicy(ctx context.Context, name string) error {
+	if c.isOpenSearch() {
+		return deleteISMPolicy(ctx, c.client, name)
+	}
+	return c.do(ctx, http.MethodDelete, "/_ilm/policy/"+url.PathEscape(name), nil, nil, "delete lifecycle policy "+name)
+}
+
+func (c *GenericClient) AttachLifecyclePolicy(ctx context.Context, template, policy, rolloverAlias string) error {
+	if c.isOpenSearch() {
+		return attachISMPolicy(ctx, c.client, template, policy, rolloverAlias)
+	}
+
+	var templates indexTemplatesResponse
+	if err := c.do(ctx, http.MethodGet, "/_index_template/"+url.PathEscape(template), nil, &templates, "get index template "+template); err != nil {
+		return err
+	}
+	indexTemplate, err := templates.template(template)
+	if err != nil {
+		return err
+	}
+	setTemplateSettings(indexTemplate, ilmTemplateSettings(policy, rolloverAlias))
+	return c.do(ctx, http.MethodPut, "/_index_template/"+url.PathEscape(template), indexTemplate, nil, "put index template "+template)
+}
+
+func (c *GenericClient) ExplainLifecycle(ctx context.Context, index string) ([]LifecycleExplanation, error) {
+	if c.isOpenSearch() {
+		return explainISM(ctx, c.client, index)
+	}
+	var response ilmExplainResponse
+	if err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(index)+"/_ilm/explain", nil, &response, "explain lifecycle of "+index); err != nil {
+		return nil, err
+	}
+	return response.explanations(), nil
+}
+
+func (c *GenericClient) RetryLifecycle(ctx context.Context, index string) error {
+	if c.isOpenSearch() {
+		return retryISM(ctx, c.client, index)
+	}
+	return c.do(ctx, http.MethodPost, "/"+url.PathEscape(index)+"/_ilm/retry", nil, nil, "retry lifecycle of "+index)
+}
+
+func (c *GenericClient) PutLegacyIndexTemplate(ctx context.Context, name string, template LegacyIndexTemplate) error {
+	return c.do(ctx, http.MethodPut, "/_template/"+url.PathEscape(name), template, nil, "put template "+name)
+}
+
+func (c *GenericClient) GetLegacyIndexTemplate(ctx context.Context, name string) (*LegacyIndexTemplate, error) {
+	var response map[string]LegacyIndexTemplate
+	if err := c.do(ctx, http.MethodGet, "/_template/"+url.PathEscape(name), nil, &response, "get template "+name); err != nil {
+		return nil, err
+	}
+	return legacyTemplate(response, name)
+}
+
+func (c *GenericClient) DeleteLegacyIndexTemplate(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/_template/"+url.PathEscape(name), nil, nil, "delete template "+name)
+}
+
+func (c *GenericClient) CreateIndexWithBody(ctx context.Context, index string, definition IndexBody) error {
+	return c.do(ctx, http.MethodPut, "/"+url.PathEscape(index), definition, nil, "create index "+index)
+}
+
+func (c *GenericClient) UpdateAliases(ctx context.Context, actions ...AliasAction) error {
+	body := map[string]interface{}{"actions": actions}
+	return c.do(ctx, http.MethodPost, "/_aliases", body, nil, "update aliases")
+}
+
+func (c *GenericClient) RolloverAlias(ctx context.Context, alias, newIndex string, conditions *RolloverConditions) (*RolloverResult, error) {
+	path := "/" + url.PathEscape(alias) + "/_rollover"
+	if newIndex != "" {
+		path += "/" + url.PathEscape(newIndex)
+	}
+	var result RolloverResult
+	if err := c.do(ctx, http.MethodPost, path, rolloverRequest{Conditions: conditions}, &result, "roll over alias "+alias); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *GenericClient) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	return c.do(ctx, http.MethodPut, "/_index_template/"+url.PathEscape(name), template, nil, "put index template "+name)
+}
+
+func (c *GenericClient) GetIndexTemplate(ctx context.Context, name string) (*IndexTemplate, error) {
+	var response indexTemplateGetResponse
+	if err := c.do(ctx, http.MethodGet, "/_index_template/"+url.PathEscape(name), nil, &response, "get index template "+name); err != nil {
+		return nil, err
+	}
+	return response.template(name)
+}
+
+func (c *GenericClient) DeleteIndexTemplate(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/_index_template/"+url.PathEscape(name), nil, nil, "delete index template "+name)
+}
+
+func (c *GenericClient) PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error {
+	return c.do(ctx, http.MethodPut, "/_component_template/"+url.PathEscape(name), template, nil, "put component template "+name)
+}
+
+func (c *GenericClient) GetComponentTemplate(ctx context.Context, name string) (*ComponentTemplate, error) {
+	var response componentTemplateGetResponse
+	if err := c.do(ctx, http.MethodGet, "/_component_template/"+url.PathEscape(name), nil, &response, "get component template "+name); err != nil {
+		return nil, err
+	}
+	return response.template(name)
+}
+
+func (c *GenericClient) DeleteComponentTemplate(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodDelete, "/_component_template/"+url.PathEscape(name), nil, nil, "delete component template "+name)
+}
+
+func (c *GenericClient) ExcludeNodesFromAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, c, nodes, nil)
+}
+
+func (c *GenericClient) IncludeNodesInAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, c, nil, nodes)
+}
+
+func (c *GenericClient) DrainNode(ctx context.Context, node string) error {
+	if err := c.ExcludeNodesFromAllocation(ctx, node); err != nil {
+		return err
+	}
+	return waitForNodeDrained(ctx, c, node)
+}
+
+func (c *GenericClient) SetShardAllocation(ctx context.Context, mode string) error {
+	if err := validateAllocationEnable(mode); err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPut, "/_cluster/settings", persistentSetting(settingAllocationEnable, mode), nil, "update cluster settings")
+}
+
+func (c *GenericClient) WaitForGreen(ctx context.Context) error {
+	return waitForGreen(ctx, c)
+}
+
+// Flush flushes the indices, all of them when none is given. Synced flush is
+// removed in the latest versions, a plain flush has the same effect on
+// recovery.
+func (c *GenericClient) Flush(ctx context.Context, synced bool, indices ...string) error {
+	path := "/_flush"
+	if len(indices) > 0 {
+		path = "/" + joinEscaped(indices) + path
+	}
+	return c.do(ctx, http.MethodPost, withQuery(path, url.Values{"wait_if_ongoing": {"true"}}), nil, nil, "flush")
+}
+
+func (c *GenericClient) ExplainAllocation(ctx context.Context, index string, shard int, primary bool) (*AllocationExplanation, error) {
+	var explanation AllocationExplanation
+	body := allocationExplainRequest{Index: index, Shard: shard, Primary: primary}
+	if err := c.do(ctx, http.MethodPost, "/_cluster/allocation/explain", body, &explanation, fmt.Sprintf("explain allocation of shard %d of index %s", shard, index)); err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
+
+func (c *GenericClient) DiagnoseUnassignedShards(ctx context.Context) ([]UnassignedShardDiagnosis, error) {
+	return diagnoseUnassignedShards(ctx, c)
+}
+
+func (c *GenericClient) getClusterSettings(ctx context.Context) (*clusterSettingsResponse, error) {
+	var settings clusterSettingsResponse
+	path := withQuery("/_cluster/settings", url.Values{"include_defaults": {"true"}, "flat_settings": {"true"}})
+	if err := c.do(ctx, http.MethodGet, path, nil, &settings, "get cluster settings"); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (c *GenericClient) putClusterSettings(ctx context.Context, settings clusterSettingsRequest) error {
+	return c.do(ctx, http.MethodPut, "/_cluster/settings", settings, nil, "update cluster settings")
+}
+
+func (c *GenericClient) GetDiskUsageReport(ctx context.Context) (*DiskUsageReport, error) {
+	settings, err := c.getClusterSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return diskUsageReport(ctx, c, settings)
+}
+
+func (c *GenericClient) PutRemoteCluster(ctx context.Context, cluster RemoteCluster) error {
+	return c.do(ctx, http.MethodPut, "/_cluster/settings", remoteClusterSettings(cluster), nil, "update cluster settings")
+}
+
+func (c *GenericClient) DeleteRemoteCluster(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPut, "/_cluster/settings", deleteRemoteClusterSettings(name), nil, "update cluster settings")
+}
+
+func (c *GenericClient) FollowIndex(ctx context.Context, followerIndex string, req FollowRequest) error {
+	if c.isOpenSearch() {
+		return startReplication(ctx, c.client, followerIndex, req)
+	}
+	body := ccrFollowRequest{RemoteCluster: req.RemoteCluster, LeaderIndex: req.LeaderIndex}
+	path := withQuery("/"+url.PathEscape(followerIndex)+"/_ccr/follow", url.Values{"wait_for_active_shards": {"1"}})
+	return c.do(ctx, http.MethodPut, path, body, nil, "follow index "+req.LeaderIndex)
+}
+
+func (c *GenericClient) PauseFollow(ctx context.Context, followerIndex string) error {
+	if c.isOpenSearch() {
+		return replicationAction(ctx, c.client, followerIndex, "pause")
+	}
+	return c.do(ctx, http.MethodPost, "/"+url.PathEscape(followerIndex)+"/_ccr/pause_follow", nil, nil, "pause follower index "+followerIndex)
+}
+
+func (c *GenericClient) ResumeFollow(ctx context.Context, followerIndex string) error {
+	if c.isOpenSearch() {
+		return replicationAction(ctx, c.client, followerIndex, "resume")
+	}
+	return c.do(ctx, http.MethodPost, "/"+url.PathEscape(followerIndex)+"/_ccr/resume_follow", nil, nil, "resume follower index "+followerIndex)
+}
+
+// Unfollow stops the replication and turns the follower into a regular index.
+func (c *GenericClient) Unfollow(ctx context.Context, followerIndex string) error {
+	if c.isOpenSearch() {
+		return replicationAction(ctx, c.client, followerIndex, "stop")
+	}
+
+	var info ccrFollowInfoResponse
+	if err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(followerIndex)+"/_ccr/info", nil, &info, "get follow info of "+followerIndex); err != nil {
+		return err
+	}
+	stats, err := followerStats(followerIndex, &info, nil)
+	if err != nil {
+		return err
+	}
+	if stats.Status == FollowerStatusActive {
+		if err := c.PauseFollow(ctx, followerIndex); err != nil {
+			return err
+		}
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+url.PathEscape(followerIndex)+"/_close", nil, nil, "close index "+followerIndex); err != nil {
+		return err
+	}
+	if err := c.do(ctx, http.MethodPost, "/"+url.PathEscape(followerIndex)+"/_ccr/unfollow", nil, nil, "unfollow index "+followerIndex); err != nil {
+		return err
+	}
+	return c.do(ctx, http.MethodPost, "/"+url.PathEscape(followerIndex)+"/_open", nil, nil, "open index "+followerIndex)
+}
+
+func (c *GenericClient) PutAutoFollowPattern(ctx context.Context, name string, pattern AutoFollowPattern) error {
+	if c.isOpenSearch() {
+		return putReplicationAutoFollow(ctx, c.client, name, pattern)
+	}
+	body := ccrAutoFollowPattern{
+		RemoteCluster:       pattern.RemoteCluster,
+		LeaderIndexPatterns: pattern.LeaderIndexPatterns,
+		FollowIndexPattern:  pattern.FollowIndexPattern,
+	}
+	return c.do(ctx, http.MethodPut, "/_ccr/auto_follow/"+url.PathEscape(name), body, nil, "put auto-follow pattern "+name)
+}
+
+func (c *GenericClient) DeleteAutoFollowPattern(ctx context.Context, remoteCluster, name string) error {
+	if c.isOpenSearch() {
+		return deleteReplicationAutoFollow(ctx, c.client, remoteCluster, name)
+	}
+	return c.do(ctx, http.MethodDelete, "/_ccr/auto_follow/"+url.PathEscape(name), nil, nil, "delete auto-follow pattern "+name)
+}
+
+func (c *GenericClient) GetFollowerStats(ctx context.Context, followerIndex string) (*FollowerStats, error) {
+	if c.isOpenSearch() {
+		return getReplicationStatus(ctx, c.client, followerIndex)
+	}
+
+	var info ccrFollowInfoResponse
+	if err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(followerIndex)+"/_ccr/info", nil, &info, "get follow info of "+followerIndex); err != nil {
+		return nil, err
+	}
+	stats, err := followerStats(followerIndex, &info, nil)
+	if err != nil || stats.Status != FollowerStatusActive {
+		return stats, err
+	}
+	var response ccrFollowStatsResponse
+	if err := c.do(ctx, http.MethodGet, "/"+url.PathEscape(followerIndex)+"/_ccr/stats", nil, &response, "get follow stats of "+followerIndex); err != nil {
+		return nil, err
+	}
+	return followerStats(followerIndex, &info, &response)
+}
+
+func (c *GenericClient) bulk(ctx context.Context, body io.Reader, refresh string) (*bulkResponse, error) {
+	query := url.Values{}
+	if refresh != "" {
+		query.Set("refresh", refresh)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, withQuery("/_bulk", query), body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	res, err := c.client.Perform(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform bulk request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeBulkResponse(res.StatusCode, res.Body)
+}
+
+// NewBulkIndexer returns a bulk indexer without mapping types, which are
+// removed in the versions served by GenericClient.
+func (c *GenericClient) NewBulkIndexer(config BulkIndexerConfig) (BulkIndexer, error) {
+	return newBulkIndexer(config, c.bulk, "")
+}
+
+func asyncTaskQuery(conflicts string, slices int, requestsPerSecond *int) url.Values {
+	query := url.Values{}
+	query.Set("wait_for_completion", "false")
+	if conflicts != "" {
+		query.Set("conflicts", conflicts)
+	}
+	if slices != 0 {
+		query.Set("slices", strconv.Itoa(slices))
+	}
+	if requestsPerSecond != nil {
+		query.Set("requests_per_second", strconv.Itoa(*requestsPerSecond))
+	}
+	return query
+}
+
+func (c *GenericClient) Reindex(ctx context.Context, request ReindexRequest) (string, error) {
+	// conflicts of reindex is set in the body
+	path := withQuery("/_reindex", asyncTaskQuery("", request.Slices, request.RequestsPerSecond))
+	var response asyncTaskResponse
+	if err := c.do(ctx, http.MethodPost, path, request, &response, "reindex to "+request.Dest.Index); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (c *GenericClient) byQuery(ctx context.Context, api string, request ByQueryRequest, indices []string) (string, error) {
+	index := "_all"
+	if len(indices) > 0 {
+		index = strings.Join(indices, ",")
+	}
+	path := withQuery("/"+url.PathEscape(index)+"/_"+api, asyncTaskQuery(request.Conflicts, request.Slices, request.RequestsPerSecond))
+	var response asyncTaskResponse
+	if err := c.do(ctx, http.MethodPost, path, request.withQuery(), &response, strings.ReplaceAll(api, "_", " ")); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (c *GenericClient) UpdateByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	return c.byQuery(ctx, "update_by_query", request, indices)
+}
+
+func (c *GenericClient) DeleteByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	return c.byQuery(ctx, "delete_by_query", request, indices)
+}
+
+func (c *GenericClient) GetTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	var result TaskResult
+	if err := c.do(ctx, http.MethodGet, "/_tasks/"+url.PathEscape(taskID), nil, &result, "get task "+taskID); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (c *GenericClient) ListTasks(ctx context.Context, actions ...string) ([]TaskInfo, error) {
+	query := url.Values{}
+	query.Set("detailed", "true")
+	if len(actions) > 0 {
+		query.Set("actions", strings.Join(actions, ","))
+	}
+	var response tasksListResponse
+	if err := c.do(ctx, http.MethodGet, withQuery("/_tasks", query), nil, &response, "list tasks"); err != nil {
+		return nil, err
+	}
+	return response.tasks(), nil
+}
+
+func (c *GenericClient) CancelTask(ctx context.Context, taskID string) error {
+	var response tasksCancelResponse
+	if err := c.do(ctx, http.MethodPost, "/_tasks/"+url.PathEscape(taskID)+"/_cancel", nil, &response, "cancel task "+taskID); err != nil {
+		return err
+	}
+	return response.err(taskID)
+}
+
+func (c *GenericClient) WaitForTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	return waitForTask(ctx, c, taskID)
+}
+
+func (c *GenericClient) ReloadSecureSettings(ctx context.Context, keystorePassword string, nodes ...string) (*ReloadSecureSettingsResult, error) {
+	path := "/_nodes/reload_secure_settings"
+	if len(nodes) > 0 {
+		path = "/_nodes/" + joinEscaped(nodes) + "/reload_secure_settings"
+	}
+	var response reloadSecureSettingsResponse
+	if err := c.do(ctx, http.MethodPost, path, reloadSecureSettingsRequest{SecureSettingsPassword: keystorePassword}, &response, "reload secure settings"); err != nil {
+		return nil, err
+	}
+	return response.result(), nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	"kubedb.dev/db-client-go/dberrors"
+
+	catalog "kubedb.dev/apimachinery/apis/catalog/v1alpha1"
+
+	core "k8s.io/api/core/v1"
+)
+
+// pluginsPerformer answers the cat plugins request with the given body.
+type pluginsPerformer struct {
+	body string
+}
+
+func (p pluginsPerformer) Perform(req *http.Request) (*http.Response, error) {
+	if !strings.HasPrefix(req.URL.Path, "/_cat/plugins") {
+		return &http.Response{StatusCode: http.StatusNotFound, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(p.body))}, nil
+}
+
+func TestDetectAuthPlugin(t *testing.T) {
+	tests := []struct {
+		name    string
+		version ServerVersion
+		plugins string
+		want    catalog.ElasticsearchAuthPlugin
+	}{
+		{
+			name:    "opensearch",
+			version: ServerVersion{Number: "2.11.0", Distribution: "opensearch"},
+			want:    catalog.ElasticsearchAuthPluginOpenSearch,
+		},
+		{
+			name:    "opendistro",
+			version: ServerVersion{Number: "7.10.2", BuildFlavor: "oss"},
+			plugins: `[{"component":"opendistro_security"},{"component":"opendistro-job-scheduler"}]`,
+			want:    catalog.ElasticsearchAuthPluginOpenDistro,
+		},
+		{
+			name:    "searchguard",
+			version: ServerVersion{Number: "7.10.2", BuildFlavor: "oss"},
+			plugins: `[{"component":"search-guard-7"}]`,
+			want:    catalog.ElasticsearchAuthPluginSearchGuard,
+		},
+		{
+			name:    "default flavor",
+			version: ServerVersion{Number: "7.17.15", BuildFlavor: "default"},
+			plugins: `[]`,
+			want:    catalog.ElasticsearchAuthPluginXpack,
+		},
+		{
+			name:    "x-pack plugin",
+			version: ServerVersion{Number: "6.2.4"},
+			plugins: `[{"component":"x-pack-security"}]`,
+			want:    catalog.ElasticsearchAuthPluginXpack,
+		},
+		{
+			name:    "no security plugin",
+			version: ServerVersion{Number: "7.10.2", BuildFlavor: "oss"},
+			plugins: `[{"component":"analysis-icu"}]`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := detectAuthPlugin(context.Background(), pluginsPerformer{body: tt.plugins}, &ServerInfo{Version: tt.version})
+			if err != nil {
+				t.Fatalf("failed to detect auth plugin: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got auth plugin %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// recordingPerformer records the escaped paths of the requests and answers
+// them with status.
+type recordingPerformer struct {
+	status int
+	paths  []string
+}
+
+func (p *recordingPerformer) Perform(req *http.Request) (*http.Response, error) {
+	p.paths = append(p.paths, req.URL.EscapedPath())
+	return &http.Response{StatusCode: p.status, Body: io.NopCloser(strings.NewReader("{}"))}, nil
+}
+
+func TestGenericClientEscapesPaths(t *testing.T) {
+	p := &recordingPerformer{status: http.StatusOK}
+	c := &GenericClient{client: p}
+	secret := &core.Secret{Data: map[string][]byte{
+		core.BasicAuthUsernameKey: []byte("ops/admin"),
+		core.BasicAuthPasswordKey: []byte("pass"),
+	}}
+	if err := c.SyncCredentialFromSecret(secret); err != nil {
+		t.Fatalf("failed to sync credentials: %v", err)
+	}
+	if err := c.DeleteSnapshot(context.Background(), "s3 backup", "daily/1"); err != nil {
+		t.Fatalf("failed to delete snapshot: %v", err)
+	}
+	want := []string{
+		"/_security/user/ops%2Fadmin/_password",
+		"/_snapshot/s3%20backup/daily%2F1",
+	}
+	if !reflect.DeepEqual(p.paths, want) {
+		t.Errorf("got paths %v, want %v", p.paths, want)
+	}
+}
+
+func TestGenericClientIndexExistsOrNot(t *testing.T) {
+	for _, tt := range []struct {
+		status  int
+		wantErr bool
+		auth    bool
+	}{
+		{status: http.StatusOK},
+		{status: http.StatusNotFound, wantErr: true},
+		{status: http.StatusUnauthorized, wantErr: true, auth: true},
+	} {
+		c := &GenericClient{client: &recordingPerformer{status: tt.status}}
+		err := c.IndexExistsOrNot("logs")
+		if (err != nil) != tt.wantErr {
+			t.Errorf("status %d: got error %v", tt.status, err)
+		}
+		if tt.auth && !errors.Is(err, dberrors.ErrAuthRejected) {
+			t.Errorf("status %d: got error %v, want %v", tt.status, err, dberrors.ErrAuthRejected)
+		}
+	}
+}
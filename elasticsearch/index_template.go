@@ -0,0 +1,185 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+// IndexBody is the definition of an index, used to create an index and in
+// the index templates. Mappings are passed as is, so they must include the
+// mapping type on the versions that require it.
+type IndexBody struct {
+	Settings map[string]interface{} `json:"settings,omitempty"`
+	Mappings map[string]interface{} `json:"mappings,omitempty"`
+	Aliases  map[string]Alias       `json:"aliases,omitempty"`
+}
+
+type Alias struct {
+	Filter        map[string]interface{} `json:"filter,omitempty"`
+	Routing       string                 `json:"routing,omitempty"`
+	IndexRouting  string                 `json:"index_routing,omitempty"`
+	SearchRouting string                 `json:"search_routing,omitempty"`
+	IsWriteIndex  *bool                  `json:"is_write_index,omitempty"`
+	IsHidden      *bool                  `json:"is_hidden,omitempty"`
+}
+
+// IndexTemplate is a composable index template, available since
+// Elasticsearch 7.8 and in OpenSearch.
+type IndexTemplate struct {
+	IndexPatterns []string               `json:"index_patterns"`
+	Template      *IndexBody             `json:"template,omitempty"`
+	ComposedOf    []string               `json:"composed_of,omitempty"`
+	Priority      *int64                 `json:"priority,omitempty"`
+	Version       *int64                 `json:"version,omitempty"`
+	Meta          map[string]interface{} `json:"_meta,omitempty"`
+	DataStream    map[string]interface{} `json:"data_stream,omitempty"`
+}
+
+type ComponentTemplate struct {
+	Template IndexBody              `json:"template"`
+	Version  *int64                 `json:"version,omitempty"`
+	Meta     map[string]interface{} `json:"_meta,omitempty"`
+}
+
+// LegacyIndexTemplate is an index template of the "_template" API, which is
+// replaced by the composable index templates since Elasticsearch 7.8.
+type LegacyIndexTemplate struct {
+	IndexPatterns []string `json:"index_patterns"`
+	Order         int      `json:"order,omitempty"`
+	Version       *int64   `json:"version,omitempty"`
+	IndexBody
+}
+
+// legacyIndexTemplateV5 is a LegacyIndexTemplate in the format of version 5,
+// which supports a single index pattern named "template".
+type legacyIndexTemplateV5 struct {
+	Template string `json:"template"`
+	Order    int    `json:"order,omitempty"`
+	Version  *int64 `json:"version,omitempty"`
+	IndexBody
+}
+
+func (t *LegacyIndexTemplate) toV5() (*legacyIndexTemplateV5, error) {
+	if len(t.IndexPatterns) != 1 {
+		return nil, errors.New("index templates of es version 5 must have exactly one index pattern")
+	}
+	return &legacyIndexTemplateV5{
+		Template:  t.IndexPatterns[0],
+		Order:     t.Order,
+		Version:   t.Version,
+		IndexBody: t.IndexBody,
+	}, nil
+}
+
+func (t *legacyIndexTemplateV5) toLegacyIndexTemplate() *LegacyIndexTemplate {
+	return &LegacyIndexTemplate{
+		IndexPatterns: []string{t.Template},
+		Order:         t.Order,
+		Version:       t.Version,
+		IndexBody:     t.IndexBody,
+	}
+}
+
+const (
+	AliasActionAdd         = "add"
+	AliasActionRemove      = "remove"
+	AliasActionRemoveIndex = "remove_index"
+)
+
+// AliasAction is an action of the update aliases API. The actions passed to
+// UpdateAliases are applied atomically.
+type AliasAction struct {
+	// Action is one of AliasActionAdd, AliasActionRemove or
+	// AliasActionRemoveIndex.
+	Action string `json:"-"`
+	Index  string `json:"index"`
+	// Alias is not used by AliasActionRemoveIndex.
+	Alias        string                 `json:"alias,omitempty"`
+	Filter       map[string]interface{} `json:"filter,omitempty"`
+	Routing      string                 `json:"routing,omitempty"`
+	IsWriteIndex *bool                  `json:"is_write_index,omitempty"`
+}
+
+func (a AliasAction) MarshalJSON() ([]byte, error) {
+	type action AliasAction
+	return json.Marshal(map[string]action{a.Action: action(a)})
+}
+
+// AddAlias returns an action that adds alias to index.
+func AddAlias(index, alias string) AliasAction {
+	return AliasAction{Action: AliasActionAdd, Index: index, Alias: alias}
+}
+
+// RemoveAlias returns an action that removes alias from index.
+func RemoveAlias(index, alias string) AliasAction {
+	return AliasAction{Action: AliasActionRemove, Index: index, Alias: alias}
+}
+
+type RolloverResult struct {
+	OldIndex   string          `json:"old_index"`
+	NewIndex   string          `json:"new_index"`
+	RolledOver bool            `json:"rolled_over"`
+	DryRun     bool            `json:"dry_run"`
+	Conditions map[string]bool `json:"conditions"`
+}
+
+type indexTemplateGetResponse struct {
+	IndexTemplates []struct {
+		Name          string        `json:"name"`
+		IndexTemplate IndexTemplate `json:"index_template"`
+	} `json:"index_templates"`
+}
+
+func (r *indexTemplateGetResponse) template(name string) (*IndexTemplate, error) {
+	for i := range r.IndexTemplates {
+		if r.IndexTemplates[i].Name == name {
+			return &r.IndexTemplates[i].IndexTemplate, nil
+		}
+	}
+	return nil, errors.Errorf("index template %s is missing in the response", name)
+}
+
+type componentTemplateGetResponse struct {
+	ComponentTemplates []struct {
+		Name              string            `json:"name"`
+		ComponentTemplate ComponentTemplate `json:"component_template"`
+	} `json:"component_templates"`
+}
+
+func (r *componentTemplateGetResponse) template(name string) (*ComponentTemplate, error) {
+	for i := range r.ComponentTemplates {
+		if r.ComponentTemplates[i].Name == name {
+			return &r.ComponentTemplates[i].ComponentTemplate, nil
+		}
+	}
+	return nil, errors.Errorf("component template %s is missing in the response", name)
+}
+
+func legacyTemplate[T any](response map[string]T, name string) (*T, error) {
+	t, ok := response[name]
+	if !ok {
+		return nil, errors.Errorf("index template %s is missing in the response", name)
+	}
+	return &t, nil
+}
+
+type rolloverRequest struct {
+	Conditions *RolloverConditions `json:"conditions,omitempty"`
+}
@@ -27,6 +27,12 @@ import (
 	"net/http"
 	"time"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+	"kubedb.dev/db-client-go/retry"
+
 	catalog "kubedb.dev/apimachinery/apis/catalog/v1alpha1"
 	"kubedb.dev/apimachinery/apis/elasticsearch/v1alpha1"
 	"kubedb.dev/apimachinery/apis/kubedb"
@@ -44,17 +50,28 @@ import (
 	osv2 "github.com/opensearch-project/opensearch-go/v2"
 	osapiv2 "github.com/opensearch-project/opensearch-go/v2/opensearchapi"
 	"github.com/pkg/errors"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *dbapi.Elasticsearch
-	url     string
-	podName string
-	ctx     context.Context
+	kc             client.Client
+	db             *dbapi.Elasticsearch
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.Elasticsearch) *KubeDBClientBuilder {
@@ -74,11 +91,55 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuilder {
 	o.ctx = ctx
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default. The requests of the returned
+// client are never retried, since not all of them are idempotent.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases and of the
+// instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases
+// and of the instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 	if o.podName != "" {
 		o.url = o.ServiceURL()
@@ -89,46 +150,85 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 	if o.db == nil {
 		return nil, errors.New("db is empty")
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindElasticsearch, o.db.Namespace, o.db.Name, o.podName)...)
 
-	var esVersion catalog.ElasticsearchVersion
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.Version}, &esVersion)
+	dialContext := (&net.Dialer{
+		Timeout: 30 * time.Second,
+	}).DialContext
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
 	if err != nil {
-		return nil, errors.Errorf("Failed to get elasticsearchVersion with %s", err)
+		return nil, err
+	}
+	if dial != nil {
+		dialContext = dial
+	}
+
+	// the version is detected from the database when it is missing from the
+	// catalog
+	var esVersion catalog.ElasticsearchVersion
+	versionErr := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.Version}, &esVersion)
+	if versionErr != nil {
+		if !kerr.IsNotFound(versionErr) {
+			return nil, errors.Wrapf(versionErr, "failed to get elasticsearchVersion %s", o.db.Spec.Version)
+		}
+		klog.Warningf("ElasticsearchVersion %s is not found, detecting the version from the database", o.db.Spec.Version)
 	}
 
 	var authSecret core.Secret
 	var username, password string
 	if !o.db.Spec.DisableSecurity && o.db.Spec.AuthSecret != nil {
-		err = o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.AuthSecret.Name}, &authSecret)
+		err = o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+			return o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.AuthSecret.Name}, &authSecret)
+		})
 		if err != nil {
-			return nil, errors.Errorf("Failed to get auth secret with %s", err)
+			return nil, errors.Wrap(dberrors.SecretGetError(err), "failed to get auth secret")
 		}
 
 		if value, ok := authSecret.Data[core.BasicAuthUsernameKey]; ok {
 			username = string(value)
 		} else {
 			klog.Errorf("Failed for secret: %s/%s, username is missing", authSecret.Namespace, authSecret.Name)
-			return nil, errors.New("username is missing")
+			return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "username is missing")
 		}
 
 		if value, ok := authSecret.Data[core.BasicAuthPasswordKey]; ok {
 			password = string(value)
 		} else {
 			klog.Errorf("Failed for secret: %s/%s, password is missing", authSecret.Namespace, authSecret.Name)
-			return nil, errors.New("password is missing")
+			return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "password is missing")
 		}
 	}
 
+	if versionErr != nil {
+		info, authPlugin, err := o.detectServer(dialContext, username, password)
+		if err != nil {
+			return nil, err
+		}
+		version, err := info.version()
+		if err != nil {
+			return nil, err
+		}
+		return o.newClient(authPlugin, version, dialContext, username, password)
+	}
+
 	// parse version
 	version, err := semver.NewVersion(esVersion.Spec.Version)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to parse version")
 	}
+	return o.newClient(esVersion.Spec.AuthPlugin, version, dialContext, username, password)
+}
 
+// newClient returns the versioned client of the version, or the generic
+// client for the versions that have none.
+func (o *KubeDBClientBuilder) newClient(authPlugin catalog.ElasticsearchAuthPlugin, version *semver.Version, dialContext portforward.DialFunc, username, password string) (*Client, error) {
 	switch {
-	case esVersion.Spec.AuthPlugin == catalog.ElasticsearchAuthPluginXpack ||
-		esVersion.Spec.AuthPlugin == catalog.ElasticsearchAuthPluginSearchGuard ||
-		esVersion.Spec.AuthPlugin == catalog.ElasticsearchAuthPluginOpenDistro:
+	// an empty plugin is a detected Elasticsearch without an auth plugin
+	case authPlugin == catalog.ElasticsearchAuthPluginXpack ||
+		authPlugin == catalog.ElasticsearchAuthPluginSearchGuard ||
+		authPlugin == catalog.ElasticsearchAuthPluginOpenDistro ||
+		authPlugin == "":
 		switch {
 		// For Elasticsearch 5.x.x
 		case version.Major() == 5:
@@ -138,9 +238,7 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				Password:  password,
 				Transport: &http.Transport{
 					IdleConnTimeout: 3 * time.Second,
-					DialContext: (&net.Dialer{
-						Timeout: 30 * time.Second,
-					}).DialContext,
+					DialContext:     dialContext,
 					TLSClientConfig: &tls.Config{
 						InsecureSkipVerify: true,
 						MaxVersion:         tls.VersionTLS12,
@@ -152,24 +250,34 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				return nil, err
 			}
 			// do a manual health check to test client
-			res, err := esClient.Cluster.Health(
-				esClient.Cluster.Health.WithPretty(),
-			)
+			err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+				return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+					res, err := esClient.Cluster.Health(
+						esClient.Cluster.Health.WithContext(ctx),
+						esClient.Cluster.Health.WithPretty(),
+					)
+					if err != nil {
+						return dberrors.Classify(err)
+					}
+					defer func(Body io.ReadCloser) {
+						err := Body.Close()
+						if err != nil {
+							klog.Errorf("failed to close response body, reason: %s", err)
+						}
+					}(res.Body)
+
+					if res.IsError() {
+						return dberrors.HTTPStatusError(res.StatusCode, "health check failed with status code: %d", res.StatusCode)
+					}
+					return nil
+				})
+			})
 			if err != nil {
 				return nil, err
 			}
-			defer func(Body io.ReadCloser) {
-				err := Body.Close()
-				if err != nil {
-					klog.Errorf("failed to close response body, reason: %s", err)
-				}
-			}(res.Body)
-
-			if res.IsError() {
-				return nil, fmt.Errorf("health check failed with status code: %d", res.StatusCode)
-			}
 			return &Client{
-				&ESClientV5{client: esClient},
+				ESClient:  &ESClientV5{client: esClient},
+				telemetry: o.telemetry,
 			}, nil
 
 		// for Elasticsearch 6.x.x
@@ -189,9 +297,7 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				DisableRetry:      true,
 				Transport: &http.Transport{
 					IdleConnTimeout: 3 * time.Second,
-					DialContext: (&net.Dialer{
-						Timeout: 30 * time.Second,
-					}).DialContext,
+					DialContext:     dialContext,
 					TLSClientConfig: defaultTLSConfig,
 				},
 			})
@@ -199,23 +305,32 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				klog.Errorf("Failed to create HTTP client for Elasticsearch: %s/%s with: %s", o.db.Namespace, o.db.Name, err)
 				return nil, err
 			}
-			res, err := esapi.PingRequest{}.Do(o.ctx, esClient.Transport)
+			err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+				return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+					res, err := esapi.PingRequest{}.Do(ctx, esClient.Transport)
+					if err != nil {
+						return dberrors.Classify(err)
+					}
+
+					defer func(Body io.ReadCloser) {
+						err = Body.Close()
+						if err != nil {
+							klog.Errorf("failed to close response body, reason: %s", err)
+						}
+					}(res.Body)
+
+					if res.IsError() {
+						return dberrors.HTTPStatusError(res.StatusCode, "cluster ping request failed with status code: %d", res.StatusCode)
+					}
+					return nil
+				})
+			})
 			if err != nil {
 				return nil, err
 			}
-
-			defer func(Body io.ReadCloser) {
-				err = Body.Close()
-				if err != nil {
-					klog.Errorf("failed to close response body, reason: %s", err)
-				}
-			}(res.Body)
-
-			if res.IsError() {
-				return nil, fmt.Errorf("cluster ping request failed with status code: %d", res.StatusCode)
-			}
 			return &Client{
-				&ESClientV6{client: esClient},
+				ESClient:  &ESClientV6{client: esClient},
+				telemetry: o.telemetry,
 			}, nil
 
 		// for Elasticsearch 7.x.x
@@ -236,9 +351,7 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				DisableRetry:      true,
 				Transport: &http.Transport{
 					IdleConnTimeout: 3 * time.Second,
-					DialContext: (&net.Dialer{
-						Timeout: 30 * time.Second,
-					}).DialContext,
+					DialContext:     dialContext,
 					TLSClientConfig: defaultTLSConfig,
 				},
 			})
@@ -247,23 +360,36 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				return nil, err
 			}
 
-			res, err := esapi.PingRequest{}.Do(o.ctx, esClient.Transport)
+			err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+				return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+					res, err := esapi.PingRequest{}.Do(ctx, esClient.Transport)
+					if err != nil {
+						return dberrors.Classify(err)
+					}
+
+					defer func(Body io.ReadCloser) {
+						err = Body.Close()
+						if err != nil {
+							klog.Errorf("failed to close response body, reason: %s", err)
+						}
+					}(res.Body)
+
+					if res.IsError() {
+						return dberrors.HTTPStatusError(res.StatusCode, "cluster ping request failed with status code: %d", res.StatusCode)
+					}
+					return nil
+				})
+			})
 			if err != nil {
 				return nil, err
 			}
-
-			defer func(Body io.ReadCloser) {
-				err = Body.Close()
-				if err != nil {
-					klog.Errorf("failed to close response body, reason: %s", err)
-				}
-			}(res.Body)
-
-			if res.IsError() {
-				return nil, fmt.Errorf("cluster ping request failed with status code: %d", res.StatusCode)
+			esClientV7 := &ESClientV7{client: esClient}
+			if authPlugin == catalog.ElasticsearchAuthPluginOpenDistro {
+				esClientV7.security = newSecurityPlugin(esClient)
 			}
 			return &Client{
-				&ESClientV7{client: esClient},
+				ESClient:  esClientV7,
+				telemetry: o.telemetry,
 			}, nil
 
 			// for Elasticsearch 8.x.x
@@ -285,9 +411,7 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				DisableRetry:      true,
 				Transport: &http.Transport{
 					IdleConnTimeout: 3 * time.Second,
-					DialContext: (&net.Dialer{
-						Timeout: 30 * time.Second,
-					}).DialContext,
+					DialContext:     dialContext,
 					TLSClientConfig: defaultTLSConfig,
 				},
 			})
@@ -296,28 +420,37 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				return nil, err
 			}
 
-			res, err := esapi.PingRequest{}.Do(o.ctx, esClient.Transport)
+			err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+				return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+					res, err := esapi.PingRequest{}.Do(ctx, esClient.Transport)
+					if err != nil {
+						return dberrors.Classify(err)
+					}
+
+					defer func(Body io.ReadCloser) {
+						err = Body.Close()
+						if err != nil {
+							klog.Errorf("failed to close response body, reason: %s", err)
+						}
+					}(res.Body)
+
+					if res.IsError() {
+						return dberrors.HTTPStatusError(res.StatusCode, "cluster ping request failed with status code: %d", res.StatusCode)
+					}
+					return nil
+				})
+			})
 			if err != nil {
 				return nil, err
 			}
 
-			defer func(Body io.ReadCloser) {
-				err = Body.Close()
-				if err != nil {
-					klog.Errorf("failed to close response body, reason: %s", err)
-				}
-			}(res.Body)
-
-			if res.IsError() {
-				return nil, fmt.Errorf("cluster ping request failed with status code: %d", res.StatusCode)
-			}
-
 			return &Client{
-				&ESClientV8{client: esClient},
+				ESClient:  &ESClientV8{client: esClient},
+				telemetry: o.telemetry,
 			}, nil
 		}
 
-	case esVersion.Spec.AuthPlugin == catalog.ElasticsearchAuthPluginOpenSearch:
+	case authPlugin == catalog.ElasticsearchAuthPluginOpenSearch:
 		switch {
 		case version.Major() == 1:
 			defaultTLSConfig, err := o.getDefaultTLSConfig()
@@ -335,9 +468,7 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				DisableRetry:      true,
 				Transport: &http.Transport{
 					IdleConnTimeout: 3 * time.Second,
-					DialContext: (&net.Dialer{
-						Timeout: 30 * time.Second,
-					}).DialContext,
+					DialContext:     dialContext,
 					TLSClientConfig: defaultTLSConfig,
 				},
 			})
@@ -346,23 +477,32 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				return nil, err
 			}
 
-			res, err := osapiv1.PingRequest{}.Do(o.ctx, osClient.Transport)
+			err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+				return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+					res, err := osapiv1.PingRequest{}.Do(ctx, osClient.Transport)
+					if err != nil {
+						return err
+					}
+
+					defer func(Body io.ReadCloser) {
+						err = Body.Close()
+						if err != nil {
+							klog.Errorf("failed to close response body, reason: %s", err)
+						}
+					}(res.Body)
+
+					if res.IsError() {
+						return dberrors.HTTPStatusError(res.StatusCode, "cluster ping request failed with status code: %d", res.StatusCode)
+					}
+					return nil
+				})
+			})
 			if err != nil {
 				return nil, err
 			}
-
-			defer func(Body io.ReadCloser) {
-				err = Body.Close()
-				if err != nil {
-					klog.Errorf("failed to close response body, reason: %s", err)
-				}
-			}(res.Body)
-
-			if res.IsError() {
-				return nil, fmt.Errorf("cluster ping request failed with status code: %d", res.StatusCode)
-			}
 			return &Client{
-				&OSClientV1{client: osClient},
+				ESClient:  &OSClientV1{client: osClient, security: newSecurityPlugin(osClient)},
+				telemetry: o.telemetry,
 			}, nil
 		case version.Major() == 2:
 			defaultTLSConfig, err := o.getDefaultTLSConfig()
@@ -380,9 +520,7 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				DisableRetry:      true,
 				Transport: &http.Transport{
 					IdleConnTimeout: 3 * time.Second,
-					DialContext: (&net.Dialer{
-						Timeout: 30 * time.Second,
-					}).DialContext,
+					DialContext:     dialContext,
 					TLSClientConfig: defaultTLSConfig,
 				},
 			})
@@ -391,28 +529,112 @@ func (o *KubeDBClientBuilder) GetElasticClient() (*Client, error) {
 				return nil, err
 			}
 
-			res, err := osapiv2.PingRequest{}.Do(o.ctx, osClient.Transport)
+			err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+				return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+					res, err := osapiv2.PingRequest{}.Do(ctx, osClient.Transport)
+					if err != nil {
+						return err
+					}
+
+					defer func(Body io.ReadCloser) {
+						err = Body.Close()
+						if err != nil {
+							klog.Errorf("failed to close response body, reason: %s", err)
+						}
+					}(res.Body)
+
+					if res.IsError() {
+						return dberrors.HTTPStatusError(res.StatusCode, "cluster ping request failed with status code: %d", res.StatusCode)
+					}
+					return nil
+				})
+			})
 			if err != nil {
 				return nil, err
 			}
-
-			defer func(Body io.ReadCloser) {
-				err = Body.Close()
-				if err != nil {
-					klog.Errorf("failed to close response body, reason: %s", err)
-				}
-			}(res.Body)
-
-			if res.IsError() {
-				return nil, fmt.Errorf("cluster ping request failed with status code: %d", res.StatusCode)
-			}
 			return &Client{
-				&OSClientV2{client: osClient},
+				ESClient:  &OSClientV2{client: osClient, security: newSecurityPlugin(osClient)},
+				telemetry: o.telemetry,
 			}, nil
 		}
 	}
 
-	return nil, fmt.Errorf("unknown database version: %s", o.db.Spec.Version)
+	klog.V(3).Infof("No versioned client for %s version %s, using the generic client", authPlugin, version)
+	return o.getGenericClient(authPlugin, dialContext, username, password)
+}
+
+func (o *KubeDBClientBuilder) newRestyClient(dialContext portforward.DialFunc, username, password string) (*resty.Client, error) {
+	defaultTLSConfig, err := o.getDefaultTLSConfig()
+	if err != nil {
+		klog.Errorf("Failed get default TLS configuration")
+		return nil, err
+	}
+
+	restyClient := resty.New().
+		SetTransport(&http.Transport{
+			IdleConnTimeout: 3 * time.Second,
+			DialContext:     dialContext,
+			TLSClientConfig: defaultTLSConfig,
+		}).
+		SetBaseURL(o.url).
+		SetHeader("Accept", "application/json").
+		SetTimeout(30 * time.Second)
+	if username != "" {
+		restyClient.SetBasicAuth(username, password)
+	}
+	return restyClient, nil
+}
+
+// detectServer gets the distribution and the version of the database from
+// its root endpoint, and its auth plugin from the installed plugins.
+func (o *KubeDBClientBuilder) detectServer(dialContext portforward.DialFunc, username, password string) (*ServerInfo, catalog.ElasticsearchAuthPlugin, error) {
+	restyClient, err := o.newRestyClient(dialContext, username, password)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var info *ServerInfo
+	var authPlugin catalog.ElasticsearchAuthPlugin
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			var err error
+			info, err = getServerInfo(ctx, restyPerformer{client: restyClient})
+			if err != nil {
+				return dberrors.Classify(err)
+			}
+			authPlugin, err = detectAuthPlugin(ctx, restyPerformer{client: restyClient}, info)
+			return dberrors.Classify(err)
+		})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return info, authPlugin, nil
+}
+
+// getGenericClient returns a client built on plain REST calls. The auth
+// plugin is detected along with the version when it is empty.
+func (o *KubeDBClientBuilder) getGenericClient(authPlugin catalog.ElasticsearchAuthPlugin, dialContext portforward.DialFunc, username, password string) (*Client, error) {
+	restyClient, err := o.newRestyClient(dialContext, username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	var esClient *GenericClient
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			var err error
+			esClient, err = newGenericClient(ctx, restyClient, authPlugin)
+			return dberrors.Classify(err)
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		ESClient:  esClient,
+		telemetry: o.telemetry,
+	}, nil
 }
 
 type Config struct {
@@ -422,6 +644,8 @@ type Config struct {
 }
 
 func (o *KubeDBClientBuilder) GetElasticRestyClient() (*ESRestyClient, error) {
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindElasticsearch, o.db.Namespace, o.db.Name, o.podName)...)
 	config := Config{
 		host: o.ServiceURL(),
 		api:  "/_cluster/health?pretty",
@@ -432,27 +656,36 @@ func (o *KubeDBClientBuilder) GetElasticRestyClient() (*ESRestyClient, error) {
 			}).DialContext,
 		},
 	}
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		config.transport.DialContext = dial
+	}
 
 	var authSecret core.Secret
 	var username, password string
 	if !o.db.Spec.DisableSecurity && o.db.Spec.AuthSecret != nil {
-		err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.AuthSecret.Name}, &authSecret)
+		err = o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+			return o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.AuthSecret.Name}, &authSecret)
+		})
 		if err != nil {
-			return nil, errors.Errorf("Failed to get auth secret with %s", err)
+			return nil, errors.Wrap(dberrors.SecretGetError(err), "failed to get auth secret")
 		}
 
 		if value, ok := authSecret.Data[core.BasicAuthUsernameKey]; ok {
 			username = string(value)
 		} else {
 			klog.Errorf("Failed for secret: %s/%s, username is missing", authSecret.Namespace, authSecret.Name)
-			return nil, errors.New("username is missing")
+			return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "username is missing")
 		}
 
 		if value, ok := authSecret.Data[core.BasicAuthPasswordKey]; ok {
 			password = string(value)
 		} else {
 			klog.Errorf("Failed for secret: %s/%s, password is missing", authSecret.Namespace, authSecret.Name)
-			return nil, errors.New("password is missing")
+			return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "password is missing")
 		}
 	}
 
@@ -501,16 +734,19 @@ func (client *ESRestyClient) Ping() (string, error) {
 	return "", errors.New("status is missing")
 }
 
-func (o *KubeDBClientBuilder) getDefaultTLSConfig() (*tls.Config, error) {
+func (o *KubeDBClientBuilder) getDefaultTLSConfig() (_ *tls.Config, err error) {
 	var crt tls.Certificate
 	var clientCA, rootCA *x509.CertPool
 
 	if o.db.Spec.EnableSSL {
+		ctx, end := o.telemetry.Start(o.ctx, telemetry.OpTLSLoad)
+		defer func() { end(err) }()
+
 		var certSecret core.Secret
-		err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.GetCertSecretName(dbapi.ElasticsearchClientCert)}, &certSecret)
+		err = o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.GetCertSecretName(dbapi.ElasticsearchClientCert)}, &certSecret)
 		if err != nil {
 			klog.Errorf("Failed to get client-cert for tls configurations")
-			return nil, err
+			return nil, dberrors.SecretGetError(err)
 		}
 
 		crt, err = tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
@@ -540,5 +776,5 @@ func (o *KubeDBClientBuilder) getDefaultTLSConfig() (*tls.Config, error) {
 }
 
 func (o *KubeDBClientBuilder) ServiceURL() string {
-	return fmt.Sprintf("%v://%s.%s.svc:%d", o.db.GetConnectionScheme(), o.db.ServiceName(), o.db.GetNamespace(), kubedb.ElasticsearchRestPort)
+	return o.resolver.ServiceURL(o.db.GetConnectionScheme(), dbapi.ResourceKindElasticsearch, o.db.ServiceName(), o.db.GetNamespace(), kubedb.ElasticsearchRestPort)
 }
@@ -0,0 +1,506 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ilmPolicySetting        = "index.lifecycle.name"
+	ilmRolloverAliasSetting = "index.lifecycle.rollover_alias"
+	ismRolloverAliasSetting = "plugins.index_state_management.rollover_alias"
+
+	ismPath             = "/_plugins/_ism"
+	ismTemplatePriority = 100
+)
+
+// LifecyclePolicy is a retention policy that is translated to an index
+// lifecycle management (ILM) policy on Elasticsearch and to an index state
+// management (ISM) policy on OpenSearch. Indices move from the hot phase to
+// the warm phase after WarmAfter and are deleted after DeleteAfter. The ages
+// are relative to the rollover of an index with ILM and to its creation with
+// ISM.
+type LifecyclePolicy struct {
+	Description string
+	// Rollover rolls the write index of an alias over in the hot phase when
+	// any of the conditions is met.
+	Rollover *RolloverConditions
+	// WarmAfter is the age of the indices to enter the warm phase, e.g. "7d".
+	// The warm phase is skipped when it is empty.
+	WarmAfter string
+	// ReadOnly, ForceMergeSegments and Replicas are the actions of the warm
+	// phase.
+	ReadOnly           bool
+	ForceMergeSegments int
+	Replicas           *int
+	// DeleteAfter is the age of the indices to be deleted, e.g. "30d". The
+	// indices are kept when it is empty.
+	DeleteAfter string
+}
+
+// RolloverConditions are the conditions to roll an alias over to a new index.
+type RolloverConditions struct {
+	MaxAge              string `json:"max_age,omitempty"`
+	MaxSize             string `json:"max_size,omitempty"`
+	MaxPrimaryShardSize string `json:"max_primary_shard_size,omitempty"`
+	MaxDocs             int64  `json:"max_docs,omitempty"`
+}
+
+// LifecyclePolicyInfo is a lifecycle policy as stored by the database.
+type LifecyclePolicyInfo struct {
+	Name string
+	// Version is the version of an ILM policy or of an ISM policy document.
+	Version int64
+	// Policy is the ILM or ISM policy definition.
+	Policy json.RawMessage
+}
+
+// LifecycleExplanation is the lifecycle state of an index. Phase is the ILM
+// phase or the ISM state of the index.
+type LifecycleExplanation struct {
+	Index   string
+	Managed bool
+	Policy  string
+	Phase   string
+	Action  string
+	Step    string
+	Failed  bool
+	// Info is the failure or progress message of the current step.
+	Info string
+}
+
+func (p *LifecyclePolicy) ilmPolicy() map[string]interface{} {
+	phases := map[string]interface{}{}
+	hot := map[string]interface{}{}
+	if p.Rollover != nil {
+		rollover := map[string]interface{}{}
+		setIfNotEmpty(rollover, "max_age", p.Rollover.MaxAge)
+		setIfNotEmpty(rollover, "max_size", p.Rollover.MaxSize)
+		setIfNotEmpty(rollover, "max_primary_shard_size", p.Rollover.MaxPrimaryShardSize)
+		if p.Rollover.MaxDocs > 0 {
+			rollover["max_docs"] = p.Rollover.MaxDocs
+		}
+		hot["rollover"] = rollover
+	}
+	phases["hot"] = map[string]interface{}{
+		"min_age": "0ms",
+		"actions": hot,
+	}
+
+	if p.WarmAfter != "" {
+		warm := map[string]interface{}{}
+		if p.ReadOnly {
+			warm["readonly"] = map[string]interface{}{}
+		}
+		if p.ForceMergeSegments > 0 {
+			warm["forcemerge"] = map[string]interface{}{"max_num_segments": p.ForceMergeSegments}
+		}
+		if p.Replicas != nil {
+			warm["allocate"] = map[string]interface{}{"number_of_replicas": *p.Replicas}
+		}
+		phases["warm"] = map[string]interface{}{
+			"min_age": p.WarmAfter,
+			"actions": warm,
+		}
+	}
+
+	if p.DeleteAfter != "" {
+		phases["delete"] = map[string]interface{}{
+			"min_age": p.DeleteAfter,
+			"actions": map[string]interface{}{"delete": map[string]interface{}{}},
+		}
+	}
+
+	policy := map[string]interface{}{"phases": phases}
+	if p.Description != "" {
+		policy["_meta"] = map[string]interface{}{"description": p.Description}
+	}
+	return map[string]interface{}{"policy": policy}
+}
+
+func (p *LifecyclePolicy) ismPolicy(ismTemplate interface{}) map[string]interface{} {
+	type state struct {
+		name    string
+		actions []interface{}
+	}
+
+	hot := state{name: "hot", actions: []interface{}{}}
+	if p.Rollover != nil {
+		rollover := map[string]interface{}{}
+		setIfNotEmpty(rollover, "min_index_age", p.Rollover.MaxAge)
+		setIfNotEmpty(rollover, "min_size", p.Rollover.MaxSize)
+		setIfNotEmpty(rollover, "min_primary_shard_size", p.Rollover.MaxPrimaryShardSize)
+		if p.Rollover.MaxDocs > 0 {
+			rollover["min_doc_count"] = p.Rollover.MaxDocs
+		}
+		hot.actions = append(hot.actions, map[string]interface{}{"rollover": rollover})
+	}
+	states := []state{hot}
+	ages := []string{""}
+
+	if p.WarmAfter != "" {
+		warm := state{name: "warm", actions: []interface{}{}}
+		if p.ReadOnly {
+			warm.actions = append(warm.actions, map[string]interface{}{"read_only": map[string]interface{}{}})
+		}
+		if p.ForceMergeSegments > 0 {
+			warm.actions = append(warm.actions, map[string]interface{}{
+				"force_merge": map[string]interface{}{"max_num_segments": p.ForceMergeSegments},
+			})
+		}
+		if p.Replicas != nil {
+			warm.actions = append(warm.actions, map[string]interface{}{
+				"replica_count": map[string]interface{}{"number_of_replicas": *p.Replicas},
+			})
+		}
+		states = append(states, warm)
+		ages = append(ages, p.WarmAfter)
+	}
+
+	if p.DeleteAfter != "" {
+		states = append(states, state{
+			name:    "delete",
+			actions: []interface{}{map[string]interface{}{"delete": map[string]interface{}{}}},
+		})
+		ages = append(ages, p.DeleteAfter)
+	}
+
+	// every state transitions to the next one when the index is old enough
+	ismStates := make([]interface{}, 0, len(states))
+	for i, s := range states {
+		transitions := []interface{}{}
+		if i+1 < len(states) {
+			transitions = append(transitions, map[string]interface{}{
+				"state_name": states[i+1].name,
+				"conditions": map[string]interface{}{"min_index_age": ages[i+1]},
+			})
+		}
+		ismStates = append(ismStates, map[string]interface{}{
+			"name":        s.name,
+			"actions":     s.actions,
+			"transitions": transitions,
+		})
+	}
+
+	policy := map[string]interface{}{
+		"description":   p.Description,
+		"default_state": hot.name,
+		"states":        ismStates,
+	}
+	if ismTemplate != nil {
+		policy["ism_template"] = ismTemplate
+	}
+	return map[string]interface{}{"policy": policy}
+}
+
+func setIfNotEmpty(m map[string]interface{}, key, value string) {
+	if value != "" {
+		m[key] = value
+	}
+}
+
+type ilmPolicyResponse map[string]struct {
+	Version int64           `json:"version"`
+	Policy  json.RawMessage `json:"policy"`
+}
+
+func (r ilmPolicyResponse) info(name string) (*LifecyclePolicyInfo, error) {
+	policy, ok := r[name]
+	if !ok {
+		return nil, errors.Errorf("lifecycle policy %s is missing in the response", name)
+	}
+	return &LifecyclePolicyInfo{
+		Name:    name,
+		Version: policy.Version,
+		Policy:  policy.Policy,
+	}, nil
+}
+
+type ilmExplainResponse struct {
+	Indices map[string]struct {
+		Index      string `json:"index"`
+		Managed    bool   `json:"managed"`
+		Policy     string `json:"policy"`
+		Phase      string `json:"phase"`
+		Action     string `json:"action"`
+		Step       string `json:"step"`
+		FailedStep string `json:"failed_step"`
+		StepInfo   struct {
+			Type    string `json:"type"`
+			Reason  string `json:"reason"`
+			Message string `json:"message"`
+		} `json:"step_info"`
+	} `json:"indices"`
+}
+
+func (r *ilmExplainResponse) explanations() []LifecycleExplanation {
+	result := make([]LifecycleExplanation, 0, len(r.Indices))
+	for name, index := range r.Indices {
+		info := index.StepInfo.Message
+		if index.StepInfo.Reason != "" {
+			info = fmt.Sprintf("%s: %s", index.StepInfo.Type, index.StepInfo.Reason)
+		}
+		result = append(result, LifecycleExplanation{
+			Index:   name,
+			Managed: index.Managed,
+			Policy:  index.Policy,
+			Phase:   index.Phase,
+			Action:  index.Action,
+			Step:    index.Step,
+			Failed:  index.Step == "ERROR" || index.FailedStep != "",
+			Info:    info,
+		})
+	}
+	sortExplanations(result)
+	return result
+}
+
+func sortExplanations(explanations []LifecycleExplanation) {
+	sort.Slice(explanations, func(i, j int) bool {
+		return explanations[i].Index < explanations[j].Index
+	})
+}
+
+// indexTemplate is an entry of the response of the get index template API.
+type indexTemplate struct {
+	Name          string                 `json:"name"`
+	IndexTemplate map[string]interface{} `json:"index_template"`
+}
+
+type indexTemplatesResponse struct {
+	IndexTemplates []indexTemplate `json:"index_templates"`
+}
+
+func (r *indexTemplatesResponse) template(name string) (map[string]interface{}, error) {
+	for _, t := range r.IndexTemplates {
+		if t.Name == name {
+			return t.IndexTemplate, nil
+		}
+	}
+	return nil, errors.Errorf("index template %s is missing in the response", name)
+}
+
+// setTemplateSettings sets index settings in the template section of a
+// composable index template.
+func setTemplateSettings(indexTemplate map[string]interface{}, settings map[string]string) {
+	template, _ := indexTemplate["template"].(map[string]interface{})
+	if template == nil {
+		template = map[string]interface{}{}
+		indexTemplate["template"] = template
+	}
+	s, _ := template["settings"].(map[string]interface{})
+	if s == nil {
+		s = map[string]interface{}{}
+		template["settings"] = s
+	}
+	for k, v := range settings {
+		s[k] = v
+	}
+}
+
+func ilmTemplateSettings(policy, rolloverAlias string) map[string]string {
+	settings := map[string]string{ilmPolicySetting: policy}
+	if rolloverAlias != "" {
+		settings[ilmRolloverAliasSetting] = rolloverAlias
+	}
+	return settings
+}
+
+// The ISM API is missing in the OpenSearch API packages, so it is called
+// through the transport of the clients.
+
+type ismPolicyResponse struct {
+	ID          string                 `json:"_id"`
+	Version     int64                  `json:"_version"`
+	SeqNo       *int64                 `json:"_seq_no"`
+	PrimaryTerm *int64                 `json:"_primary_term"`
+	Policy      map[string]interface{} `json:"policy"`
+}
+
+func getISMPolicy(ctx context.Context, client performer, name string) (*ismPolicyResponse, error) {
+	var response ismPolicyResponse
+	err := performJSON(ctx, client, http.MethodGet, ismPath+"/policies/"+url.PathEscape(name), nil, &response, "get ISM policy "+name)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// putISMPolicy creates or updates an ISM policy. An existing policy is
+// updated with optimistic concurrency control, keeping its ISM template.
+func putISMPolicy(ctx context.Context, client performer, name string, policy LifecyclePolicy) error {
+	path := ismPath + "/policies/" + url.PathEscape(name)
+	existing, err := getISMPolicy(ctx, client, name)
+	if err != nil && !isNotFound(err) {
+		return err
+	}
+	var ismTemplate interface{}
+	if existing != nil {
+		ismTemplate = existing.Policy["ism_template"]
+		path += ifSeqNoQuery(existing)
+	}
+	return performJSON(ctx, client, http.MethodPut, path, policy.ismPolicy(ismTemplate), nil, "put ISM policy "+name)
+}
+
+func ifSeqNoQuery(policy *ismPolicyResponse) string {
+	if policy.SeqNo == nil || policy.PrimaryTerm == nil {
+		return ""
+	}
+	return fmt.Sprintf("?if_seq_no=%d&if_primary_term=%d", *policy.SeqNo, *policy.PrimaryTerm)
+}
+
+func getISMPolicyInfo(ctx context.Context, client performer, name string) (*LifecyclePolicyInfo, error) {
+	policy, err := getISMPolicy(ctx, client, name)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := json.Marshal(policy.Policy)
+	if err != nil {
+		return nil, err
+	}
+	return &LifecyclePolicyInfo{
+		Name:    name,
+		Version: policy.Version,
+		Policy:  raw,
+	}, nil
+}
+
+func deleteISMPolicy(ctx context.Context, client performer, name string) error {
+	return performJSON(ctx, client, http.MethodDelete, ismPath+"/policies/"+url.PathEscape(name), nil, nil, "delete ISM policy "+name)
+}
+
+// attachISMPolicy adds the index patterns of a composable index template to
+// the ISM template of a policy, so that the policy is applied to the indices
+// created from the template. The rollover alias is set in the template.
+func attachISMPolicy(ctx context.Context, client performer, templateName, policyName, rolloverAlias string) error {
+	var templates indexTemplatesResponse
+	templatePath := "/_index_template/" + url.PathEscape(templateName)
+	err := performJSON(ctx, client, http.MethodGet, templatePath, nil, &templates, "get index template "+templateName)
+	if err != nil {
+		return err
+	}
+	indexTemplate, err := templates.template(templateName)
+	if err != nil {
+		return err
+	}
+
+	policy, err := getISMPolicy(ctx, client, policyName)
+	if err != nil {
+		return err
+	}
+	ismTemplates := []interface{}{}
+	switch t := policy.Policy["ism_template"].(type) {
+	case []interface{}:
+		ismTemplates = t
+	case map[string]interface{}:
+		ismTemplates = append(ismTemplates, t)
+	}
+	ismTemplates = append(ismTemplates, map[string]interface{}{
+		"index_patterns": indexTemplate["index_patterns"],
+		"priority":       ismTemplatePriority,
+	})
+	policy.Policy["ism_template"] = ismTemplates
+	// the response contains read-only fields that are rejected on update
+	for _, field := range []string{"policy_id", "last_updated_time", "schema_version", "error_notification"} {
+		delete(policy.Policy, field)
+	}
+	err = performJSON(ctx, client, http.MethodPut, ismPath+"/policies/"+url.PathEscape(policyName)+ifSeqNoQuery(policy),
+		map[string]interface{}{"policy": policy.Policy}, nil, "attach ISM policy "+policyName)
+	if err != nil {
+		return err
+	}
+
+	if rolloverAlias == "" {
+		return nil
+	}
+	setTemplateSettings(indexTemplate, map[string]string{ismRolloverAliasSetting: rolloverAlias})
+	return performJSON(ctx, client, http.MethodPut, templatePath, indexTemplate, nil, "put index template "+templateName)
+}
+
+func explainISM(ctx context.Context, client performer, index string) ([]LifecycleExplanation, error) {
+	var response map[string]json.RawMessage
+	err := performJSON(ctx, client, http.MethodGet, ismPath+"/explain/"+url.PathEscape(index), nil, &response, "explain ISM of "+index)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]LifecycleExplanation, 0, len(response))
+	for name, raw := range response {
+		if name == "total_managed_indices" {
+			continue
+		}
+		var explanation struct {
+			PolicyID string `json:"policy_id"`
+			State    struct {
+				Name string `json:"name"`
+			} `json:"state"`
+			Action struct {
+				Name   string `json:"name"`
+				Failed bool   `json:"failed"`
+			} `json:"action"`
+			Step struct {
+				Name       string `json:"name"`
+				StepStatus string `json:"step_status"`
+			} `json:"step"`
+			Info struct {
+				Message string `json:"message"`
+			} `json:"info"`
+		}
+		if err := json.Unmarshal(raw, &explanation); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode ISM explanation of index %s", name)
+		}
+		result = append(result, LifecycleExplanation{
+			Index:   name,
+			Managed: explanation.PolicyID != "",
+			Policy:  explanation.PolicyID,
+			Phase:   explanation.State.Name,
+			Action:  explanation.Action.Name,
+			Step:    explanation.Step.Name,
+			Failed:  explanation.Action.Failed || explanation.Step.StepStatus == "failed",
+			Info:    explanation.Info.Message,
+		})
+	}
+	sortExplanations(result)
+	return result, nil
+}
+
+func retryISM(ctx context.Context, client performer, index string) error {
+	var response struct {
+		Failures      bool `json:"failures"`
+		FailedIndices []struct {
+			IndexName string `json:"index_name"`
+			Reason    string `json:"reason"`
+		} `json:"failed_indices"`
+	}
+	err := performJSON(ctx, client, http.MethodPost, ismPath+"/retry/"+url.PathEscape(index), nil, &response, "retry ISM of "+index)
+	if err != nil {
+		return err
+	}
+	if response.Failures && len(response.FailedIndices) > 0 {
+		f := response.FailedIndices[0]
+		return errors.Errorf("failed to retry ISM of index %s: %s", f.IndexName, f.Reason)
+	}
+	return nil
+}
@@ -0,0 +1,187 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"encoding/json"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	ClusterHealthGreen  = "green"
+	ClusterHealthYellow = "yellow"
+	ClusterHealthRed    = "red"
+)
+
+// ClusterHealth is the response of the cluster health API.
+type ClusterHealth struct {
+	ClusterName                 string  `json:"cluster_name"`
+	Status                      string  `json:"status"`
+	TimedOut                    bool    `json:"timed_out"`
+	NumberOfNodes               int     `json:"number_of_nodes"`
+	NumberOfDataNodes           int     `json:"number_of_data_nodes"`
+	ActivePrimaryShards         int     `json:"active_primary_shards"`
+	ActiveShards                int     `json:"active_shards"`
+	RelocatingShards            int     `json:"relocating_shards"`
+	InitializingShards          int     `json:"initializing_shards"`
+	UnassignedShards            int     `json:"unassigned_shards"`
+	DelayedUnassignedShards     int     `json:"delayed_unassigned_shards"`
+	NumberOfPendingTasks        int     `json:"number_of_pending_tasks"`
+	NumberOfInFlightFetch       int     `json:"number_of_in_flight_fetch"`
+	TaskMaxWaitingInQueueMillis int64   `json:"task_max_waiting_in_queue_millis"`
+	ActiveShardsPercentAsNumber float64 `json:"active_shards_percent_as_number"`
+}
+
+// NodesStats is the response of the nodes stats API, keyed by node ID.
+type NodesStats struct {
+	ClusterName string               `json:"cluster_name"`
+	Nodes       map[string]NodeStats `json:"nodes"`
+}
+
+type NodeStats struct {
+	Name             string                     `json:"name"`
+	TransportAddress string                     `json:"transport_address"`
+	Host             string                     `json:"host"`
+	Roles            []string                   `json:"roles"`
+	JVM              NodeJVMStats               `json:"jvm"`
+	FS               NodeFSStats                `json:"fs"`
+	ThreadPool       map[string]ThreadPoolStats `json:"thread_pool"`
+	Breakers         map[string]BreakerStats    `json:"breakers"`
+}
+
+type NodeJVMStats struct {
+	UptimeInMillis int64           `json:"uptime_in_millis"`
+	Mem            NodeJVMMemStats `json:"mem"`
+}
+
+type NodeJVMMemStats struct {
+	HeapUsedInBytes         int64 `json:"heap_used_in_bytes"`
+	HeapUsedPercent         int   `json:"heap_used_percent"`
+	HeapCommittedInBytes    int64 `json:"heap_committed_in_bytes"`
+	HeapMaxInBytes          int64 `json:"heap_max_in_bytes"`
+	NonHeapUsedInBytes      int64 `json:"non_heap_used_in_bytes"`
+	NonHeapCommittedInBytes int64 `json:"non_heap_committed_in_bytes"`
+}
+
+type NodeFSStats struct {
+	Timestamp int64         `json:"timestamp"`
+	Total     FSUsage       `json:"total"`
+	Data      []FSPathUsage `json:"data"`
+}
+
+type FSUsage struct {
+	TotalInBytes     int64 `json:"total_in_bytes"`
+	FreeInBytes      int64 `json:"free_in_bytes"`
+	AvailableInBytes int64 `json:"available_in_bytes"`
+}
+
+type FSPathUsage struct {
+	Path  string `json:"path"`
+	Mount string `json:"mount"`
+	Type  string `json:"type"`
+	FSUsage
+}
+
+type ThreadPoolStats struct {
+	Threads   int   `json:"threads"`
+	Queue     int   `json:"queue"`
+	Active    int   `json:"active"`
+	Rejected  int64 `json:"rejected"`
+	Largest   int   `json:"largest"`
+	Completed int64 `json:"completed"`
+}
+
+type BreakerStats struct {
+	LimitSizeInBytes     int64   `json:"limit_size_in_bytes"`
+	EstimatedSizeInBytes int64   `json:"estimated_size_in_bytes"`
+	Overhead             float64 `json:"overhead"`
+	Tripped              int64   `json:"tripped"`
+}
+
+// IndexInfo is a row of the cat indices API. Closed indices have no stats.
+type IndexInfo struct {
+	Health              string `json:"health"`
+	Status              string `json:"status"`
+	Index               string `json:"index"`
+	UUID                string `json:"uuid"`
+	PrimaryShards       int    `json:"pri"`
+	Replicas            int    `json:"rep"`
+	DocsCount           int64  `json:"docs.count"`
+	DocsDeleted         int64  `json:"docs.deleted"`
+	StoreSizeInBytes    int64  `json:"store.size"`
+	PriStoreSizeInBytes int64  `json:"pri.store.size"`
+	DatasetSizeInBytes  int64  `json:"dataset.size,omitempty"`
+}
+
+// UnmarshalJSON parses a row of the cat indices API, where all the numbers
+// are strings.
+func (i *IndexInfo) UnmarshalJSON(data []byte) error {
+	var row map[string]*string
+	if err := json.Unmarshal(data, &row); err != nil {
+		return err
+	}
+	str := func(key string) string {
+		if v := row[key]; v != nil {
+			return *v
+		}
+		return ""
+	}
+	num := func(key string) (int64, error) {
+		v := str(key)
+		if v == "" {
+			return 0, nil
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return 0, errors.Wrapf(err, "failed to parse %s of index %s", key, str("index"))
+		}
+		return n, nil
+	}
+
+	*i = IndexInfo{
+		Health: str("health"),
+		Status: str("status"),
+		Index:  str("index"),
+		UUID:   str("uuid"),
+	}
+	for key, field := range map[string]*int64{
+		"docs.count":     &i.DocsCount,
+		"docs.deleted":   &i.DocsDeleted,
+		"store.size":     &i.StoreSizeInBytes,
+		"pri.store.size": &i.PriStoreSizeInBytes,
+		"dataset.size":   &i.DatasetSizeInBytes,
+	} {
+		n, err := num(key)
+		if err != nil {
+			return err
+		}
+		*field = n
+	}
+	for key, field := range map[string]*int{
+		"pri": &i.PrimaryShards,
+		"rep": &i.Replicas,
+	} {
+		n, err := num(key)
+		if err != nil {
+			return err
+		}
+		*field = int(n)
+	}
+	return nil
+}
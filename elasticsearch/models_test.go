@@ -0,0 +1,96 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestIndexInfoUnmarshalJSON(t *testing.T) {
+	data := `[
+		{
+			"health": "green",
+			"status": "open",
+			"index": "logs",
+			"uuid": "u1",
+			"pri": "3",
+			"rep": "1",
+			"docs.count": "1200",
+			"docs.deleted": "7",
+			"store.size": "104857600",
+			"pri.store.size": "52428800",
+			"dataset.size": "52428800"
+		},
+		{
+			"health": "red",
+			"status": "close",
+			"index": "archive",
+			"uuid": "u2",
+			"pri": "1",
+			"rep": "0",
+			"docs.count": null,
+			"docs.deleted": null,
+			"store.size": null,
+			"pri.store.size": null
+		}
+	]`
+	want := []IndexInfo{
+		{
+			Health:              "green",
+			Status:              "open",
+			Index:               "logs",
+			UUID:                "u1",
+			PrimaryShards:       3,
+			Replicas:            1,
+			DocsCount:           1200,
+			DocsDeleted:         7,
+			StoreSizeInBytes:    104857600,
+			PriStoreSizeInBytes: 52428800,
+			DatasetSizeInBytes:  52428800,
+		},
+		{
+			Health:        "red",
+			Status:        "close",
+			Index:         "archive",
+			UUID:          "u2",
+			PrimaryShards: 1,
+		},
+	}
+
+	var got []IndexInfo
+	if err := json.Unmarshal([]byte(data), &got); err != nil {
+		t.Fatalf("failed to unmarshal indices: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got indices %+v, want %+v", got, want)
+	}
+}
+
+func TestIndexInfoUnmarshalJSONInvalidNumber(t *testing.T) {
+	for _, data := range []string{
+		`{"index": "logs", "docs.count": "12kb"}`,
+		`{"index": "logs", "pri": "three"}`,
+		`["logs"]`,
+	} {
+		var info IndexInfo
+		if err := json.Unmarshal([]byte(data), &info); err == nil {
+			t.Errorf("expected an error for %s, got %+v", data, info)
+		}
+	}
+}
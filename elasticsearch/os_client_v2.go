@@ -35,10 +35,14 @@ import (
 	kutil "kmodules.xyz/client-go"
 )
 
-var _ ESClient = &OSClientV2{}
+var (
+	_ ESClient   = &OSClientV2{}
+	_ RoleMapper = &OSClientV2{}
+)
 
 type OSClientV2 struct {
-	client *osv2.Client
+	client   *osv2.Client
+	security *securityPlugin
 }
 
 func (os *OSClientV2) ClusterHealthInfo() (map[string]interface{}, error) {
@@ -83,7 +87,7 @@ func (es *OSClientV2) ShardStats() ([]ShardInfo, error) {
 		Format: "json",
 		Pretty: true,
 		Human:  true,
-		H:      []string{"index", "shard", "prirep", "state", "unassigned.reason"},
+		H:      []string{"index", "shard", "prirep", "state", "node", "unassigned.reason"},
 	}
 
 	resp, err := req.Do(context.Background(), es.client)
@@ -277,15 +281,20 @@ func (os *OSClientV2) GetTotalDiskUsage(ctx context.Context) (string, error) {
 }
 
 func (os *OSClientV2) SyncCredentialFromSecret(secret *core.Secret) error {
-	return nil
+	return os.security.syncCredentialFromSecret(context.Background(), secret)
 }
 
 func (os *OSClientV2) GetDBUserRole(ctx context.Context) (error, bool) {
-	return errors.New("not supported in os version 2"), false
+	return os.security.getDBUserRole(ctx)
 }
 
 func (os *OSClientV2) CreateDBUserRole(ctx context.Context) error {
-	return errors.New("not supported in os version 2")
+	return os.security.createDBUserRole(ctx)
+}
+
+// MapDBUserRole maps the custom role created by CreateDBUserRole to users.
+func (os *OSClientV2) MapDBUserRole(ctx context.Context, users ...string) error {
+	return os.security.mapDBUserRole(ctx, users...)
 }
 
 func (os *OSClientV2) IndexExistsOrNot(index string) error {
@@ -432,3 +441,716 @@ func (os *OSClientV2) PutData(index, id string, data map[string]interface{}) err
 	}
 	return nil
 }
+
+func (os *OSClientV2) CreateSnapshotRepository(ctx context.Context, name string, repository SnapshotRepository, verify bool) error {
+	body, err := jsonBody(repository)
+	if err != nil {
+		return err
+	}
+	res, err := osv2api.SnapshotCreateRepositoryRequest{
+		Repository: name,
+		Body:       body,
+		Verify:     &verify,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform create snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "create snapshot repository "+name)
+}
+
+func (os *OSClientV2) VerifySnapshotRepository(ctx context.Context, name string) ([]string, error) {
+	res, err := osv2api.SnapshotVerifyRepositoryRequest{
+		Repository: name,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform verify snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	var response verifyRepositoryResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "verify snapshot repository "+name); err != nil {
+		return nil, err
+	}
+	return response.nodeNames(), nil
+}
+
+func (os *OSClientV2) DeleteSnapshotRepository(ctx context.Context, name string) error {
+	res, err := osv2api.SnapshotDeleteRepositoryRequest{
+		Repository: []string{name},
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete snapshot repository request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete snapshot repository "+name)
+}
+
+func (os *OSClientV2) CreateSnapshot(ctx context.Context, repository, snapshot string, opts SnapshotOptions) (*SnapshotInfo, error) {
+	body, err := jsonBody(opts)
+	if err != nil {
+		return nil, err
+	}
+	res, err := osv2api.SnapshotCreateRequest{
+		Repository:        repository,
+		Snapshot:          snapshot,
+		Body:              body,
+		WaitForCompletion: &opts.WaitForCompletion,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform create snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotCreateResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "create snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.info(repository, snapshot), nil
+}
+
+func (os *OSClientV2) ListSnapshots(ctx context.Context, repository string) ([]SnapshotInfo, error) {
+	res, err := osv2api.SnapshotGetRequest{
+		Repository: repository,
+		Snapshot:   []string{"_all"},
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get snapshots request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotGetResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "list snapshots of repository "+repository); err != nil {
+		return nil, err
+	}
+	return response.Snapshots, nil
+}
+
+func (os *OSClientV2) GetSnapshotStatus(ctx context.Context, repository, snapshot string) (*SnapshotStatus, error) {
+	res, err := osv2api.SnapshotStatusRequest{
+		Repository: repository,
+		Snapshot:   []string{snapshot},
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform snapshot status request")
+	}
+	defer closeBody(res.Body)
+
+	var response snapshotStatusResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get status of snapshot "+snapshot); err != nil {
+		return nil, err
+	}
+	return response.status(snapshot)
+}
+
+func (os *OSClientV2) DeleteSnapshot(ctx context.Context, repository, snapshot string) error {
+	res, err := osv2api.SnapshotDeleteRequest{
+		Repository: repository,
+		Snapshot:   []string{snapshot},
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete snapshot "+snapshot)
+}
+
+func (os *OSClientV2) RestoreSnapshot(ctx context.Context, repository, snapshot string, opts RestoreOptions) error {
+	body, err := jsonBody(opts)
+	if err != nil {
+		return err
+	}
+	res, err := osv2api.SnapshotRestoreRequest{
+		Repository:        repository,
+		Snapshot:          snapshot,
+		Body:              body,
+		WaitForCompletion: &opts.WaitForCompletion,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform restore snapshot request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "restore snapshot "+snapshot)
+}
+
+func (os *OSClientV2) GetClusterHealth(ctx context.Context) (*ClusterHealth, error) {
+	res, err := osv2api.ClusterHealthRequest{}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cluster health request")
+	}
+	defer closeBody(res.Body)
+
+	var health ClusterHealth
+	if err := decodeResponse(res.StatusCode, res.Body, &health, "get cluster health"); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+func (os *OSClientV2) GetNodesStats(ctx context.Context) (*NodesStats, error) {
+	res, err := osv2api.NodesStatsRequest{
+		Metric: []string{"jvm", "fs", "thread_pool", "breaker"},
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform nodes stats request")
+	}
+	defer closeBody(res.Body)
+
+	var stats NodesStats
+	if err := decodeResponse(res.StatusCode, res.Body, &stats, "get nodes stats"); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+func (os *OSClientV2) GetIndices(ctx context.Context) ([]IndexInfo, error) {
+	res, err := osv2api.CatIndicesRequest{
+		Bytes:  "b",
+		Format: "json",
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cat indices request")
+	}
+	defer closeBody(res.Body)
+
+	var indices []IndexInfo
+	if err := decodeResponse(res.StatusCode, res.Body, &indices, "get indices"); err != nil {
+		return nil, err
+	}
+	return indices, nil
+}
+
+func (os *OSClientV2) PutLifecyclePolicy(ctx context.Context, name string, policy LifecyclePolicy) error {
+	return putISMPolicy(ctx, os.client, name, policy)
+}
+
+func (os *OSClientV2) GetLifecyclePolicy(ctx context.Context, name string) (*LifecyclePolicyInfo, error) {
+	return getISMPolicyInfo(ctx, os.client, name)
+}
+
+func (os *OSClientV2) DeleteLifecyclePolicy(ctx context.Context, name string) error {
+	return deleteISMPolicy(ctx, os.client, name)
+}
+
+// AttachLifecyclePolicy applies the ISM policy to the indices created from a
+// composable index template and sets the rollover alias in the template.
+func (os *OSClientV2) AttachLifecyclePolicy(ctx context.Context, template, policy, rolloverAlias string) error {
+	return attachISMPolicy(ctx, os.client, template, policy, rolloverAlias)
+}
+
+func (os *OSClientV2) ExplainLifecycle(ctx context.Context, index string) ([]LifecycleExplanation, error) {
+	return explainISM(ctx, os.client, index)
+}
+
+func (os *OSClientV2) RetryLifecycle(ctx context.Context, index string) error {
+	return retryISM(ctx, os.client, index)
+}
+
+func (os *OSClientV2) PutLegacyIndexTemplate(ctx context.Context, name string, template LegacyIndexTemplate) error {
+	body, err := jsonBody(template)
+	if err != nil {
+		return err
+	}
+	res, err := osv2api.IndicesPutTemplateRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put template "+name)
+}
+
+func (os *OSClientV2) GetLegacyIndexTemplate(ctx context.Context, name string) (*LegacyIndexTemplate, error) {
+	res, err := osv2api.IndicesGetTemplateRequest{
+		Name: []string{name},
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get template request")
+	}
+	defer closeBody(res.Body)
+
+	var response map[string]LegacyIndexTemplate
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get template "+name); err != nil {
+		return nil, err
+	}
+	return legacyTemplate(response, name)
+}
+
+func (os *OSClientV2) DeleteLegacyIndexTemplate(ctx context.Context, name string) error {
+	res, err := osv2api.IndicesDeleteTemplateRequest{
+		Name: name,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete template "+name)
+}
+
+func (os *OSClientV2) CreateIndexWithBody(ctx context.Context, index string, definition IndexBody) error {
+	body, err := jsonBody(definition)
+	if err != nil {
+		return err
+	}
+	res, err := osv2api.IndicesCreateRequest{
+		Index: index,
+		Body:  body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform create index request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "create index "+index)
+}
+
+func (os *OSClientV2) UpdateAliases(ctx context.Context, actions ...AliasAction) error {
+	body, err := jsonBody(map[string]interface{}{"actions": actions})
+	if err != nil {
+		return err
+	}
+	res, err := osv2api.IndicesUpdateAliasesRequest{
+		Body: body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform update aliases request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "update aliases")
+}
+
+func (os *OSClientV2) RolloverAlias(ctx context.Context, alias, newIndex string, conditions *RolloverConditions) (*RolloverResult, error) {
+	body, err := jsonBody(rolloverRequest{Conditions: conditions})
+	if err != nil {
+		return nil, err
+	}
+	res, err := osv2api.IndicesRolloverRequest{
+		Alias:    alias,
+		NewIndex: newIndex,
+		Body:     body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform rollover request")
+	}
+	defer closeBody(res.Body)
+
+	var result RolloverResult
+	if err := decodeResponse(res.StatusCode, res.Body, &result, "roll over alias "+alias); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (os *OSClientV2) PutIndexTemplate(ctx context.Context, name string, template IndexTemplate) error {
+	body, err := jsonBody(template)
+	if err != nil {
+		return err
+	}
+	res, err := osv2api.IndicesPutIndexTemplateRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put index template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put index template "+name)
+}
+
+func (os *OSClientV2) GetIndexTemplate(ctx context.Context, name string) (*IndexTemplate, error) {
+	res, err := osv2api.IndicesGetIndexTemplateRequest{
+		Name: []string{name},
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get index template request")
+	}
+	defer closeBody(res.Body)
+
+	var response indexTemplateGetResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get index template "+name); err != nil {
+		return nil, err
+	}
+	return response.template(name)
+}
+
+func (os *OSClientV2) DeleteIndexTemplate(ctx context.Context, name string) error {
+	res, err := osv2api.IndicesDeleteIndexTemplateRequest{
+		Name: name,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete index template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete index template "+name)
+}
+
+func (os *OSClientV2) PutComponentTemplate(ctx context.Context, name string, template ComponentTemplate) error {
+	body, err := jsonBody(template)
+	if err != nil {
+		return err
+	}
+	res, err := osv2api.ClusterPutComponentTemplateRequest{
+		Name: name,
+		Body: body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform put component template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "put component template "+name)
+}
+
+func (os *OSClientV2) GetComponentTemplate(ctx context.Context, name string) (*ComponentTemplate, error) {
+	res, err := osv2api.ClusterGetComponentTemplateRequest{
+		Name: []string{name},
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get component template request")
+	}
+	defer closeBody(res.Body)
+
+	var response componentTemplateGetResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "get component template "+name); err != nil {
+		return nil, err
+	}
+	return response.template(name)
+}
+
+func (os *OSClientV2) DeleteComponentTemplate(ctx context.Context, name string) error {
+	res, err := osv2api.ClusterDeleteComponentTemplateRequest{
+		Name: name,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform delete component template request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "delete component template "+name)
+}
+
+func (os *OSClientV2) putClusterSettings(ctx context.Context, settings clusterSettingsRequest) error {
+	body, err := jsonBody(settings)
+	if err != nil {
+		return err
+	}
+	res, err := osv2api.ClusterPutSettingsRequest{
+		Body: body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform cluster put settings request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "update cluster settings")
+}
+
+func (os *OSClientV2) ExcludeNodesFromAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, os, nodes, nil)
+}
+
+func (os *OSClientV2) IncludeNodesInAllocation(ctx context.Context, nodes ...string) error {
+	return updateAllocationExclude(ctx, os, nil, nodes)
+}
+
+func (os *OSClientV2) DrainNode(ctx context.Context, node string) error {
+	if err := os.ExcludeNodesFromAllocation(ctx, node); err != nil {
+		return err
+	}
+	return waitForNodeDrained(ctx, os, node)
+}
+
+func (os *OSClientV2) SetShardAllocation(ctx context.Context, mode string) error {
+	if err := validateAllocationEnable(mode); err != nil {
+		return err
+	}
+	return os.putClusterSettings(ctx, persistentSetting(settingAllocationEnable, mode))
+}
+
+func (os *OSClientV2) WaitForGreen(ctx context.Context) error {
+	return waitForGreen(ctx, os)
+}
+
+// Flush flushes the indices, all of them when none is given. Synced flush is
+// removed in this version, a plain flush has the same effect on recovery.
+func (os *OSClientV2) Flush(ctx context.Context, synced bool, indices ...string) error {
+	waitIfOngoing := true
+	res, err := osv2api.IndicesFlushRequest{
+		Index:         indices,
+		WaitIfOngoing: &waitIfOngoing,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform flush request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, nil, "flush")
+}
+
+func (os *OSClientV2) ExplainAllocation(ctx context.Context, index string, shard int, primary bool) (*AllocationExplanation, error) {
+	body, err := jsonBody(allocationExplainRequest{Index: index, Shard: shard, Primary: primary})
+	if err != nil {
+		return nil, err
+	}
+	res, err := osv2api.ClusterAllocationExplainRequest{
+		Body: body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform allocation explain request")
+	}
+	defer closeBody(res.Body)
+
+	var explanation AllocationExplanation
+	if err := decodeResponse(res.StatusCode, res.Body, &explanation, fmt.Sprintf("explain allocation of shard %d of index %s", shard, index)); err != nil {
+		return nil, err
+	}
+	return &explanation, nil
+}
+
+func (os *OSClientV2) DiagnoseUnassignedShards(ctx context.Context) ([]UnassignedShardDiagnosis, error) {
+	return diagnoseUnassignedShards(ctx, os)
+}
+
+func (os *OSClientV2) getClusterSettings(ctx context.Context) (*clusterSettingsResponse, error) {
+	includeDefaults, flatSettings := true, true
+	res, err := osv2api.ClusterGetSettingsRequest{
+		IncludeDefaults: &includeDefaults,
+		FlatSettings:    &flatSettings,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform cluster get settings request")
+	}
+	defer closeBody(res.Body)
+
+	var settings clusterSettingsResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &settings, "get cluster settings"); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (os *OSClientV2) GetDiskUsageReport(ctx context.Context) (*DiskUsageReport, error) {
+	settings, err := os.getClusterSettings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return diskUsageReport(ctx, os, settings)
+}
+
+func (os *OSClientV2) PutRemoteCluster(ctx context.Context, cluster RemoteCluster) error {
+	return os.putClusterSettings(ctx, remoteClusterSettings(cluster))
+}
+
+func (os *OSClientV2) DeleteRemoteCluster(ctx context.Context, name string) error {
+	return os.putClusterSettings(ctx, deleteRemoteClusterSettings(name))
+}
+
+func (os *OSClientV2) FollowIndex(ctx context.Context, followerIndex string, req FollowRequest) error {
+	return startReplication(ctx, os.client, followerIndex, req)
+}
+
+func (os *OSClientV2) PauseFollow(ctx context.Context, followerIndex string) error {
+	return replicationAction(ctx, os.client, followerIndex, "pause")
+}
+
+func (os *OSClientV2) ResumeFollow(ctx context.Context, followerIndex string) error {
+	return replicationAction(ctx, os.client, followerIndex, "resume")
+}
+
+// Unfollow stops the replication and turns the follower into a regular index.
+func (os *OSClientV2) Unfollow(ctx context.Context, followerIndex string) error {
+	return replicationAction(ctx, os.client, followerIndex, "stop")
+}
+
+func (os *OSClientV2) PutAutoFollowPattern(ctx context.Context, name string, pattern AutoFollowPattern) error {
+	return putReplicationAutoFollow(ctx, os.client, name, pattern)
+}
+
+func (os *OSClientV2) DeleteAutoFollowPattern(ctx context.Context, remoteCluster, name string) error {
+	return deleteReplicationAutoFollow(ctx, os.client, remoteCluster, name)
+}
+
+func (os *OSClientV2) GetFollowerStats(ctx context.Context, followerIndex string) (*FollowerStats, error) {
+	return getReplicationStatus(ctx, os.client, followerIndex)
+}
+
+func (os *OSClientV2) bulk(ctx context.Context, body io.Reader, refresh string) (*bulkResponse, error) {
+	res, err := osv2api.BulkRequest{
+		Body:    body,
+		Refresh: refresh,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform bulk request")
+	}
+	defer closeBody(res.Body)
+
+	return decodeBulkResponse(res.StatusCode, res.Body)
+}
+
+func (os *OSClientV2) NewBulkIndexer(config BulkIndexerConfig) (BulkIndexer, error) {
+	return newBulkIndexer(config, os.bulk, "")
+}
+
+func (os *OSClientV2) Reindex(ctx context.Context, request ReindexRequest) (string, error) {
+	body, err := jsonBody(request)
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := osv2api.ReindexRequest{
+		Body:              body,
+		Slices:            slicesParam(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform reindex request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "reindex to "+request.Dest.Index); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (os *OSClientV2) UpdateByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	body, err := jsonBody(request)
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := osv2api.UpdateByQueryRequest{
+		Index:             indices,
+		Body:              body,
+		Conflicts:         request.Conflicts,
+		Slices:            slicesParam(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform update by query request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "update by query"); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (os *OSClientV2) DeleteByQuery(ctx context.Context, request ByQueryRequest, indices ...string) (string, error) {
+	body, err := jsonBody(request.withQuery())
+	if err != nil {
+		return "", err
+	}
+	waitForCompletion := false
+	res, err := osv2api.DeleteByQueryRequest{
+		Index:             indices,
+		Body:              body,
+		Conflicts:         request.Conflicts,
+		Slices:            slicesParam(request.Slices),
+		RequestsPerSecond: request.RequestsPerSecond,
+		WaitForCompletion: &waitForCompletion,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to perform delete by query request")
+	}
+	defer closeBody(res.Body)
+
+	var response asyncTaskResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "delete by query"); err != nil {
+		return "", err
+	}
+	return response.Task, nil
+}
+
+func (os *OSClientV2) GetTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	res, err := osv2api.TasksGetRequest{
+		TaskID: taskID,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform get task request")
+	}
+	defer closeBody(res.Body)
+
+	var result TaskResult
+	if err := decodeResponse(res.StatusCode, res.Body, &result, "get task "+taskID); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+func (os *OSClientV2) ListTasks(ctx context.Context, actions ...string) ([]TaskInfo, error) {
+	detailed := true
+	res, err := osv2api.TasksListRequest{
+		Actions:  actions,
+		Detailed: &detailed,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform list tasks request")
+	}
+	defer closeBody(res.Body)
+
+	var response tasksListResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "list tasks"); err != nil {
+		return nil, err
+	}
+	return response.tasks(), nil
+}
+
+func (os *OSClientV2) CancelTask(ctx context.Context, taskID string) error {
+	res, err := osv2api.TasksCancelRequest{
+		TaskID: taskID,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return errors.Wrap(err, "failed to perform cancel task request")
+	}
+	defer closeBody(res.Body)
+
+	var response tasksCancelResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "cancel task "+taskID); err != nil {
+		return err
+	}
+	return response.err(taskID)
+}
+
+func (os *OSClientV2) WaitForTask(ctx context.Context, taskID string) (*TaskResult, error) {
+	return waitForTask(ctx, os, taskID)
+}
+
+// ReloadSecureSettings reloads the keystore of nodes, all the nodes when none
+// is given. keystorePassword is empty for a keystore without a password.
+func (os *OSClientV2) ReloadSecureSettings(ctx context.Context, keystorePassword string, nodes ...string) (*ReloadSecureSettingsResult, error) {
+	body, err := jsonBody(reloadSecureSettingsRequest{SecureSettingsPassword: keystorePassword})
+	if err != nil {
+		return nil, err
+	}
+	res, err := osv2api.NodesReloadSecureSettingsRequest{
+		NodeID: nodes,
+		Body:   body,
+	}.Do(ctx, os.client)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to perform reload secure settings request")
+	}
+	defer closeBody(res.Body)
+
+	var response reloadSecureSettingsResponse
+	if err := decodeResponse(res.StatusCode, res.Body, &response, "reload secure settings"); err != nil {
+		return nil, err
+	}
+	return response.result(), nil
+}
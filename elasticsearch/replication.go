@@ -0,0 +1,305 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	kutil "kmodules.xyz/client-go"
+)
+
+const (
+	FollowerStatusActive        = "active"
+	FollowerStatusPaused        = "paused"
+	FollowerStatusBootstrapping = "bootstrapping"
+	FollowerStatusFailed        = "failed"
+)
+
+// RemoteCluster is a cluster registered in the "cluster.remote" settings, used
+// as the leader of cross-cluster replication.
+type RemoteCluster struct {
+	Name string
+	// Seeds are the transport addresses of the nodes of the remote cluster,
+	// e.g. "leader.demo.svc:9300". They are ignored when ProxyAddress is set.
+	Seeds []string
+	// ProxyAddress connects to the remote cluster through a single proxy in
+	// proxy mode, available since Elasticsearch 7.7 and in OpenSearch.
+	ProxyAddress    string
+	SkipUnavailable *bool
+}
+
+// FollowRequest starts the replication of a leader index to a follower index.
+type FollowRequest struct {
+	RemoteCluster string
+	LeaderIndex   string
+	// LeaderClusterRole and FollowerClusterRole are the roles used for the
+	// replication when the OpenSearch security plugin is enabled. They are
+	// ignored by Elasticsearch.
+	LeaderClusterRole   string
+	FollowerClusterRole string
+}
+
+// AutoFollowPattern creates follower indices for the new leader indices that
+// match any of LeaderIndexPatterns. OpenSearch supports a single pattern and
+// names the followers as the leaders, FollowIndexPattern must be empty there.
+type AutoFollowPattern struct {
+	RemoteCluster       string
+	LeaderIndexPatterns []string
+	// FollowIndexPattern names the followers, e.g. "{{leader_index}}-copy".
+	FollowIndexPattern  string
+	LeaderClusterRole   string
+	FollowerClusterRole string
+}
+
+type FollowerStats struct {
+	FollowerIndex string
+	LeaderIndex   string
+	RemoteCluster string
+	// Status is one of the FollowerStatus constants.
+	Status string
+	// OperationsLag is the number of operations the follower is behind the
+	// leader.
+	OperationsLag int64
+	// Shards are only reported by Elasticsearch.
+	Shards []FollowerShardStats
+	// Failure is the reason of the last failure of the replication, if any.
+	Failure string
+}
+
+type FollowerShardStats struct {
+	ShardID            int   `json:"shard_id"`
+	LeaderCheckpoint   int64 `json:"leader_global_checkpoint"`
+	FollowerCheckpoint int64 `json:"follower_global_checkpoint"`
+}
+
+func remoteClusterSettings(cluster RemoteCluster) clusterSettingsRequest {
+	prefix := "cluster.remote." + cluster.Name + "."
+	settings := map[string]interface{}{}
+	if cluster.ProxyAddress != "" {
+		settings[prefix+"mode"] = "proxy"
+		settings[prefix+"proxy_address"] = cluster.ProxyAddress
+	} else {
+		settings[prefix+"seeds"] = cluster.Seeds
+	}
+	if cluster.SkipUnavailable != nil {
+		settings[prefix+"skip_unavailable"] = *cluster.SkipUnavailable
+	}
+	return clusterSettingsRequest{Persistent: settings}
+}
+
+func deleteRemoteClusterSettings(name string) clusterSettingsRequest {
+	return persistentSetting("cluster.remote."+name+".*", nil)
+}
+
+type ccrFollowRequest struct {
+	RemoteCluster string `json:"remote_cluster"`
+	LeaderIndex   string `json:"leader_index"`
+}
+
+type ccrAutoFollowPattern struct {
+	RemoteCluster       string   `json:"remote_cluster"`
+	LeaderIndexPatterns []string `json:"leader_index_patterns"`
+	FollowIndexPattern  string   `json:"follow_index_pattern,omitempty"`
+}
+
+type ccrFollowInfoResponse struct {
+	FollowerIndices []struct {
+		FollowerIndex string `json:"follower_index"`
+		RemoteCluster string `json:"remote_cluster"`
+		LeaderIndex   string `json:"leader_index"`
+		Status        string `json:"status"`
+	} `json:"follower_indices"`
+}
+
+type ccrFollowStatsResponse struct {
+	Indices []struct {
+		Index  string `json:"index"`
+		Shards []struct {
+			FollowerShardStats
+			ReadExceptions []struct {
+				Exception struct {
+					Type   string `json:"type"`
+					Reason string `json:"reason"`
+				} `json:"exception"`
+			} `json:"read_exceptions"`
+			FatalException *struct {
+				Type   string `json:"type"`
+				Reason string `json:"reason"`
+			} `json:"fatal_exception"`
+		} `json:"shards"`
+	} `json:"indices"`
+}
+
+// followerStats merges the follow info and the follow stats of index. The
+// stats are only reported for the active followers.
+func followerStats(index string, info *ccrFollowInfoResponse, stats *ccrFollowStatsResponse) (*FollowerStats, error) {
+	var result *FollowerStats
+	for _, follower := range info.FollowerIndices {
+		if follower.FollowerIndex == index {
+			result = &FollowerStats{
+				FollowerIndex: follower.FollowerIndex,
+				LeaderIndex:   follower.LeaderIndex,
+				RemoteCluster: follower.RemoteCluster,
+				Status:        follower.Status,
+			}
+		}
+	}
+	if result == nil {
+		return nil, fmt.Errorf("index %s is not a follower index: %w", index, kutil.ErrNotFound)
+	}
+	if stats == nil {
+		return result, nil
+	}
+
+	for _, indexStats := range stats.Indices {
+		if indexStats.Index != index {
+			continue
+		}
+		for _, shard := range indexStats.Shards {
+			result.Shards = append(result.Shards, shard.FollowerShardStats)
+			result.OperationsLag += shard.LeaderCheckpoint - shard.FollowerCheckpoint
+			switch {
+			case shard.FatalException != nil:
+				result.Status = FollowerStatusFailed
+				result.Failure = fmt.Sprintf("%s: %s", shard.FatalException.Type, shard.FatalException.Reason)
+			case len(shard.ReadExceptions) > 0 && result.Failure == "":
+				e := shard.ReadExceptions[len(shard.ReadExceptions)-1].Exception
+				result.Failure = fmt.Sprintf("%s: %s", e.Type, e.Reason)
+			}
+		}
+	}
+	return result, nil
+}
+
+// The replication plugin of OpenSearch is missing in the OpenSearch API
+// packages, so it is called through the transport of the clients.
+
+const replicationPluginPath = "/_plugins/_replication"
+
+type replicationRoles struct {
+	LeaderClusterRole   string `json:"leader_cluster_role"`
+	FollowerClusterRole string `json:"follower_cluster_role"`
+}
+
+func useRoles(leader, follower string) *replicationRoles {
+	if leader == "" && follower == "" {
+		return nil
+	}
+	return &replicationRoles{LeaderClusterRole: leader, FollowerClusterRole: follower}
+}
+
+type replicationStartRequest struct {
+	LeaderAlias string            `json:"leader_alias"`
+	LeaderIndex string            `json:"leader_index"`
+	UseRoles    *replicationRoles `json:"use_roles,omitempty"`
+}
+
+type replicationAutoFollowRequest struct {
+	LeaderAlias string            `json:"leader_alias"`
+	Name        string            `json:"name"`
+	Pattern     string            `json:"pattern,omitempty"`
+	UseRoles    *replicationRoles `json:"use_roles,omitempty"`
+}
+
+type replicationStatusResponse struct {
+	Status         string `json:"status"`
+	Reason         string `json:"reason"`
+	LeaderAlias    string `json:"leader_alias"`
+	LeaderIndex    string `json:"leader_index"`
+	FollowerIndex  string `json:"follower_index"`
+	SyncingDetails *struct {
+		LeaderCheckpoint   int64 `json:"leader_checkpoint"`
+		FollowerCheckpoint int64 `json:"follower_checkpoint"`
+	} `json:"syncing_details"`
+}
+
+func startReplication(ctx context.Context, client performer, followerIndex string, req FollowRequest) error {
+	body := replicationStartRequest{
+		LeaderAlias: req.RemoteCluster,
+		LeaderIndex: req.LeaderIndex,
+		UseRoles:    useRoles(req.LeaderClusterRole, req.FollowerClusterRole),
+	}
+	return performJSON(ctx, client, http.MethodPut, replicationPluginPath+"/"+url.PathEscape(followerIndex)+"/_start", body, nil, "start replication of "+followerIndex)
+}
+
+// replicationAction calls the pause, resume and stop actions, which require
+// an empty body.
+func replicationAction(ctx context.Context, client performer, followerIndex, action string) error {
+	return performJSON(ctx, client, http.MethodPost, replicationPluginPath+"/"+url.PathEscape(followerIndex)+"/_"+action, struct{}{}, nil, action+" replication of "+followerIndex)
+}
+
+func putReplicationAutoFollow(ctx context.Context, client performer, name string, pattern AutoFollowPattern) error {
+	if len(pattern.LeaderIndexPatterns) != 1 {
+		return errors.New("auto-follow rules of OpenSearch must have exactly one leader index pattern")
+	}
+	if pattern.FollowIndexPattern != "" {
+		return errors.New("auto-follow rules of OpenSearch do not support a follow index pattern")
+	}
+	body := replicationAutoFollowRequest{
+		LeaderAlias: pattern.RemoteCluster,
+		Name:        name,
+		Pattern:     pattern.LeaderIndexPatterns[0],
+		UseRoles:    useRoles(pattern.LeaderClusterRole, pattern.FollowerClusterRole),
+	}
+	return performJSON(ctx, client, http.MethodPost, replicationPluginPath+"/_autofollow", body, nil, "put auto-follow rule "+name)
+}
+
+func deleteReplicationAutoFollow(ctx context.Context, client performer, remoteCluster, name string) error {
+	body := replicationAutoFollowRequest{
+		LeaderAlias: remoteCluster,
+		Name:        name,
+	}
+	return performJSON(ctx, client, http.MethodDelete, replicationPluginPath+"/_autofollow", body, nil, "delete auto-follow rule "+name)
+}
+
+func getReplicationStatus(ctx context.Context, client performer, followerIndex string) (*FollowerStats, error) {
+	var response replicationStatusResponse
+	err := performJSON(ctx, client, http.MethodGet, replicationPluginPath+"/"+url.PathEscape(followerIndex)+"/_status", nil, &response, "get replication status of "+followerIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &FollowerStats{
+		FollowerIndex: followerIndex,
+		LeaderIndex:   response.LeaderIndex,
+		RemoteCluster: response.LeaderAlias,
+		Failure:       response.Reason,
+	}
+	switch response.Status {
+	case "SYNCING":
+		stats.Status = FollowerStatusActive
+	case "BOOTSTRAPPING":
+		stats.Status = FollowerStatusBootstrapping
+	case "PAUSED":
+		stats.Status = FollowerStatusPaused
+	case "FAILED":
+		stats.Status = FollowerStatusFailed
+	case "REPLICATION NOT IN PROGRESS":
+		return nil, fmt.Errorf("index %s is not a follower index: %w", followerIndex, kutil.ErrNotFound)
+	default:
+		stats.Status = strings.ToLower(response.Status)
+	}
+	if response.SyncingDetails != nil {
+		stats.OperationsLag = response.SyncingDetails.LeaderCheckpoint - response.SyncingDetails.FollowerCheckpoint
+	}
+	return stats, nil
+}
@@ -0,0 +1,94 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"sort"
+)
+
+// ReloadSecureSettingsResult is the result of reloading the keystore of the
+// nodes, e.g. after the credentials of a snapshot repository are rotated.
+type ReloadSecureSettingsResult struct {
+	ClusterName string
+	Nodes       []NodeReloadResult
+}
+
+// NodeReloadResult is the result of a node. Error is empty when the secure
+// settings of the node are reloaded.
+type NodeReloadResult struct {
+	NodeID string
+	Name   string
+	Error  string
+}
+
+// Failed returns the nodes that failed to reload the secure settings.
+func (r *ReloadSecureSettingsResult) Failed() []NodeReloadResult {
+	var failed []NodeReloadResult
+	for _, node := range r.Nodes {
+		if node.Error != "" {
+			failed = append(failed, node)
+		}
+	}
+	return failed
+}
+
+// reloadSecureSettingsRequest sets the password of the keystore, supported
+// since Elasticsearch 7.7 and in OpenSearch.
+type reloadSecureSettingsRequest struct {
+	SecureSettingsPassword string `json:"secure_settings_password,omitempty"`
+}
+
+type reloadSecureSettingsResponse struct {
+	NodesInfo struct {
+		Failures []struct {
+			NodeID string `json:"node_id"`
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"failures"`
+	} `json:"_nodes"`
+	ClusterName string `json:"cluster_name"`
+	Nodes       map[string]struct {
+		Name            string `json:"name"`
+		ReloadException *struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"reload_exception"`
+	} `json:"nodes"`
+}
+
+// result returns the nodes sorted by name, followed by the nodes that failed
+// to respond.
+func (r *reloadSecureSettingsResponse) result() *ReloadSecureSettingsResult {
+	result := &ReloadSecureSettingsResult{ClusterName: r.ClusterName}
+	for id, node := range r.Nodes {
+		n := NodeReloadResult{NodeID: id, Name: node.Name}
+		if node.ReloadException != nil {
+			n.Error = node.ReloadException.Type + ": " + node.ReloadException.Reason
+		}
+		result.Nodes = append(result.Nodes, n)
+	}
+	sort.Slice(result.Nodes, func(i, j int) bool {
+		return result.Nodes[i].Name < result.Nodes[j].Name
+	})
+	for _, failure := range r.NodesInfo.Failures {
+		result.Nodes = append(result.Nodes, NodeReloadResult{
+			NodeID: failure.NodeID,
+			Error:  failure.Type + ": " + failure.Reason,
+		})
+	}
+	return result
+}
@@ -0,0 +1,231 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"sort"
+	"sync"
+
+	"kubedb.dev/db-client-go/dberrors"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+	kutil "kmodules.xyz/client-go"
+)
+
+const (
+	securityPluginPath       = "/_plugins/_security"
+	securityPluginLegacyPath = "/_opendistro/_security"
+)
+
+// Action groups of the OpenSearch security plugin granted by the custom role.
+const (
+	ActionGroupClusterAll      = "cluster_all"
+	ActionGroupManageSnapshots = "manage_snapshots"
+	ActionGroupRead            = "read"
+	ActionGroupWrite           = "write"
+	ActionGroupCreateIndex     = "create_index"
+	ActionGroupKibanaAllWrite  = "kibana_all_write"
+	TenantGlobal               = "global_tenant"
+)
+
+// RoleMapper is implemented by the clients of the OpenSearch security plugin,
+// where a role only takes effect once it is mapped to users.
+type RoleMapper interface {
+	MapDBUserRole(ctx context.Context, users ...string) error
+}
+
+type IndexPermission struct {
+	IndexPatterns  []string `json:"index_patterns"`
+	AllowedActions []string `json:"allowed_actions"`
+}
+
+type TenantPermission struct {
+	TenantPatterns []string `json:"tenant_patterns"`
+	AllowedActions []string `json:"allowed_actions"`
+}
+
+// SecurityPluginRole is a role of the OpenSearch security plugin.
+type SecurityPluginRole struct {
+	ClusterPermissions []string           `json:"cluster_permissions"`
+	IndexPermissions   []IndexPermission  `json:"index_permissions"`
+	TenantPermissions  []TenantPermission `json:"tenant_permissions"`
+}
+
+// SecurityPluginRoleMapping maps a role of the OpenSearch security plugin to
+// users and backend roles.
+type SecurityPluginRoleMapping struct {
+	Users        []string `json:"users"`
+	BackendRoles []string `json:"backend_roles"`
+	Hosts        []string `json:"hosts"`
+}
+
+type jsonPatchOperation struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	Value interface{} `json:"value,omitempty"`
+}
+
+// dbUserRole is the OpenSearch equivalent of the X-Pack role created by
+// CreateDBUserRole.
+func dbUserRole() SecurityPluginRole {
+	return SecurityPluginRole{
+		ClusterPermissions: []string{ActionGroupClusterAll, ActionGroupManageSnapshots},
+		IndexPermissions: []IndexPermission{
+			{
+				IndexPatterns:  []string{PrivilegeIndexAny},
+				AllowedActions: []string{ActionGroupRead, ActionGroupWrite, ActionGroupCreateIndex},
+			},
+		},
+		TenantPermissions: []TenantPermission{
+			{
+				TenantPatterns: []string{TenantGlobal},
+				AllowedActions: []string{ActionGroupKibanaAllWrite},
+			},
+		},
+	}
+}
+
+// securityPlugin calls the REST API of the OpenSearch security plugin. The
+// API is served under "_plugins/_security" by OpenSearch and under the legacy
+// "_opendistro/_security" by the OpenDistro plugin for Elasticsearch.
+type securityPlugin struct {
+	client performer
+
+	mu       sync.Mutex
+	basePath string
+}
+
+func newSecurityPlugin(client performer) *securityPlugin {
+	return &securityPlugin{client: client}
+}
+
+// apiPath returns the path of the REST API, detecting the prefix on the
+// first call.
+func (s *securityPlugin) apiPath(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.basePath != "" {
+		return s.basePath + "/api", nil
+	}
+
+	res, err := performRequest(ctx, s.client, http.MethodGet, securityPluginPath+"/health", nil)
+	if err != nil {
+		return "", err
+	}
+	closeBody(res.Body)
+	switch {
+	case res.StatusCode == http.StatusNotFound || res.StatusCode == http.StatusBadRequest:
+		// unknown paths are rejected with 400 before Elasticsearch 7.x
+		s.basePath = securityPluginLegacyPath
+	case res.StatusCode >= http.StatusBadRequest:
+		return "", dberrors.HTTPStatusError(res.StatusCode, "security plugin health check failed with status code %d", res.StatusCode)
+	default:
+		s.basePath = securityPluginPath
+	}
+	klog.V(5).Infof("using security plugin API at %s", s.basePath)
+	return s.basePath + "/api", nil
+}
+
+// do calls the REST API at path, relative to the API path, and decodes the
+// response into out, if out is not nil.
+func (s *securityPlugin) do(ctx context.Context, method, path string, body, out interface{}, action string) error {
+	apiPath, err := s.apiPath(ctx)
+	if err != nil {
+		return err
+	}
+	return performJSON(ctx, s.client, method, apiPath+path, body, out, action)
+}
+
+func (s *securityPlugin) syncCredentialFromSecret(ctx context.Context, secret *core.Secret) error {
+	var username, password string
+	if value, ok := secret.Data[core.BasicAuthUsernameKey]; ok {
+		username = string(value)
+	} else {
+		return dberrors.Errorf(dberrors.ErrInvalidSecret, "username is missing")
+	}
+	if value, ok := secret.Data[core.BasicAuthPasswordKey]; ok {
+		password = string(value)
+	} else {
+		return dberrors.Errorf(dberrors.ErrInvalidSecret, "password is missing")
+	}
+
+	patch := []jsonPatchOperation{
+		{Op: "add", Path: "/password", Value: password},
+	}
+	err := s.do(ctx, http.MethodPatch, "/internalusers/"+url.PathEscape(username), patch, nil, "sync credentials of user "+username)
+	if err != nil {
+		klog.V(5).Infoln("Failed to sync", username, "credentials")
+		return err
+	}
+	klog.V(5).Infoln(username, "user credentials successfully synced")
+	return nil
+}
+
+func (s *securityPlugin) getDBUserRole(ctx context.Context) (error, bool) {
+	err := s.do(ctx, http.MethodGet, "/roles/"+CustomRoleName, nil, nil, "get DB user role")
+	if errors.Is(err, kutil.ErrNotFound) {
+		return nil, false
+	}
+	if err != nil {
+		klog.Errorf("failed to get existing DB user role, reason: %s", err)
+		return err, false
+	}
+	return nil, true
+}
+
+func (s *securityPlugin) createDBUserRole(ctx context.Context) error {
+	err := s.do(ctx, http.MethodPut, "/roles/"+CustomRoleName, dbUserRole(), nil, "create DB user role")
+	if err != nil {
+		klog.Errorf("Failed to create DB user role, reason: %s", err)
+		return err
+	}
+	return nil
+}
+
+// mapDBUserRole adds users to the mapping of the custom role, keeping the
+// users, backend roles and hosts that are already mapped.
+func (s *securityPlugin) mapDBUserRole(ctx context.Context, users ...string) error {
+	var response map[string]SecurityPluginRoleMapping
+	err := s.do(ctx, http.MethodGet, "/rolesmapping/"+CustomRoleName, nil, &response, "get DB user role mapping")
+	if err != nil && !errors.Is(err, kutil.ErrNotFound) {
+		return err
+	}
+	mapping := response[CustomRoleName]
+
+	set := map[string]struct{}{}
+	for _, user := range append(mapping.Users, users...) {
+		set[user] = struct{}{}
+	}
+	mapping.Users = make([]string, 0, len(set))
+	for user := range set {
+		mapping.Users = append(mapping.Users, user)
+	}
+	sort.Strings(mapping.Users)
+	if mapping.BackendRoles == nil {
+		mapping.BackendRoles = []string{}
+	}
+	if mapping.Hosts == nil {
+		mapping.Hosts = []string{}
+	}
+
+	return s.do(ctx, http.MethodPut, "/rolesmapping/"+CustomRoleName, mapping, nil, "map DB user role")
+}
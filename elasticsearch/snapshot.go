@@ -0,0 +1,197 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	SnapshotRepositoryTypeFS    = "fs"
+	SnapshotRepositoryTypeS3    = "s3"
+	SnapshotRepositoryTypeGCS   = "gcs"
+	SnapshotRepositoryTypeAzure = "azure"
+)
+
+const (
+	SnapshotStateInProgress = "IN_PROGRESS"
+	SnapshotStateSuccess    = "SUCCESS"
+	SnapshotStatePartial    = "PARTIAL"
+	SnapshotStateFailed     = "FAILED"
+)
+
+// SnapshotRepository is the definition of a snapshot repository. Settings
+// are passed through as is, e.g. "location" for fs, "bucket", "base_path"
+// and "client" for s3 and gcs or "container" for azure.
+type SnapshotRepository struct {
+	Type     string                 `json:"type"`
+	Settings map[string]interface{} `json:"settings,omitempty"`
+}
+
+type SnapshotOptions struct {
+	Indices            []string               `json:"indices,omitempty"`
+	IgnoreUnavailable  bool                   `json:"ignore_unavailable,omitempty"`
+	IncludeGlobalState *bool                  `json:"include_global_state,omitempty"`
+	Partial            bool                   `json:"partial,omitempty"`
+	Metadata           map[string]interface{} `json:"metadata,omitempty"`
+	// WaitForCompletion returns when the snapshot is done instead of when
+	// it is started.
+	WaitForCompletion bool `json:"-"`
+}
+
+type RestoreOptions struct {
+	Indices            []string `json:"indices,omitempty"`
+	IgnoreUnavailable  bool     `json:"ignore_unavailable,omitempty"`
+	IncludeGlobalState bool     `json:"include_global_state,omitempty"`
+	IncludeAliases     *bool    `json:"include_aliases,omitempty"`
+	// Partial restores the indices of which some shards are missing in the
+	// snapshot, the missing shards are recreated empty.
+	Partial bool `json:"partial,omitempty"`
+	// RenamePattern and RenameReplacement rename the restored indices,
+	// e.g. "(.+)" and "restored-$1".
+	RenamePattern       string                 `json:"rename_pattern,omitempty"`
+	RenameReplacement   string                 `json:"rename_replacement,omitempty"`
+	IndexSettings       map[string]interface{} `json:"index_settings,omitempty"`
+	IgnoreIndexSettings []string               `json:"ignore_index_settings,omitempty"`
+	// WaitForCompletion returns when the restore is done instead of when it
+	// is started.
+	WaitForCompletion bool `json:"-"`
+}
+
+type SnapshotInfo struct {
+	Snapshot          string                 `json:"snapshot"`
+	UUID              string                 `json:"uuid,omitempty"`
+	Repository        string                 `json:"repository,omitempty"`
+	Version           string                 `json:"version,omitempty"`
+	Indices           []string               `json:"indices,omitempty"`
+	State             string                 `json:"state,omitempty"`
+	Reason            string                 `json:"reason,omitempty"`
+	StartTimeInMillis int64                  `json:"start_time_in_millis,omitempty"`
+	EndTimeInMillis   int64                  `json:"end_time_in_millis,omitempty"`
+	DurationInMillis  int64                  `json:"duration_in_millis,omitempty"`
+	Failures          []SnapshotShardFailure `json:"failures,omitempty"`
+	Shards            SnapshotShards         `json:"shards"`
+}
+
+type SnapshotShardFailure struct {
+	Index   string `json:"index"`
+	ShardID int    `json:"shard_id"`
+	NodeID  string `json:"node_id,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Status  string `json:"status,omitempty"`
+}
+
+type SnapshotShards struct {
+	Total      int `json:"total"`
+	Failed     int `json:"failed"`
+	Successful int `json:"successful"`
+}
+
+type SnapshotStatus struct {
+	Snapshot           string              `json:"snapshot"`
+	Repository         string              `json:"repository"`
+	UUID               string              `json:"uuid,omitempty"`
+	State              string              `json:"state"`
+	IncludeGlobalState bool                `json:"include_global_state"`
+	ShardsStats        SnapshotShardsStats `json:"shards_stats"`
+	Stats              SnapshotStats       `json:"stats"`
+}
+
+type SnapshotShardsStats struct {
+	Initializing int `json:"initializing"`
+	Started      int `json:"started"`
+	Finalizing   int `json:"finalizing"`
+	Done         int `json:"done"`
+	Failed       int `json:"failed"`
+	Total        int `json:"total"`
+}
+
+// SnapshotStats are the file stats of a snapshot. Incremental and Total are
+// reported since Elasticsearch 7.
+type SnapshotStats struct {
+	Incremental       SnapshotFileStats `json:"incremental"`
+	Processed         SnapshotFileStats `json:"processed"`
+	Total             SnapshotFileStats `json:"total"`
+	StartTimeInMillis int64             `json:"start_time_in_millis"`
+	TimeInMillis      int64             `json:"time_in_millis"`
+}
+
+type SnapshotFileStats struct {
+	FileCount   int   `json:"file_count"`
+	SizeInBytes int64 `json:"size_in_bytes"`
+}
+
+type snapshotGetResponse struct {
+	Snapshots []SnapshotInfo `json:"snapshots"`
+}
+
+type snapshotCreateResponse struct {
+	Snapshot *SnapshotInfo `json:"snapshot"`
+}
+
+// info returns the created snapshot. The response of a snapshot that is not
+// waited for has no snapshot, so it is reported as in progress.
+func (r *snapshotCreateResponse) info(repository, snapshot string) *SnapshotInfo {
+	if r.Snapshot != nil {
+		return r.Snapshot
+	}
+	return &SnapshotInfo{
+		Snapshot:   snapshot,
+		Repository: repository,
+		State:      SnapshotStateInProgress,
+	}
+}
+
+type snapshotStatusResponse struct {
+	Snapshots []SnapshotStatus `json:"snapshots"`
+}
+
+type verifyRepositoryResponse struct {
+	Nodes map[string]struct {
+		Name string `json:"name"`
+	} `json:"nodes"`
+}
+
+func (r *verifyRepositoryResponse) nodeNames() []string {
+	names := make([]string, 0, len(r.Nodes))
+	for _, node := range r.Nodes {
+		names = append(names, node.Name)
+	}
+	return names
+}
+
+func (r *snapshotStatusResponse) status(snapshot string) (*SnapshotStatus, error) {
+	for i := range r.Snapshots {
+		if r.Snapshots[i].Snapshot == snapshot {
+			return &r.Snapshots[i], nil
+		}
+	}
+	return nil, errors.Errorf("status of snapshot %s is missing in the response", snapshot)
+}
+
+// jsonBody encodes v as a request body.
+func jsonBody(v interface{}) (io.Reader, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encode request body")
+	}
+	return bytes.NewReader(body), nil
+}
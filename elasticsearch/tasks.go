@@ -0,0 +1,280 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearch
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"kubedb.dev/db-client-go/retry"
+
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	TaskActionReindex       = "indices:data/write/reindex"
+	TaskActionUpdateByQuery = "indices:data/write/update/byquery"
+	TaskActionDeleteByQuery = "indices:data/write/delete/byquery"
+)
+
+// ConflictsProceed counts the version conflicts instead of aborting the task.
+const ConflictsProceed = "proceed"
+
+const taskPollInterval = 5 * time.Second
+
+// ReindexRequest copies the documents of the source indices to the dest
+// index. The remote hosts must be allowed by the "reindex.remote.whitelist"
+// setting of the local cluster.
+type ReindexRequest struct {
+	Source ReindexSource `json:"source"`
+	Dest   ReindexDest   `json:"dest"`
+	Script *Script       `json:"script,omitempty"`
+	// Conflicts is ConflictsProceed to ignore the version conflicts.
+	Conflicts string `json:"conflicts,omitempty"`
+	// Slices splits the task into subtasks, 1 by default.
+	Slices int `json:"-"`
+	// RequestsPerSecond throttles the task, unlimited by default.
+	RequestsPerSecond *int `json:"-"`
+}
+
+type ReindexSource struct {
+	Index  []string               `json:"index"`
+	Query  map[string]interface{} `json:"query,omitempty"`
+	Remote *ReindexRemote         `json:"remote,omitempty"`
+	// Size is the number of documents copied in a batch, 1000 by default.
+	Size int `json:"size,omitempty"`
+}
+
+// ReindexRemote is a remote cluster to reindex from, e.g. the cluster of an
+// older version during an upgrade.
+type ReindexRemote struct {
+	// Host is the http address of the remote cluster, e.g.
+	// "https://old.demo.svc:9200".
+	Host           string            `json:"host"`
+	Username       string            `json:"username,omitempty"`
+	Password       string            `json:"password,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+	SocketTimeout  string            `json:"socket_timeout,omitempty"`
+	ConnectTimeout string            `json:"connect_timeout,omitempty"`
+}
+
+type ReindexDest struct {
+	Index string `json:"index"`
+	// OpType is "create" to copy only the missing documents.
+	OpType      string `json:"op_type,omitempty"`
+	VersionType string `json:"version_type,omitempty"`
+	Pipeline    string `json:"pipeline,omitempty"`
+}
+
+type Script struct {
+	Source string                 `json:"source"`
+	Lang   string                 `json:"lang,omitempty"`
+	Params map[string]interface{} `json:"params,omitempty"`
+}
+
+// ByQueryRequest updates or deletes the documents that match Query, all the
+// documents when it is nil.
+type ByQueryRequest struct {
+	Query map[string]interface{} `json:"query,omitempty"`
+	// Script is only used by update by query.
+	Script            *Script `json:"script,omitempty"`
+	Conflicts         string  `json:"-"`
+	Slices            int     `json:"-"`
+	RequestsPerSecond *int    `json:"-"`
+}
+
+// withQuery returns the request with a match_all query when Query is nil,
+// since delete by query requires a query.
+func (r ByQueryRequest) withQuery() ByQueryRequest {
+	if r.Query == nil {
+		r.Query = map[string]interface{}{"match_all": map[string]interface{}{}}
+	}
+	return r
+}
+
+// TaskInfo is a task running in the cluster. Status is reported by the
+// reindex and the by query tasks.
+type TaskInfo struct {
+	Node               string      `json:"node"`
+	ID                 int64       `json:"id"`
+	Type               string      `json:"type"`
+	Action             string      `json:"action"`
+	Description        string      `json:"description"`
+	StartTimeInMillis  int64       `json:"start_time_in_millis"`
+	RunningTimeInNanos int64       `json:"running_time_in_nanos"`
+	Cancellable        bool        `json:"cancellable"`
+	ParentTaskID       string      `json:"parent_task_id,omitempty"`
+	Status             *TaskStatus `json:"status,omitempty"`
+}
+
+// TaskID returns the id of the task in the format of the tasks API, i.e.
+// "<node>:<id>".
+func (t *TaskInfo) TaskID() string {
+	return fmt.Sprintf("%s:%d", t.Node, t.ID)
+}
+
+type TaskStatus struct {
+	Total            int64 `json:"total"`
+	Created          int64 `json:"created"`
+	Updated          int64 `json:"updated"`
+	Deleted          int64 `json:"deleted"`
+	Batches          int64 `json:"batches"`
+	VersionConflicts int64 `json:"version_conflicts"`
+	Noops            int64 `json:"noops"`
+}
+
+// Progress returns the percentage of the documents that are processed.
+func (s *TaskStatus) Progress() float64 {
+	if s.Total == 0 {
+		return 0
+	}
+	processed := s.Created + s.Updated + s.Deleted + s.VersionConflicts + s.Noops
+	return float64(processed) * 100 / float64(s.Total)
+}
+
+// TaskResult is the state of a task. Response and Error are only set for the
+// completed tasks.
+type TaskResult struct {
+	Completed bool          `json:"completed"`
+	Task      TaskInfo      `json:"task"`
+	Response  *TaskResponse `json:"response,omitempty"`
+	Error     *struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error,omitempty"`
+}
+
+// TaskResponse is the response of a completed reindex or by query task.
+// Failures are the documents that failed, which abort the task.
+type TaskResponse struct {
+	TaskStatus
+	Took     int64                    `json:"took"`
+	TimedOut bool                     `json:"timed_out"`
+	Failures []map[string]interface{} `json:"failures"`
+}
+
+// Progress returns the percentage of the documents that are processed by the
+// task.
+func (r *TaskResult) Progress() float64 {
+	if r.Response != nil {
+		return r.Response.Progress()
+	}
+	if r.Task.Status != nil {
+		return r.Task.Status.Progress()
+	}
+	return 0
+}
+
+type asyncTaskResponse struct {
+	Task string `json:"task"`
+}
+
+type tasksListResponse struct {
+	Nodes map[string]struct {
+		Tasks map[string]TaskInfo `json:"tasks"`
+	} `json:"nodes"`
+}
+
+func (r *tasksListResponse) tasks() []TaskInfo {
+	var tasks []TaskInfo
+	for _, node := range r.Nodes {
+		for _, task := range node.Tasks {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].StartTimeInMillis < tasks[j].StartTimeInMillis
+	})
+	return tasks
+}
+
+type tasksCancelResponse struct {
+	NodeFailures []struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"node_failures"`
+	TaskFailures []struct {
+		Reason struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		} `json:"reason"`
+	} `json:"task_failures"`
+}
+
+func (r *tasksCancelResponse) err(taskID string) error {
+	var reasons []string
+	for _, f := range r.NodeFailures {
+		reasons = append(reasons, f.Type+": "+f.Reason)
+	}
+	for _, f := range r.TaskFailures {
+		reasons = append(reasons, f.Reason.Type+": "+f.Reason.Reason)
+	}
+	if len(reasons) > 0 {
+		return errors.Errorf("failed to cancel task %s, reason: %s", taskID, strings.Join(reasons, "; "))
+	}
+	return nil
+}
+
+// waitForTask polls the task until it is completed. The result of a failed
+// task is returned along with its error.
+func waitForTask(ctx context.Context, client ESClient, taskID string) (*TaskResult, error) {
+	policy := &retry.Policy{
+		InitialInterval: taskPollInterval,
+		Multiplier:      1,
+	}
+	var result *TaskResult
+	err := policy.Poll(ctx, func(ctx context.Context) (bool, error) {
+		var err error
+		result, err = client.GetTask(ctx, taskID)
+		if err != nil {
+			return false, err
+		}
+		klog.V(5).Infof("task %s is %.1f%% done", taskID, result.Progress())
+		return result.Completed, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if result.Error != nil {
+		return result, errors.Errorf("task %s failed, reason: %s: %s", taskID, result.Error.Type, result.Error.Reason)
+	}
+	if result.Response != nil && len(result.Response.Failures) > 0 {
+		return result, errors.Errorf("task %s failed for %d documents", taskID, len(result.Response.Failures))
+	}
+	return result, nil
+}
+
+// slicesV6 returns the slices parameter of versions 5 and 6.
+func slicesV6(slices int) *int {
+	if slices == 0 {
+		return nil
+	}
+	return &slices
+}
+
+// slicesParam returns the slices parameter, which also accepts "auto" since
+// version 7.
+func slicesParam(slices int) interface{} {
+	if slices == 0 {
+		return nil
+	}
+	return slices
+}
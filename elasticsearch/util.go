@@ -17,11 +17,17 @@ limitations under the License.
 package elasticsearch
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
+	"net/http"
 
+	"kubedb.dev/db-client-go/dberrors"
+
+	"github.com/pkg/errors"
 	"k8s.io/klog/v2"
+	kutil "kmodules.xyz/client-go"
 )
 
 var (
@@ -52,11 +58,102 @@ func calculateDatabaseSize(body io.ReadCloser) (string, error) {
 		}
 	}
 
+	return diskUsageQuantity(totalDiskUsageInBytes), nil
+}
+
+func diskUsageQuantity(totalDiskUsageInBytes float64) string {
 	// Add extra 20% percent of extra storage for safety & taking metadata into account.
 	// convert bytes to Mib
 	totalDiskUsageInMi := int(totalDiskUsageInBytes * (1 + diskUsageSafetyFactor) / (1024 * 1024))
 	if totalDiskUsageInMi < diskUsageDefaultMi {
 		totalDiskUsageInMi = diskUsageDefaultMi
 	}
-	return fmt.Sprintf("%dMi", totalDiskUsageInMi), nil
+	return fmt.Sprintf("%dMi", totalDiskUsageInMi)
+}
+
+func closeBody(body io.ReadCloser) {
+	if err := body.Close(); err != nil {
+		klog.Errorf("failed to close response body, reason: %s", err)
+	}
+}
+
+// decodeResponse returns the error reported in body for an error status code,
+// else it decodes body into out, if out is not nil. action describes the
+// request in the errors, e.g. "create snapshot".
+func decodeResponse(statusCode int, body io.Reader, out interface{}, action string) error {
+	if statusCode >= http.StatusBadRequest {
+		return responseError(statusCode, body, action)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(body).Decode(out); err != nil {
+		return errors.Wrapf(err, "failed to decode response of %s request", action)
+	}
+	return nil
+}
+
+// responseError returns the error of a failed request. The error wraps
+// kutil.ErrNotFound for status code 404.
+func responseError(statusCode int, body io.Reader, action string) error {
+	var response struct {
+		Error json.RawMessage `json:"error"`
+	}
+	reason := http.StatusText(statusCode)
+	if err := json.NewDecoder(body).Decode(&response); err == nil && len(response.Error) > 0 {
+		var cause struct {
+			Type   string `json:"type"`
+			Reason string `json:"reason"`
+		}
+		if err := json.Unmarshal(response.Error, &cause); err == nil {
+			reason = fmt.Sprintf("%s: %s", cause.Type, cause.Reason)
+		} else {
+			_ = json.Unmarshal(response.Error, &reason)
+		}
+	}
+	if statusCode == http.StatusNotFound {
+		return fmt.Errorf("failed to %s: %w: %s", action, kutil.ErrNotFound, reason)
+	}
+	return dberrors.HTTPStatusError(statusCode, "failed to %s with status code %d: %s", action, statusCode, reason)
+}
+
+// performer sends requests through the transport of a versioned client. It is
+// used for the APIs that are missing in the versioned API packages.
+type performer interface {
+	Perform(req *http.Request) (*http.Response, error)
+}
+
+func performRequest(ctx context.Context, client performer, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		var err error
+		reader, err = jsonBody(body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req, err := http.NewRequestWithContext(ctx, method, path, reader)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	return client.Perform(req)
+}
+
+// performJSON sends body encoded as json and decodes the response into out,
+// if out is not nil.
+func performJSON(ctx context.Context, client performer, method, path string, body, out interface{}, action string) error {
+	res, err := performRequest(ctx, client, method, path, body)
+	if err != nil {
+		return errors.Wrapf(err, "failed to perform %s request", action)
+	}
+	defer closeBody(res.Body)
+
+	return decodeResponse(res.StatusCode, res.Body, out, action)
+}
+
+func isNotFound(err error) bool {
+	return errors.Is(err, kutil.ErrNotFound)
 }
@@ -17,6 +17,8 @@ limitations under the License.
 package elasticsearchdashboard
 
 import (
+	"io"
+
 	esapi "kubedb.dev/apimachinery/apis/elasticsearch/v1alpha1"
 )
 
@@ -25,6 +27,8 @@ type EDClient interface {
 	GetStateFromHealthResponse(health *Health) (esapi.DashboardServerState, error)
 	ExportSavedObjects(spaceName string) (*Response, error)
 	ImportSavedObjects(spaceName, filepath string) (*Response, error)
+	ExportSavedObjectsTo(spaceName string, w io.Writer, options ExportOptions) error
+	ImportSavedObjectsFrom(spaceName string, r io.Reader, options ImportOptions) (*ImportResult, error)
 	ListSpaces() ([]Space, error)
 	CreateSpace(space Space) error
 }
@@ -142,6 +142,17 @@ func (h *EDClientV8) ImportSavedObjects(spaceName, filepath string) (*Response,
 	}, nil
 }
 
+// ExportSavedObjectsTo writes the saved objects of the space to w as ndjson.
+func (h *EDClientV8) ExportSavedObjectsTo(spaceName string, w io.Writer, options ExportOptions) error {
+	return exportSavedObjects(h.Client, jsonHeaderForKibanaAPI, "/s/"+spaceName+SavedObjectsExportURL, SavedObjectsReqBodyES, w, options)
+}
+
+// ImportSavedObjectsFrom imports the ndjson saved objects read from r to the
+// space.
+func (h *EDClientV8) ImportSavedObjectsFrom(spaceName string, r io.Reader, options ImportOptions) (*ImportResult, error) {
+	return importSavedObjects(h.Client, "kbn-xsrf", "/s/"+spaceName+SavedObjectsImportURL, r, options)
+}
+
 func (h *EDClientV8) ListSpaces() ([]Space, error) {
 	req := h.Client.R().
 		SetDoNotParseResponse(true).
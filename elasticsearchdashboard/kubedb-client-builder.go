@@ -25,6 +25,10 @@ import (
 	"net/http"
 	"time"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/portforward"
+
 	catalog "kubedb.dev/apimachinery/apis/catalog/v1alpha1"
 	esapi "kubedb.dev/apimachinery/apis/elasticsearch/v1alpha1"
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
@@ -34,6 +38,7 @@ import (
 	"github.com/pkg/errors"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -46,8 +51,11 @@ type KubeDBClientBuilder struct {
 	dbVersionInfo *DbVersionInfo
 	authSecret    *core.Secret
 	url           string
+	resolver      *endpoint.Resolver
 	podName       string
 	ctx           context.Context
+	dialer        portforward.DialFunc
+	restConfig    *rest.Config
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *esapi.ElasticsearchDashboard) *KubeDBClientBuilder {
@@ -67,6 +75,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithAuthSecret(secret *core.Secret) *KubeDBClientBuilder {
 	o.authSecret = secret
 	return o
@@ -92,9 +107,24 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetElasticsearchDashboardClient() (*Client, error) {
 	config := Config{
-		host: getHostPath(o.dashboard),
+		host: o.getHostPath(),
 		api:  esapi.KibanaStatusEndpoint,
 		transport: &http.Transport{
 			IdleConnTimeout: time.Second * 3,
@@ -105,6 +135,14 @@ func (o *KubeDBClientBuilder) GetElasticsearchDashboardClient() (*Client, error)
 		connectionScheme: o.dashboard.GetConnectionScheme(),
 		dbVersionInfo:    o.getDbVersionInfo(),
 	}
+
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.dashboard.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		config.transport.DialContext = dial
+	}
 	// If EnableSSL is true set tls config,
 	// provide client certs and root CA
 	if o.dashboard.Spec.EnableSSL {
@@ -115,7 +153,7 @@ func (o *KubeDBClientBuilder) GetElasticsearchDashboardClient() (*Client, error)
 		}, &certSecret)
 		if err != nil {
 			klog.Error(err, "failed to get serverCert secret")
-			return nil, err
+			return nil, dberrors.SecretGetError(err)
 		}
 
 		// get tls cert, clientCA and rootCA for tls config
@@ -144,19 +182,22 @@ func (o *KubeDBClientBuilder) GetElasticsearchDashboardClient() (*Client, error)
 
 	// if security is enabled set database credentials in clientConfig
 	if !o.db.Spec.DisableSecurity {
+		if o.authSecret == nil {
+			return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "auth secret is not set")
+		}
 
 		if value, ok := o.authSecret.Data[core.BasicAuthUsernameKey]; ok {
 			username = string(value)
 		} else {
 			klog.Info(fmt.Sprintf("Failed for secret: %s/%s, username is missing", o.authSecret.Namespace, o.authSecret.Name))
-			return nil, errors.New("username is missing")
+			return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "username is missing")
 		}
 
 		if value, ok := o.authSecret.Data[core.BasicAuthPasswordKey]; ok {
 			password = string(value)
 		} else {
 			klog.Info(fmt.Sprintf("Failed for secret: %s/%s, password is missing", o.authSecret.Namespace, o.authSecret.Name))
-			return nil, errors.New("password is missing")
+			return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "password is missing")
 		}
 
 		config.username = username
@@ -214,7 +255,7 @@ func (o *KubeDBClientBuilder) GetElasticsearchDashboardClient() (*Client, error)
 		}, nil
 	}
 
-	return nil, fmt.Errorf("unknown version: %s", config.dbVersionInfo.Name)
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "unknown version: %s", config.dbVersionInfo.Name)
 }
 
 func (o *KubeDBClientBuilder) getDbVersionInfo() *DbVersionInfo {
@@ -231,6 +272,6 @@ func (o *KubeDBClientBuilder) getDbVersionInfo() *DbVersionInfo {
 
 // return host path in
 // format https://svc_name.namespace.svc:5601/api/status
-func getHostPath(dashboard *esapi.ElasticsearchDashboard) string {
-	return fmt.Sprintf("%v://%s.%s.svc:%d", dashboard.GetConnectionScheme(), dashboard.ServiceName(), dashboard.GetNamespace(), esapi.ElasticsearchDashboardRESTPort)
+func (o *KubeDBClientBuilder) getHostPath() string {
+	return o.resolver.ServiceURL(o.dashboard.GetConnectionScheme(), esapi.ResourceKindElasticsearchDashboard, o.dashboard.ServiceName(), o.dashboard.GetNamespace(), esapi.ElasticsearchDashboardRESTPort)
 }
@@ -145,6 +145,20 @@ func (h *OSClient) ImportSavedObjects(_, filepath string) (*Response, error) {
 	}, nil
 }
 
+// ExportSavedObjectsTo writes the saved objects to w as ndjson. OpenSearch
+// Dashboards has no spaces, so spaceName is ignored.
+func (h *OSClient) ExportSavedObjectsTo(_ string, w io.Writer, options ExportOptions) error {
+	headers := map[string]string{
+		"Content-Type": "application/json",
+		"osd-xsrf":     "true",
+	}
+	return exportSavedObjects(h.Client, headers, SavedObjectsExportURL, SavedObjectsReqBodyOS, w, options)
+}
+
+func (h *OSClient) ImportSavedObjectsFrom(_ string, r io.Reader, options ImportOptions) (*ImportResult, error) {
+	return importSavedObjects(h.Client, "osd-xsrf", SavedObjectsImportURL, r, options)
+}
+
 func (h *OSClient) ListSpaces() ([]Space, error) {
 	return []Space{{Id: "default"}}, nil
 }
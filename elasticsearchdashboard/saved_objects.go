@@ -0,0 +1,189 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package elasticsearchdashboard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/pkg/errors"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// ImportConflictFail reports the objects that already exist as conflict
+	// errors.
+	ImportConflictFail = ""
+	// ImportConflictOverwrite overwrites the objects that already exist.
+	ImportConflictOverwrite = "overwrite"
+	// ImportConflictCreateNewCopies imports all the objects with new ids, so
+	// no conflict is possible.
+	ImportConflictCreateNewCopies = "createNewCopies"
+)
+
+// savedObjectsFileName is the name of the imported file, which must have the
+// ndjson extension.
+const savedObjectsFileName = "export.ndjson"
+
+type SavedObjectRef struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+// ExportOptions filters the exported saved objects, either by Types or by
+// Objects. The default types of the dashboard are exported when both are
+// empty.
+type ExportOptions struct {
+	Types   []string
+	Objects []SavedObjectRef
+	// IncludeReferencesDeep also exports the objects referenced by the
+	// exported objects.
+	IncludeReferencesDeep bool
+	ExcludeExportDetails  bool
+}
+
+type ImportOptions struct {
+	// ConflictMode is one of the ImportConflict constants.
+	ConflictMode string
+}
+
+// ImportResult is the result of an import. Success is false when any object
+// failed, the failed objects are listed in Errors.
+type ImportResult struct {
+	Success        bool            `json:"success"`
+	SuccessCount   int             `json:"successCount"`
+	SuccessResults []ImportSuccess `json:"successResults,omitempty"`
+	Errors         []ImportError   `json:"errors,omitempty"`
+}
+
+type ImportSuccess struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+	// DestinationID is the new id of the object when it is imported as a new
+	// copy.
+	DestinationID string `json:"destinationId,omitempty"`
+	Meta          struct {
+		Title string `json:"title"`
+	} `json:"meta"`
+}
+
+type ImportError struct {
+	Type  string `json:"type"`
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Error struct {
+		// Type is the kind of the error, e.g. "conflict",
+		// "missing_references" or "unsupported_type".
+		Type       string           `json:"type"`
+		Message    string           `json:"message,omitempty"`
+		StatusCode int              `json:"statusCode,omitempty"`
+		References []SavedObjectRef `json:"references,omitempty"`
+	} `json:"error"`
+}
+
+type savedObjectsExportRequest struct {
+	Type                  []string         `json:"type,omitempty"`
+	Objects               []SavedObjectRef `json:"objects,omitempty"`
+	IncludeReferencesDeep bool             `json:"includeReferencesDeep,omitempty"`
+	ExcludeExportDetails  bool             `json:"excludeExportDetails,omitempty"`
+}
+
+// exportSavedObjects writes the saved objects exported from path to w as
+// ndjson. defaultBody is the request body with the default types.
+func exportSavedObjects(client *resty.Client, headers map[string]string, path, defaultBody string, w io.Writer, options ExportOptions) error {
+	if len(options.Types) > 0 && len(options.Objects) > 0 {
+		return errors.New("saved objects must be exported either by types or by objects")
+	}
+	body := savedObjectsExportRequest{
+		Type:                  options.Types,
+		Objects:               options.Objects,
+		IncludeReferencesDeep: options.IncludeReferencesDeep,
+		ExcludeExportDetails:  options.ExcludeExportDetails,
+	}
+	if len(body.Type) == 0 && len(body.Objects) == 0 {
+		if err := json.Unmarshal([]byte(defaultBody), &body); err != nil {
+			return errors.Wrap(err, "failed to parse default saved object types")
+		}
+	}
+
+	res, err := client.R().
+		SetDoNotParseResponse(true).
+		SetHeaders(headers).
+		SetBody(body).
+		Post(path)
+	if err != nil {
+		klog.Error(err, "Failed to send http request")
+		return err
+	}
+	defer closeBody(res.RawBody())
+
+	if res.StatusCode() != http.StatusOK {
+		msg, _ := io.ReadAll(res.RawBody())
+		return fmt.Errorf("failed to export saved objects %s", string(msg))
+	}
+	if _, err := io.Copy(w, res.RawBody()); err != nil {
+		return errors.Wrap(err, "failed to write exported saved objects")
+	}
+	return nil
+}
+
+// importSavedObjects imports the ndjson saved objects read from r to path.
+// xsrfHeader is the header required by the dashboard for the requests that
+// change state.
+func importSavedObjects(client *resty.Client, xsrfHeader, path string, r io.Reader, options ImportOptions) (*ImportResult, error) {
+	req := client.R().
+		SetDoNotParseResponse(true).
+		SetHeader(xsrfHeader, "true").
+		SetFileReader("file", savedObjectsFileName, r)
+	switch options.ConflictMode {
+	case ImportConflictFail:
+	case ImportConflictOverwrite, ImportConflictCreateNewCopies:
+		req.SetQueryParam(options.ConflictMode, strconv.FormatBool(true))
+	default:
+		return nil, errors.Errorf("invalid import conflict mode %q", options.ConflictMode)
+	}
+	res, err := req.Post(path)
+	if err != nil {
+		klog.Error(err, "Failed to send http request")
+		return nil, err
+	}
+	defer closeBody(res.RawBody())
+
+	body, err := io.ReadAll(res.RawBody())
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode() != http.StatusOK {
+		return nil, fmt.Errorf("failed to import saved objects %s", string(body))
+	}
+
+	var result ImportResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to parse import response")
+	}
+	return &result, nil
+}
+
+func closeBody(body io.ReadCloser) {
+	if err := body.Close(); err != nil {
+		klog.Errorf("failed to close response body, reason: %s", err)
+	}
+}
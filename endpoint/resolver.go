@@ -0,0 +1,153 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package endpoint builds the in-cluster addresses of the services and pods
+// of the KubeDB databases.
+package endpoint
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// DefaultClusterDomain is the default domain of the Kubernetes clusters.
+const DefaultClusterDomain = "cluster.local"
+
+// Resolver builds the DNS names and addresses of services and pods:
+//
+//	<service>.<namespace>.svc[.<cluster-domain>]
+//	<pod>[.<cluster-id>].<governing-service>.<namespace>.svc[.<cluster-domain>]
+//
+// A nil *Resolver is valid and uses the defaults, i.e. names end with ".svc"
+// and the default port of every database kind is used.
+type Resolver struct {
+	clusterDomain string
+	clusterID     string
+	ports         map[string]int
+}
+
+func NewResolver() *Resolver {
+	return &Resolver{
+		ports: map[string]int{},
+	}
+}
+
+// WithClusterDomain sets the domain appended to the ".svc" suffix, e.g.
+// "cluster.local", or "clusterset.local" to reach the services exported
+// from other clusters with multi-cluster DNS.
+func (r *Resolver) WithClusterDomain(domain string) *Resolver {
+	r.clusterDomain = strings.Trim(domain, ".")
+	return r
+}
+
+// WithClusterID sets the cluster ID used in the pod names of multi-cluster
+// DNS, so that the pods of a headless service in another cluster can be
+// reached.
+func (r *Resolver) WithClusterID(id string) *Resolver {
+	r.clusterID = id
+	return r
+}
+
+// WithPort overrides the default port of the database kind, e.g.
+// WithPort("MySQL", 13306).
+func (r *Resolver) WithPort(kind string, port int) *Resolver {
+	if r.ports == nil {
+		r.ports = map[string]int{}
+	}
+	r.ports[kind] = port
+	return r
+}
+
+// OrClusterDomain returns a copy of r that uses domain if the cluster domain
+// is not set, or r itself if it is. r is not modified.
+func (r *Resolver) OrClusterDomain(domain string) *Resolver {
+	if r != nil && r.clusterDomain != "" {
+		return r
+	}
+	c := &Resolver{}
+	if r != nil {
+		c.clusterID = r.clusterID
+		for kind, port := range r.ports {
+			c.WithPort(kind, port)
+		}
+	}
+	return c.WithClusterDomain(domain)
+}
+
+func (r *Resolver) suffix() string {
+	if r == nil || r.clusterDomain == "" {
+		return "svc"
+	}
+	return "svc." + r.clusterDomain
+}
+
+// Port returns the port of the database kind, port if it is not overridden.
+func (r *Resolver) Port(kind string, port int) int {
+	if r != nil {
+		if p, ok := r.ports[kind]; ok {
+			return p
+		}
+	}
+	return port
+}
+
+// ServiceHost returns the DNS name of a service.
+func (r *Resolver) ServiceHost(service, namespace string) string {
+	return fmt.Sprintf("%s.%s.%s", service, namespace, r.suffix())
+}
+
+// PodHost returns the DNS name of a pod of a governing service.
+func (r *Resolver) PodHost(pod, governingService, namespace string) string {
+	if r != nil && r.clusterID != "" {
+		return fmt.Sprintf("%s.%s.%s.%s.%s", pod, r.clusterID, governingService, namespace, r.suffix())
+	}
+	return fmt.Sprintf("%s.%s.%s.%s", pod, governingService, namespace, r.suffix())
+}
+
+// ServiceAddress returns the host:port address of a service. port is the
+// default port of the database kind.
+func (r *Resolver) ServiceAddress(kind, service, namespace string, port int) string {
+	return JoinHostPort(r.ServiceHost(service, namespace), r.Port(kind, port))
+}
+
+// PodAddress returns the host:port address of a pod. port is the default
+// port of the database kind.
+func (r *Resolver) PodAddress(kind, pod, governingService, namespace string, port int) string {
+	return JoinHostPort(r.PodHost(pod, governingService, namespace), r.Port(kind, port))
+}
+
+// ServiceURL returns the scheme://host:port URL of a service.
+func (r *Resolver) ServiceURL(scheme, kind, service, namespace string, port int) string {
+	return URL(scheme, r.ServiceAddress(kind, service, namespace, port))
+}
+
+// PodURL returns the scheme://host:port URL of a pod.
+func (r *Resolver) PodURL(scheme, kind, pod, governingService, namespace string, port int) string {
+	return URL(scheme, r.PodAddress(kind, pod, governingService, namespace, port))
+}
+
+// JoinHostPort combines host and port into an address, enclosing IPv6
+// literals in brackets.
+func JoinHostPort(host string, port int) string {
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
+
+// URL returns scheme://address.
+func URL(scheme, address string) string {
+	return fmt.Sprintf("%s://%s", scheme, address)
+}
@@ -0,0 +1,146 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpoint
+
+import (
+	"testing"
+)
+
+func TestResolver(t *testing.T) {
+	tests := []struct {
+		name        string
+		resolver    *Resolver
+		serviceAddr string
+		podAddr     string
+		serviceURL  string
+	}{
+		{
+			name:        "nil",
+			serviceAddr: "mysql.demo.svc:3306",
+			podAddr:     "mysql-0.mysql-pods.demo.svc:3306",
+			serviceURL:  "tcp://mysql.demo.svc:3306",
+		},
+		{
+			name:        "defaults",
+			resolver:    NewResolver(),
+			serviceAddr: "mysql.demo.svc:3306",
+			podAddr:     "mysql-0.mysql-pods.demo.svc:3306",
+			serviceURL:  "tcp://mysql.demo.svc:3306",
+		},
+		{
+			name:        "cluster domain",
+			resolver:    NewResolver().WithClusterDomain(".cluster.local."),
+			serviceAddr: "mysql.demo.svc.cluster.local:3306",
+			podAddr:     "mysql-0.mysql-pods.demo.svc.cluster.local:3306",
+			serviceURL:  "tcp://mysql.demo.svc.cluster.local:3306",
+		},
+		{
+			name:        "cluster id",
+			resolver:    NewResolver().WithClusterDomain("clusterset.local").WithClusterID("west"),
+			serviceAddr: "mysql.demo.svc.clusterset.local:3306",
+			podAddr:     "mysql-0.west.mysql-pods.demo.svc.clusterset.local:3306",
+			serviceURL:  "tcp://mysql.demo.svc.clusterset.local:3306",
+		},
+		{
+			name:        "port",
+			resolver:    NewResolver().WithPort("MySQL", 13306).WithPort("Redis", 16379),
+			serviceAddr: "mysql.demo.svc:13306",
+			podAddr:     "mysql-0.mysql-pods.demo.svc:13306",
+			serviceURL:  "tcp://mysql.demo.svc:13306",
+		},
+		{
+			name:        "port of zero value",
+			resolver:    (&Resolver{}).WithPort("MySQL", 13306),
+			serviceAddr: "mysql.demo.svc:13306",
+			podAddr:     "mysql-0.mysql-pods.demo.svc:13306",
+			serviceURL:  "tcp://mysql.demo.svc:13306",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.resolver.ServiceAddress("MySQL", "mysql", "demo", 3306); got != tt.serviceAddr {
+				t.Errorf("got service address %q, want %q", got, tt.serviceAddr)
+			}
+			if got := tt.resolver.PodAddress("MySQL", "mysql-0", "mysql-pods", "demo", 3306); got != tt.podAddr {
+				t.Errorf("got pod address %q, want %q", got, tt.podAddr)
+			}
+			if got := tt.resolver.ServiceURL("tcp", "MySQL", "mysql", "demo", 3306); got != tt.serviceURL {
+				t.Errorf("got service url %q, want %q", got, tt.serviceURL)
+			}
+		})
+	}
+}
+
+func TestResolverOrClusterDomain(t *testing.T) {
+	tests := []struct {
+		name        string
+		resolver    *Resolver
+		serviceAddr string
+		podAddr     string
+	}{
+		{
+			name:        "nil",
+			serviceAddr: "solr.demo.svc.cluster.local:8983",
+			podAddr:     "solr-0.solr-pods.demo.svc.cluster.local:8983",
+		},
+		{
+			name:        "cluster id and port",
+			resolver:    NewResolver().WithClusterID("west").WithPort("Solr", 18983),
+			serviceAddr: "solr.demo.svc.cluster.local:18983",
+			podAddr:     "solr-0.west.solr-pods.demo.svc.cluster.local:18983",
+		},
+		{
+			name:        "cluster domain",
+			resolver:    NewResolver().WithClusterDomain("clusterset.local"),
+			serviceAddr: "solr.demo.svc.clusterset.local:8983",
+			podAddr:     "solr-0.solr-pods.demo.svc.clusterset.local:8983",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := tt.resolver.OrClusterDomain(DefaultClusterDomain)
+			if got := r.ServiceAddress("Solr", "solr", "demo", 8983); got != tt.serviceAddr {
+				t.Errorf("got service address %q, want %q", got, tt.serviceAddr)
+			}
+			if got := r.PodAddress("Solr", "solr-0", "solr-pods", "demo", 8983); got != tt.podAddr {
+				t.Errorf("got pod address %q, want %q", got, tt.podAddr)
+			}
+		})
+	}
+
+	r := NewResolver()
+	r.OrClusterDomain(DefaultClusterDomain).WithPort("Solr", 18983)
+	if got := r.ServiceHost("solr", "demo"); got != "solr.demo.svc" {
+		t.Errorf("resolver is modified, got service host %q", got)
+	}
+	if got := r.Port("Solr", 8983); got != 8983 {
+		t.Errorf("resolver ports are modified, got port %d", got)
+	}
+}
+
+func TestJoinHostPort(t *testing.T) {
+	tests := map[string]string{
+		"10.0.0.1":       "10.0.0.1:6379",
+		"fd00::1":        "[fd00::1]:6379",
+		"redis.demo.svc": "redis.demo.svc:6379",
+	}
+	for host, want := range tests {
+		if got := JoinHostPort(host, 6379); got != want {
+			t.Errorf("JoinHostPort(%q) = %q, want %q", host, got, want)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dsn
+
+import (
+	"database/sql"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	sql_driver "github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+	"github.com/microsoft/go-mssqldb/msdsn"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+	"xorm.io/xorm"
+	xormcore "xorm.io/xorm/core"
+)
+
+// hostile are credentials with the characters that have a meaning in any of
+// the DSN and URL formats.
+var hostile = []string{
+	"plain",
+	"it's",
+	`say "hi"`,
+	`back\slash`,
+	`trailing\`,
+	"user@host",
+	"a:b",
+	"a/b",
+	"a?b",
+	"a#b",
+	"100%",
+	"%41",
+	"with space",
+	" leading",
+	"a=b",
+	`all' " \ @ : / ? # % =`,
+}
+
+// The user of a go-sql-driver DSN ends at the first colon, so only the
+// password is hostile.
+func TestMySQL(t *testing.T) {
+	for _, cred := range hostile {
+		t.Run(cred, func(t *testing.T) {
+			cfg, err := sql_driver.ParseDSN(MySQL("root", cred, "tcp", "mysql.demo.svc:3306", "mysql", ""))
+			if err != nil {
+				t.Fatalf("failed to parse dsn: %v", err)
+			}
+			if cfg.User != "root" || cfg.Passwd != cred {
+				t.Errorf("got user %q and password %q, want root and %q", cfg.User, cfg.Passwd, cred)
+			}
+			if cfg.Addr != "mysql.demo.svc:3306" || cfg.DBName != "mysql" {
+				t.Errorf("got addr %q and db %q", cfg.Addr, cfg.DBName)
+			}
+		})
+	}
+}
+
+// postgresOpts returns the options parsed by lib/pq, which are not exported.
+func postgresOpts(t *testing.T, dsn string) map[string]string {
+	t.Helper()
+	c, err := pq.NewConnector(dsn)
+	if err != nil {
+		t.Fatalf("failed to parse dsn: %v", err)
+	}
+	opts := map[string]string{}
+	v := reflect.ValueOf(c).Elem().FieldByName("opts")
+	for _, key := range v.MapKeys() {
+		opts[key.String()] = v.MapIndex(key).String()
+	}
+	return opts
+}
+
+func TestFormatPostgres(t *testing.T) {
+	for _, cred := range append(hostile, "") {
+		t.Run(cred, func(t *testing.T) {
+			opts := postgresOpts(t, FormatPostgres(
+				PostgresParam{Key: "user", Value: cred},
+				PostgresParam{Key: "password", Value: cred},
+				PostgresParam{Key: "host", Value: "postgres.demo.svc"},
+				PostgresParam{Key: "dbname", Value: "postgres"},
+			))
+			if opts["user"] != cred || opts["password"] != cred {
+				t.Errorf("got user %q and password %q, want %q", opts["user"], opts["password"], cred)
+			}
+			if opts["host"] != "postgres.demo.svc" || opts["dbname"] != "postgres" {
+				t.Errorf("got host %q and dbname %q", opts["host"], opts["dbname"])
+			}
+		})
+	}
+}
+
+func TestXormPostgres(t *testing.T) {
+	for _, cred := range hostile {
+		t.Run(cred, func(t *testing.T) {
+			db, err := sql.Open("postgres", FormatPostgres(
+				PostgresParam{Key: "user", Value: cred},
+				PostgresParam{Key: "password", Value: cred},
+				PostgresParam{Key: "dbname", Value: "postgres"},
+			))
+			if err != nil {
+				t.Fatalf("failed to open db: %v", err)
+			}
+			defer db.Close()
+
+			engine, err := xorm.NewEngineWithDB("postgres", XormPostgres("postgres"), xormcore.FromDB(db))
+			if err != nil {
+				t.Fatalf("failed to create engine: %v", err)
+			}
+			if name := engine.Dialect().URI().DBName; name != "postgres" {
+				t.Errorf("got dbname %q, want postgres", name)
+			}
+		})
+	}
+}
+
+func TestURLSQLServer(t *testing.T) {
+	params := url.Values{}
+	params.Set("database", "master")
+	for _, cred := range hostile {
+		t.Run(cred, func(t *testing.T) {
+			cfg, err := msdsn.Parse(URL("sqlserver", cred, cred, "mssql.demo.svc:1433", "", params))
+			if err != nil {
+				t.Fatalf("failed to parse dsn: %v", err)
+			}
+			if cfg.User != cred || cfg.Password != cred {
+				t.Errorf("got user %q and password %q, want %q", cfg.User, cfg.Password, cred)
+			}
+			if cfg.Host != "mssql.demo.svc" || cfg.Database != "master" {
+				t.Errorf("got host %q and database %q", cfg.Host, cfg.Database)
+			}
+		})
+	}
+}
+
+func TestURLMongoDB(t *testing.T) {
+	params := url.Values{}
+	params.Set("authSource", "admin")
+	for _, cred := range hostile {
+		t.Run(cred, func(t *testing.T) {
+			cs, err := connstring.ParseAndValidate(URL("mongodb", cred, cred, "mongo.demo.svc:27017", "/admin", params))
+			if err != nil {
+				t.Fatalf("failed to parse uri: %v", err)
+			}
+			if cs.Username != cred || cs.Password != cred {
+				t.Errorf("got user %q and password %q, want %q", cs.Username, cs.Password, cred)
+			}
+			if len(cs.Hosts) != 1 || cs.Hosts[0] != "mongo.demo.svc:27017" || cs.AuthSource != "admin" {
+				t.Errorf("got hosts %v and auth source %q", cs.Hosts, cs.AuthSource)
+			}
+		})
+	}
+}
+
+func TestURLAMQP(t *testing.T) {
+	for _, cred := range hostile {
+		t.Run(cred, func(t *testing.T) {
+			uri, err := amqp.ParseURI(URL("amqp", cred, cred, "rabbitmq.demo.svc:5672", "/", nil))
+			if err != nil {
+				t.Fatalf("failed to parse uri: %v", err)
+			}
+			if uri.Username != cred || uri.Password != cred {
+				t.Errorf("got user %q and password %q, want %q", uri.Username, uri.Password, cred)
+			}
+			if uri.Host != "rabbitmq.demo.svc" || uri.Port != 5672 || uri.Vhost != "/" {
+				t.Errorf("got host %q, port %d and vhost %q", uri.Host, uri.Port, uri.Vhost)
+			}
+		})
+	}
+}
+
+func TestClickHouse(t *testing.T) {
+	for _, cred := range hostile {
+		t.Run(cred, func(t *testing.T) {
+			opts, err := clickhouse.ParseDSN(ClickHouse(cred, cred, "clickhouse.demo.svc:9000"))
+			if err != nil {
+				t.Fatalf("failed to parse dsn: %v", err)
+			}
+			if opts.Auth.Username != cred || opts.Auth.Password != cred {
+				t.Errorf("got user %q and password %q, want %q", opts.Auth.Username, opts.Auth.Password, cred)
+			}
+			if len(opts.Addr) != 1 || opts.Addr[0] != "clickhouse.demo.svc:9000" {
+				t.Errorf("got addr %v", opts.Addr)
+			}
+		})
+	}
+}
+
+func TestMySQLTLSConfigKey(t *testing.T) {
+	a := MySQLTLSConfigKey("custom", "demo", "mysql")
+	b := MySQLTLSConfigKey("custom", "demo", "mysql")
+	if a == b {
+		t.Errorf("got the same key %q for two builds", a)
+	}
+	if !strings.HasPrefix(a, "custom/demo/mysql/") {
+		t.Errorf("got key %q, want prefix custom/demo/mysql/", a)
+	}
+}
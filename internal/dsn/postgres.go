@@ -0,0 +1,227 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dsn
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lib/pq"
+	core "k8s.io/api/core/v1"
+	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
+)
+
+// PostgresParam is a single keyword/value pair of a libpq connection string.
+type PostgresParam struct {
+	Key   string
+	Value string
+}
+
+var postgresValueEscaper = strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+
+// FormatPostgres builds a keyword/value connection string understood by libpq
+// and github.com/lib/pq. Values containing whitespace, quotes or backslashes
+// are single quoted and escaped, so credentials can be passed as is.
+func FormatPostgres(params ...PostgresParam) string {
+	parts := make([]string, 0, len(params))
+	for _, p := range params {
+		parts = append(parts, p.Key+"="+quotePostgresValue(p.Value))
+	}
+	return strings.Join(parts, " ")
+}
+
+func quotePostgresValue(v string) string {
+	if v == "" {
+		return "''"
+	}
+	if !strings.ContainsAny(v, " \t\n\v\f\r'\\") {
+		return v
+	}
+	return "'" + postgresValueEscaper.Replace(v) + "'"
+}
+
+// XormPostgres returns the data source name of the xorm postgres dialect,
+// which only reads the database name from it. The xorm parser does not
+// accept the escaped values of FormatPostgres, so the connections must be
+// opened by lib/pq and passed to xorm.NewEngineWithDB.
+func XormPostgres(dbname string) string {
+	return (&url.URL{Scheme: "postgres", Path: "/" + dbname}).String()
+}
+
+// PostgresTLS returns the tls.Config of the certificate secret for the
+// sslmode and host of params, and params with sslmode=disable. lib/pq only
+// reads the certificates from files, or inline when a client certificate is
+// present, so the connections are upgraded by OpenPostgres instead. The
+// client certificate is only sent when clientCert is true. A nil config is
+// returned when sslmode is disable.
+func PostgresTLS(secret *core.Secret, params []PostgresParam, clientCert bool) ([]PostgresParam, *tls.Config, error) {
+	var sslMode, host string
+	for _, p := range params {
+		switch p.Key {
+		case "sslmode":
+			sslMode = p.Value
+		case "host":
+			host = p.Value
+		}
+	}
+	if sslMode == "disable" {
+		return params, nil, nil
+	}
+
+	ca, ok := secret.Data[dbapi.TLSCACertFileName]
+	if !ok {
+		return nil, nil, fmt.Errorf("%s is not present in secret %s/%s", dbapi.TLSCACertFileName, secret.Namespace, secret.Name)
+	}
+	roots := x509.NewCertPool()
+	if !roots.AppendCertsFromPEM(ca) {
+		return nil, nil, fmt.Errorf("failed to parse %s of secret %s/%s", dbapi.TLSCACertFileName, secret.Namespace, secret.Name)
+	}
+	config := &tls.Config{
+		ServerName:    host,
+		Renegotiation: tls.RenegotiateFreelyAsClient,
+	}
+	switch sslMode {
+	case "", "require", "verify-ca":
+		// like lib/pq, require verifies the chain when the CA is known, and
+		// verify-ca does not check the host name
+		config.InsecureSkipVerify = true
+		config.VerifyConnection = func(cs tls.ConnectionState) error {
+			if len(cs.PeerCertificates) == 0 {
+				return errors.New("server did not send a certificate")
+			}
+			opts := x509.VerifyOptions{
+				Roots:         roots,
+				Intermediates: x509.NewCertPool(),
+			}
+			for _, cert := range cs.PeerCertificates[1:] {
+				opts.Intermediates.AddCert(cert)
+			}
+			_, err := cs.PeerCertificates[0].Verify(opts)
+			return err
+		}
+	case "verify-full":
+		config.RootCAs = roots
+	default:
+		return nil, nil, fmt.Errorf("unsupported sslmode %q", sslMode)
+	}
+	if clientCert {
+		cert, err := tls.X509KeyPair(secret.Data[core.TLSCertKey], secret.Data[core.TLSPrivateKeyKey])
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load client certificate of secret %s/%s: %w", secret.Namespace, secret.Name, err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	out := make([]PostgresParam, 0, len(params))
+	for _, p := range params {
+		if p.Key == "sslmode" {
+			p.Value = "disable"
+		}
+		out = append(out, p)
+	}
+	return out, config, nil
+}
+
+// OpenPostgres opens the database with lib/pq. The connections are opened by
+// dial when it is not nil, and upgraded with config when it is not nil.
+func OpenPostgres(connector string, dial func(ctx context.Context, network, address string) (net.Conn, error), config *tls.Config) (*sql.DB, error) {
+	if dial == nil && config == nil {
+		return sql.Open("postgres", connector)
+	}
+	c, err := pq.NewConnector(connector)
+	if err != nil {
+		return nil, err
+	}
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	c.Dialer(postgresDialer{dial: dial, config: config})
+	return sql.OpenDB(c), nil
+}
+
+// sslRequestCode is the code of the SSLRequest message of the postgres
+// protocol.
+const sslRequestCode = 80877103
+
+// postgresDialer opens the connections of lib/pq with dial, and negotiates
+// TLS when config is not nil.
+type postgresDialer struct {
+	dial   func(ctx context.Context, network, address string) (net.Conn, error)
+	config *tls.Config
+}
+
+func (d postgresDialer) Dial(network, address string) (net.Conn, error) {
+	return d.DialContext(context.Background(), network, address)
+}
+
+func (d postgresDialer) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d.DialContext(ctx, network, address)
+}
+
+func (d postgresDialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := d.dial(ctx, network, address)
+	if err != nil || d.config == nil {
+		return conn, err
+	}
+	tlsConn, err := d.upgrade(ctx, conn)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return tlsConn, nil
+}
+
+// upgrade sends an SSLRequest and performs the TLS handshake when the server
+// accepts it.
+func (d postgresDialer) upgrade(ctx context.Context, conn net.Conn) (net.Conn, error) {
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+		defer func() { _ = conn.SetDeadline(time.Time{}) }()
+	}
+	request := make([]byte, 8)
+	binary.BigEndian.PutUint32(request[0:4], 8)
+	binary.BigEndian.PutUint32(request[4:8], sslRequestCode)
+	if _, err := conn.Write(request); err != nil {
+		return nil, err
+	}
+	response := make([]byte, 1)
+	if _, err := io.ReadFull(conn, response); err != nil {
+		return nil, err
+	}
+	if response[0] != 'S' {
+		return nil, errors.New("pq: SSL is not enabled on the server")
+	}
+	tlsConn := tls.Client(conn, d.config)
+	if err := tlsConn.HandshakeContext(ctx); err != nil {
+		return nil, err
+	}
+	return tlsConn, nil
+}
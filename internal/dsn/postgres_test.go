@@ -0,0 +1,265 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dsn
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/binary"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// newTestCert returns a certificate signed by parent, or a self signed CA
+// when parent is nil.
+func newTestCert(t *testing.T, parent *testCert, hosts ...string) *testCert {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "postgres"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		keyPEM:  pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}),
+	}
+}
+
+// servePostgres accepts the connections of a postgres server that requires
+// TLS, and the client certificate when clientCert is true, and accepts every
+// startup message.
+func servePostgres(t *testing.T, ca, server *testCert, clientCert bool) string {
+	t.Helper()
+	cert, err := tls.X509KeyPair(server.certPEM, server.keyPEM)
+	if err != nil {
+		t.Fatalf("failed to load server certificate: %v", err)
+	}
+	config := &tls.Config{Certificates: []tls.Certificate{cert}}
+	if clientCert {
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+		config.ClientCAs = x509.NewCertPool()
+		config.ClientCAs.AddCert(ca.cert)
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	t.Cleanup(func() { _ = l.Close() })
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				request := make([]byte, 8)
+				if _, err := io.ReadFull(conn, request); err != nil || binary.BigEndian.Uint32(request[4:]) != sslRequestCode {
+					return
+				}
+				if _, err := conn.Write([]byte{'S'}); err != nil {
+					return
+				}
+				tlsConn := tls.Server(conn, config)
+				header := make([]byte, 4)
+				if _, err := io.ReadFull(tlsConn, header); err != nil {
+					return
+				}
+				if _, err := io.CopyN(io.Discard, tlsConn, int64(binary.BigEndian.Uint32(header)-4)); err != nil {
+					return
+				}
+				// AuthenticationOk and ReadyForQuery
+				_, _ = tlsConn.Write([]byte{'R', 0, 0, 0, 8, 0, 0, 0, 0, 'Z', 0, 0, 0, 5, 'I'})
+				_, _ = io.Copy(io.Discard, tlsConn)
+			}()
+		}
+	}()
+	return l.Addr().String()
+}
+
+func TestOpenPostgresTLS(t *testing.T) {
+	ca := newTestCert(t, nil)
+	server := newTestCert(t, ca, "127.0.0.1")
+	client := newTestCert(t, ca)
+	otherCA := newTestCert(t, nil)
+	secret := func(ca *testCert) *core.Secret {
+		return &core.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "postgres-client-cert"},
+			Data: map[string][]byte{
+				"ca.crt":              ca.certPEM,
+				core.TLSCertKey:       client.certPEM,
+				core.TLSPrivateKeyKey: client.keyPEM,
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		sslMode    string
+		host       string
+		ca         *testCert
+		clientCert bool
+		wantErr    bool
+	}{
+		{name: "require", sslMode: "require", ca: ca},
+		{name: "require with client certificate", sslMode: "require", ca: ca, clientCert: true},
+		{name: "verify-ca with another host", sslMode: "verify-ca", host: "localhost", ca: ca},
+		{name: "verify-full", sslMode: "verify-full", ca: ca, clientCert: true},
+		{name: "require with unknown ca", sslMode: "require", ca: otherCA, wantErr: true},
+		{name: "verify-full with another host", sslMode: "verify-full", host: "localhost", ca: ca, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			addr := servePostgres(t, ca, server, tt.clientCert)
+			host, port, _ := net.SplitHostPort(addr)
+			dialHost := host
+			if tt.host != "" {
+				host = tt.host
+			}
+			params := []PostgresParam{
+				{Key: "user", Value: "postgres"},
+				{Key: "password", Value: "pass"},
+				{Key: "host", Value: host},
+				{Key: "port", Value: port},
+				{Key: "connect_timeout", Value: "5"},
+				{Key: "dbname", Value: "postgres"},
+				{Key: "sslmode", Value: tt.sslMode},
+			}
+			params, config, err := PostgresTLS(secret(tt.ca), params, tt.clientCert)
+			if err != nil {
+				t.Fatalf("failed to build tls config: %v", err)
+			}
+			dial := func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, net.JoinHostPort(dialHost, port))
+			}
+			db, err := OpenPostgres(FormatPostgres(params...), dial, config)
+			if err != nil {
+				t.Fatalf("failed to open database: %v", err)
+			}
+			defer db.Close()
+
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			defer cancel()
+			conn, err := db.Conn(ctx)
+			if tt.wantErr {
+				if err == nil {
+					_ = conn.Close()
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("failed to connect: %v", err)
+			}
+			_ = conn.Close()
+		})
+	}
+}
+
+func TestPostgresTLS(t *testing.T) {
+	ca := newTestCert(t, nil)
+	secret := &core.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "postgres-client-cert"},
+		Data:       map[string][]byte{"ca.crt": ca.certPEM},
+	}
+	params := []PostgresParam{{Key: "host", Value: "postgres.demo.svc"}, {Key: "sslmode", Value: "disable"}}
+	got, config, err := PostgresTLS(secret, params, false)
+	if err != nil || config != nil || got[1].Value != "disable" {
+		t.Errorf("got params %v, config %v and error %v for sslmode disable", got, config, err)
+	}
+
+	params[1].Value = "verify-full"
+	got, config, err = PostgresTLS(secret, params, false)
+	if err != nil {
+		t.Fatalf("failed to build tls config: %v", err)
+	}
+	if config == nil || config.ServerName != "postgres.demo.svc" || config.InsecureSkipVerify {
+		t.Errorf("got config %+v", config)
+	}
+	if got[1].Value != "disable" || params[1].Value != "verify-full" {
+		t.Errorf("got params %v, want sslmode disable without changing the input", got)
+	}
+
+	if _, _, err := PostgresTLS(secret, params, true); err == nil {
+		t.Error("expected an error for a secret without the client certificate")
+	}
+	params[1].Value = "prefer"
+	if _, _, err := PostgresTLS(secret, params, false); err == nil {
+		t.Error("expected an error for an unsupported sslmode")
+	}
+	delete(secret.Data, "ca.crt")
+	params[1].Value = "require"
+	if _, _, err := PostgresTLS(secret, params, false); err == nil {
+		t.Error("expected an error for a secret without ca.crt")
+	}
+}
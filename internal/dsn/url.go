@@ -0,0 +1,75 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dsn
+
+import (
+	"fmt"
+	"net/url"
+	"sync/atomic"
+
+	sql_driver "github.com/go-sql-driver/mysql"
+)
+
+// URL builds a connection URI with the credentials in the user info, e.g. for
+// mongodb, sqlserver and amqp. All the parts are escaped.
+func URL(scheme, user, password, host, path string, query url.Values) string {
+	u := url.URL{
+		Scheme:   scheme,
+		User:     url.UserPassword(user, password),
+		Host:     host,
+		Path:     path,
+		RawQuery: query.Encode(),
+	}
+	return u.String()
+}
+
+// ClickHouse builds a clickhouse-go DSN, which takes the credentials from the
+// query parameters.
+func ClickHouse(user, password, host string) string {
+	params := url.Values{}
+	params.Set("username", user)
+	params.Set("password", password)
+	u := url.URL{
+		Scheme:   "clickhouse",
+		Host:     host,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// MySQL builds a go-sql-driver DSN. network is "tcp" or the name of a
+// registered dialer, tlsConfig is the name of a registered TLS config or
+// empty. The password is passed as is, but user must not contain a colon.
+func MySQL(user, password, network, addr, dbName, tlsConfig string) string {
+	cfg := sql_driver.NewConfig()
+	cfg.User = user
+	cfg.Passwd = password
+	cfg.Net = network
+	cfg.Addr = addr
+	cfg.DBName = dbName
+	cfg.TLSConfig = tlsConfig
+	return cfg.FormatDSN()
+}
+
+var mysqlTLSConfigSeq atomic.Uint64
+
+// MySQLTLSConfigKey returns a go-sql-driver TLS config name unique to the
+// database and to the build, so that concurrent builds don't deregister each
+// other's config.
+func MySQLTLSConfigKey(prefix, namespace, name string) string {
+	return fmt.Sprintf("%s/%s/%s/%d", prefix, namespace, name, mysqlTLSConfigSeq.Add(1))
+}
@@ -0,0 +1,148 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/klog/v2"
+)
+
+const instrumentationName = "kubedb.dev/db-client-go"
+
+// operations recorded by the client builders and helpers
+const (
+	OpSecretFetch = "secret.fetch"
+	OpTLSLoad     = "tls.load"
+	OpDial        = "dial"
+	OpPing        = "ping"
+)
+
+const (
+	KeyKind      = attribute.Key("kubedb.kind")
+	KeyNamespace = attribute.Key("kubedb.namespace")
+	KeyName      = attribute.Key("kubedb.name")
+	KeyPod       = attribute.Key("kubedb.pod")
+	KeyOperation = attribute.Key("kubedb.operation")
+)
+
+// Telemetry records a span, the latency and the errors of client operations.
+// A nil *Telemetry is valid and records nothing.
+type Telemetry struct {
+	tracer   trace.Tracer
+	duration metric.Float64Histogram
+	errors   metric.Int64Counter
+	attrs    []attribute.KeyValue
+}
+
+// New returns a Telemetry for the given providers. Either of them can be nil,
+// and nil is returned when both are nil.
+func New(tp trace.TracerProvider, mp metric.MeterProvider, attrs ...attribute.KeyValue) *Telemetry {
+	if tp == nil && mp == nil {
+		return nil
+	}
+
+	t := &Telemetry{
+		attrs: attrs,
+	}
+	if tp != nil {
+		t.tracer = tp.Tracer(instrumentationName)
+	}
+	if mp != nil {
+		meter := mp.Meter(instrumentationName)
+		var err error
+		t.duration, err = meter.Float64Histogram("kubedb.client.operation.duration",
+			metric.WithUnit("s"),
+			metric.WithDescription("Duration of database client operations."),
+		)
+		if err != nil {
+			klog.Errorf("Failed to create operation duration histogram. error: %v", err)
+		}
+		t.errors, err = meter.Int64Counter("kubedb.client.operation.errors",
+			metric.WithUnit("{error}"),
+			metric.WithDescription("Number of failed database client operations."),
+		)
+		if err != nil {
+			klog.Errorf("Failed to create operation error counter. error: %v", err)
+		}
+	}
+	return t
+}
+
+// Attributes returns the common attributes of a database. pod is omitted when empty.
+func Attributes(kind, namespace, name, pod string) []attribute.KeyValue {
+	attrs := []attribute.KeyValue{
+		KeyKind.String(kind),
+		KeyNamespace.String(namespace),
+		KeyName.String(name),
+	}
+	if pod != "" {
+		attrs = append(attrs, KeyPod.String(pod))
+	}
+	return attrs
+}
+
+// Start starts the operation op. The returned function must be called with
+// the result of the operation to end it.
+func (t *Telemetry) Start(ctx context.Context, op string, attrs ...attribute.KeyValue) (context.Context, func(err error)) {
+	if t == nil {
+		return ctx, func(error) {}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	all := make([]attribute.KeyValue, 0, len(t.attrs)+len(attrs)+1)
+	all = append(all, t.attrs...)
+	all = append(all, attrs...)
+	all = append(all, KeyOperation.String(op))
+
+	var span trace.Span
+	if t.tracer != nil {
+		ctx, span = t.tracer.Start(ctx, op, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(all...))
+	}
+	start := time.Now()
+
+	return ctx, func(err error) {
+		if t.duration != nil {
+			t.duration.Record(ctx, time.Since(start).Seconds(), metric.WithAttributes(all...))
+		}
+		if err != nil && t.errors != nil {
+			t.errors.Add(ctx, 1, metric.WithAttributes(all...))
+		}
+		if span != nil {
+			if err != nil {
+				span.RecordError(err)
+				span.SetStatus(codes.Error, err.Error())
+			}
+			span.End()
+		}
+	}
+}
+
+// Run runs fn as the operation op and returns its error.
+func (t *Telemetry) Run(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	ctx, end := t.Start(ctx, op)
+	err := fn(ctx)
+	end(err)
+	return err
+}
@@ -17,28 +17,36 @@ limitations under the License.
 package kafka
 
 import (
+	"context"
 	"time"
 
 	"fmt"
 
+	"kubedb.dev/db-client-go/internal/telemetry"
+
 	kafkago "github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/klog/v2"
 )
 
 type Client struct {
 	kafkago.Client
+	telemetry *telemetry.Telemetry
 }
 
 type ProducerClient struct {
 	kafkago.SyncProducer
+	telemetry *telemetry.Telemetry
 }
 
 type AdminClient struct {
 	kafkago.ClusterAdmin
+	telemetry *telemetry.Telemetry
 }
 
 type ConsumerClient struct {
 	kafkago.Consumer
+	telemetry *telemetry.Telemetry
 }
 
 type MessageMetadata struct {
@@ -154,6 +162,13 @@ func (c *Client) DeleteKafkaTopics(topics ...string) {
 }
 
 func (p *ProducerClient) PublishMessages(partition int32, topic, key, message string) (*MessageMetadata, error) {
+	_, end := p.telemetry.Start(context.Background(), "publish", messagingAttributes(topic, partition)...)
+	msgMetadata, err := p.publishMessages(partition, topic, key, message)
+	end(err)
+	return msgMetadata, err
+}
+
+func (p *ProducerClient) publishMessages(partition int32, topic, key, message string) (*MessageMetadata, error) {
 	producerMsg := &kafkago.ProducerMessage{
 		Topic:     topic,
 		Key:       kafkago.StringEncoder(key),
@@ -174,7 +189,16 @@ func (p *ProducerClient) PublishMessages(partition int32, topic, key, message st
 	return &msgMetadata, nil
 }
 
+// ConsumeMessages sends the consumed messages to message until signal is
+// received. The whole call is recorded as a single consume operation.
 func (c *ConsumerClient) ConsumeMessages(partition int32, topic string, offset int64, signal *chan bool, message *chan MessageMetadata) error {
+	_, end := c.telemetry.Start(context.Background(), "consume", messagingAttributes(topic, partition)...)
+	err := c.consumeMessages(partition, topic, offset, signal, message)
+	end(err)
+	return err
+}
+
+func (c *ConsumerClient) consumeMessages(partition int32, topic string, offset int64, signal *chan bool, message *chan MessageMetadata) error {
 	var err error
 	var partitionConsumer kafkago.PartitionConsumer
 	partitionConsumer, err = c.ConsumePartition(topic, partition, offset)
@@ -202,3 +226,10 @@ func (c *ConsumerClient) ConsumeMessages(partition int32, topic string, offset i
 		}
 	}
 }
+
+func messagingAttributes(topic string, partition int32) []attribute.KeyValue {
+	return []attribute.KeyValue{
+		attribute.String("messaging.destination.name", topic),
+		attribute.Int("messaging.destination.partition.id", int(partition)),
+	}
+}
@@ -20,16 +20,23 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"errors"
 	"net"
 	"net/http"
 	"time"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+
 	"fmt"
 
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	kapi "kubedb.dev/apimachinery/apis/kafka/v1alpha1"
 	"kubedb.dev/apimachinery/apis/kubedb"
@@ -37,12 +44,18 @@ import (
 )
 
 type KubeDBClientBuilder struct {
-	kc        client.Client
-	dbConnect *kapi.ConnectCluster
-	url       string
-	path      string
-	podName   string
-	ctx       context.Context
+	kc             client.Client
+	dbConnect      *kapi.ConnectCluster
+	url            string
+	resolver       *endpoint.Resolver
+	path           string
+	podName        string
+	ctx            context.Context
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, dbConnect *kapi.ConnectCluster) *KubeDBClientBuilder {
@@ -62,6 +75,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPath(path string) *KubeDBClientBuilder {
 	o.path = path
 	return o
@@ -72,7 +92,39 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetConnectClusterClient() (*Client, error) {
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(kapi.ResourceKindConnectCluster, o.dbConnect.Namespace, o.dbConnect.Name, o.podName)...)
+	if o.url == "" {
+		o.url = o.resolver.ServiceURL(o.dbConnect.GetConnectionScheme(), kapi.ResourceKindConnectCluster, o.dbConnect.ServiceName(), o.dbConnect.Namespace, kapi.ConnectClusterRESTPort)
+	}
 	config := Config{
 		host: o.url,
 		api:  o.path,
@@ -85,39 +137,53 @@ func (o *KubeDBClientBuilder) GetConnectClusterClient() (*Client, error) {
 		connectionScheme: o.dbConnect.GetConnectionScheme(),
 	}
 
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.dbConnect.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		config.transport.DialContext = dial
+	}
+
 	// If EnableSSL is true set tls config,
 	// provide client certs and root CA
 	if o.dbConnect.Spec.EnableSSL {
-		var certSecret core.Secret
-		err := o.kc.Get(o.ctx, types.NamespacedName{
-			Namespace: o.dbConnect.Namespace,
-			Name:      o.dbConnect.GetCertSecretName(kapi.ConnectClusterClientCert),
-		}, &certSecret)
+		err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			var certSecret core.Secret
+			err := o.kc.Get(ctx, types.NamespacedName{
+				Namespace: o.dbConnect.Namespace,
+				Name:      o.dbConnect.GetCertSecretName(kapi.ConnectClusterClientCert),
+			}, &certSecret)
+			if err != nil {
+				klog.Error(err, "failed to get connect cluster client secret")
+				return dberrors.SecretGetError(err)
+			}
+
+			// get tls cert, clientCA and rootCA for tls config
+			// use server cert ca for rootca as issuer ref is not taken into account
+			clientCA := x509.NewCertPool()
+			rootCA := x509.NewCertPool()
+
+			crt, err := tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
+			if err != nil {
+				klog.Error(err, "failed to create certificate for TLS config")
+				return err
+			}
+			clientCA.AppendCertsFromPEM(certSecret.Data[kubedb.CACert])
+			rootCA.AppendCertsFromPEM(certSecret.Data[kubedb.CACert])
+
+			config.transport.TLSClientConfig = &tls.Config{
+				Certificates: []tls.Certificate{crt},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCA,
+				RootCAs:      rootCA,
+				MaxVersion:   tls.VersionTLS13,
+			}
+			return nil
+		})
 		if err != nil {
-			klog.Error(err, "failed to get connect cluster client secret")
 			return nil, err
 		}
-
-		// get tls cert, clientCA and rootCA for tls config
-		// use server cert ca for rootca as issuer ref is not taken into account
-		clientCA := x509.NewCertPool()
-		rootCA := x509.NewCertPool()
-
-		crt, err := tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
-		if err != nil {
-			klog.Error(err, "failed to create certificate for TLS config")
-			return nil, err
-		}
-		clientCA.AppendCertsFromPEM(certSecret.Data[kubedb.CACert])
-		rootCA.AppendCertsFromPEM(certSecret.Data[kubedb.CACert])
-
-		config.transport.TLSClientConfig = &tls.Config{
-			Certificates: []tls.Certificate{crt},
-			ClientAuth:   tls.RequireAndVerifyClientCert,
-			ClientCAs:    clientCA,
-			RootCAs:      rootCA,
-			MaxVersion:   tls.VersionTLS13,
-		}
 	}
 
 	var username, password string
@@ -125,26 +191,28 @@ func (o *KubeDBClientBuilder) GetConnectClusterClient() (*Client, error) {
 	// if security is enabled set database credentials in clientConfig
 	if !o.dbConnect.Spec.DisableSecurity {
 		secret := &core.Secret{}
-		err := o.kc.Get(o.ctx, types.NamespacedName{
-			Name:      o.dbConnect.Spec.AuthSecret.Name,
-			Namespace: o.dbConnect.GetNamespace(),
-		}, secret)
+		err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+			return o.kc.Get(ctx, types.NamespacedName{
+				Name:      o.dbConnect.Spec.AuthSecret.Name,
+				Namespace: o.dbConnect.GetNamespace(),
+			}, secret)
+		})
 		if err != nil {
-			return nil, err
+			return nil, dberrors.SecretGetError(err)
 		}
 
 		if value, ok := secret.Data[core.BasicAuthUsernameKey]; ok {
 			username = string(value)
 		} else {
 			klog.Info(fmt.Sprintf("Failed for secret: %s/%s, username is missing", secret.Namespace, secret.Name))
-			return nil, errors.New("username is missing")
+			return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "username is missing")
 		}
 
 		if value, ok := secret.Data[core.BasicAuthPasswordKey]; ok {
 			password = string(value)
 		} else {
 			klog.Info(fmt.Sprintf("Failed for secret: %s/%s, password is missing", secret.Namespace, secret.Name))
-			return nil, errors.New("password is missing")
+			return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "password is missing")
 		}
 
 		config.username = username
@@ -20,24 +20,40 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"errors"
 	"strings"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+	"kubedb.dev/db-client-go/retry"
+
 	kafkago "github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
+	"kubedb.dev/apimachinery/apis/kubedb"
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *dbapi.Kafka
-	url     string
-	podName string
-	ctx     context.Context
+	kc             client.Client
+	db             *dbapi.Kafka
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.Kafka) *KubeDBClientBuilder {
@@ -57,28 +73,76 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuilder {
 	o.ctx = ctx
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases and of the
+// instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases
+// and of the instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetConfig() (*kafkago.Config, error) {
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindKafka, o.db.Namespace, o.db.Name, o.podName)...)
+
 	clientConfig := kafkago.NewConfig()
 	if !o.db.Spec.DisableSecurity {
 		if o.db.Spec.AuthSecret == nil {
 			klog.Info("Auth-secret not set")
-			return nil, errors.New("auth-secret is not set")
+			return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "auth-secret is not set")
 		}
 
 		authSecret := &core.Secret{}
-		err := o.kc.Get(o.ctx, types.NamespacedName{
-			Namespace: o.db.Namespace,
-			Name:      o.db.Spec.AuthSecret.Name,
-		}, authSecret)
+		err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+			return o.kc.Get(ctx, types.NamespacedName{
+				Namespace: o.db.Namespace,
+				Name:      o.db.Spec.AuthSecret.Name,
+			}, authSecret)
+		})
 		if err != nil {
 			if kerr.IsNotFound(err) {
 				klog.Error(err, "Auth-secret not found")
-				return nil, errors.New("auth-secret is not found")
+				return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "auth-secret is not found")
 			}
 			klog.Error(err, "Failed to get auth-secret")
 			return nil, err
@@ -89,43 +153,59 @@ func (o *KubeDBClientBuilder) GetConfig() (*kafkago.Config, error) {
 		clientConfig.Net.SASL.Password = string(authSecret.Data[core.BasicAuthPasswordKey])
 
 		if o.db.Spec.EnableSSL {
-			certSecret := &core.Secret{}
-			err := o.kc.Get(o.ctx, types.NamespacedName{
-				Namespace: o.db.Namespace,
-				Name:      o.db.GetCertSecretName(dbapi.KafkaClientCert),
-			}, certSecret)
-			if err != nil {
-				if kerr.IsNotFound(err) {
-					klog.Error(err, "Client certificate secret not found")
-					return nil, errors.New("client certificate secret is not found")
+			err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+				certSecret := &core.Secret{}
+				err := o.kc.Get(ctx, types.NamespacedName{
+					Namespace: o.db.Namespace,
+					Name:      o.db.GetCertSecretName(dbapi.KafkaClientCert),
+				}, certSecret)
+				if err != nil {
+					if kerr.IsNotFound(err) {
+						klog.Error(err, "Client certificate secret not found")
+						return dberrors.Errorf(dberrors.ErrSecretNotFound, "client certificate secret is not found")
+					}
+					klog.Error(err, "Failed to get client certificate Secret")
+					return err
 				}
-				klog.Error(err, "Failed to get client certificate Secret")
-				return nil, err
-			}
 
-			// get tls cert, clientCA and rootCA for tls config
-			clientCA := x509.NewCertPool()
-			rootCA := x509.NewCertPool()
+				// get tls cert, clientCA and rootCA for tls config
+				clientCA := x509.NewCertPool()
+				rootCA := x509.NewCertPool()
 
-			crt, err := tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
+				crt, err := tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
+				if err != nil {
+					klog.Error(err, "Failed to parse private key pair")
+					return err
+				}
+				clientCA.AppendCertsFromPEM(certSecret.Data[dbapi.TLSCACertFileName])
+				rootCA.AppendCertsFromPEM(certSecret.Data[dbapi.TLSCACertFileName])
+				clientConfig.Net.TLS.Enable = true
+				clientConfig.Net.TLS.Config = &tls.Config{
+					Certificates: []tls.Certificate{crt},
+					ClientAuth:   tls.RequireAndVerifyClientCert,
+					ClientCAs:    clientCA,
+					RootCAs:      rootCA,
+					MaxVersion:   tls.VersionTLS13,
+				}
+				return nil
+			})
 			if err != nil {
-				klog.Error(err, "Failed to parse private key pair")
 				return nil, err
 			}
-			clientCA.AppendCertsFromPEM(certSecret.Data[dbapi.TLSCACertFileName])
-			rootCA.AppendCertsFromPEM(certSecret.Data[dbapi.TLSCACertFileName])
-			clientConfig.Net.TLS.Enable = true
-			clientConfig.Net.TLS.Config = &tls.Config{
-				Certificates: []tls.Certificate{crt},
-				ClientAuth:   tls.RequireAndVerifyClientCert,
-				ClientCAs:    clientCA,
-				RootCAs:      rootCA,
-				MaxVersion:   tls.VersionTLS13,
-			}
 		}
 
 	}
 
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		// brokers are dialed by their advertised pod address
+		clientConfig.Net.Proxy.Enable = true
+		clientConfig.Net.Proxy.Dialer = dial
+	}
+
 	clientConfig.Producer.Return.Successes = true
 	clientConfig.Producer.Retry.Max = 10
 	clientConfig.Producer.Timeout = 1000
@@ -140,16 +220,23 @@ func (o *KubeDBClientBuilder) GetKafkaClient() (*Client, error) {
 	if err != nil {
 		return nil, err
 	}
-	kafkaClient, err := kafkago.NewClient(
-		strings.Split(o.url, ","),
-		clientConfig,
-	)
+	var kafkaClient kafkago.Client
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpDial, func(context.Context) error {
+			kafkaClient, err = kafkago.NewClient(
+				o.brokers(),
+				clientConfig,
+			)
+			return dberrors.Classify(err)
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &Client{
-		Client: kafkaClient,
+		Client:    kafkaClient,
+		telemetry: o.telemetry,
 	}, nil
 }
 
@@ -158,16 +245,23 @@ func (o *KubeDBClientBuilder) GetKafkaProducerClient() (*ProducerClient, error)
 	if err != nil {
 		return nil, err
 	}
-	kafkaProducerClient, err := kafkago.NewSyncProducer(
-		strings.Split(o.url, ","),
-		clientConfig,
-	)
+	var kafkaProducerClient kafkago.SyncProducer
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpDial, func(context.Context) error {
+			kafkaProducerClient, err = kafkago.NewSyncProducer(
+				o.brokers(),
+				clientConfig,
+			)
+			return dberrors.Classify(err)
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &ProducerClient{
-		kafkaProducerClient,
+		SyncProducer: kafkaProducerClient,
+		telemetry:    o.telemetry,
 	}, nil
 }
 
@@ -176,16 +270,23 @@ func (o *KubeDBClientBuilder) GetKafkaAdminClient() (*AdminClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	kafkaAdminClient, err := kafkago.NewClusterAdmin(
-		strings.Split(o.url, ","),
-		clientConfig,
-	)
+	var kafkaAdminClient kafkago.ClusterAdmin
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpDial, func(context.Context) error {
+			kafkaAdminClient, err = kafkago.NewClusterAdmin(
+				o.brokers(),
+				clientConfig,
+			)
+			return dberrors.Classify(err)
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &AdminClient{
-		kafkaAdminClient,
+		ClusterAdmin: kafkaAdminClient,
+		telemetry:    o.telemetry,
 	}, nil
 }
 
@@ -194,15 +295,34 @@ func (o *KubeDBClientBuilder) GetKafkaConsumerClient() (*ConsumerClient, error)
 	if err != nil {
 		return nil, err
 	}
-	kafkaConsumerClient, err := kafkago.NewConsumer(
-		strings.Split(o.url, ","),
-		clientConfig,
-	)
+	var kafkaConsumerClient kafkago.Consumer
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpDial, func(context.Context) error {
+			kafkaConsumerClient, err = kafkago.NewConsumer(
+				o.brokers(),
+				clientConfig,
+			)
+			return dberrors.Classify(err)
+		})
+	})
 	if err != nil {
 		return nil, err
 	}
 
 	return &ConsumerClient{
-		kafkaConsumerClient,
+		Consumer:  kafkaConsumerClient,
+		telemetry: o.telemetry,
 	}, nil
 }
+
+// brokers returns the bootstrap addresses of the comma separated url, the pod
+// or the kafka service when url is empty.
+func (o *KubeDBClientBuilder) brokers() []string {
+	if o.url != "" {
+		return strings.Split(o.url, ",")
+	}
+	if o.podName != "" {
+		return []string{o.resolver.PodAddress(dbapi.ResourceKindKafka, o.podName, o.db.GoverningServiceName(), o.db.Namespace, kubedb.KafkaRESTPort)}
+	}
+	return []string{o.resolver.ServiceAddress(dbapi.ResourceKindKafka, o.db.ServiceName(), o.db.Namespace, kubedb.KafkaRESTPort)}
+}
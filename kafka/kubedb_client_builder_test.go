@@ -0,0 +1,67 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kafka
+
+import (
+	"reflect"
+	"testing"
+
+	"kubedb.dev/db-client-go/endpoint"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
+)
+
+func TestBrokers(t *testing.T) {
+	db := &dbapi.Kafka{ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "kafka"}}
+	tests := []struct {
+		name     string
+		url      string
+		pod      string
+		resolver *endpoint.Resolver
+		want     []string
+	}{
+		{
+			name: "service",
+			want: []string{"kafka.demo.svc:9092"},
+		},
+		{
+			name:     "service with cluster domain",
+			resolver: endpoint.NewResolver().WithClusterDomain("cluster.local"),
+			want:     []string{"kafka.demo.svc.cluster.local:9092"},
+		},
+		{
+			name: "pod",
+			pod:  "kafka-0",
+			want: []string{"kafka-0.kafka-pods.demo.svc:9092"},
+		},
+		{
+			name: "url",
+			url:  "a.demo.svc:9092,b.demo.svc:9092",
+			pod:  "kafka-0",
+			want: []string{"a.demo.svc:9092", "b.demo.svc:9092"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := NewKubeDBClientBuilder(nil, db).WithURL(tt.url).WithPod(tt.pod).WithResolver(tt.resolver)
+			if got := o.brokers(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got brokers %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -18,11 +18,12 @@ package restproxy
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"net/http"
 	"time"
 
+	"kubedb.dev/db-client-go/endpoint"
+
 	"github.com/go-resty/resty/v2"
 	kapi "kubedb.dev/apimachinery/apis/kafka/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,6 +33,7 @@ type KubeDBClientBuilder struct {
 	kc        client.Client
 	restproxy *kapi.RestProxy
 	url       string
+	resolver  *endpoint.Resolver
 	path      string
 	podName   string
 	ctx       context.Context
@@ -41,7 +43,6 @@ func NewKubeDBClientBuilder(kc client.Client, restproxy *kapi.RestProxy) *KubeDB
 	return &KubeDBClientBuilder{
 		kc:        kc,
 		restproxy: restproxy,
-		url:       GetConnectionURL(restproxy),
 	}
 }
 
@@ -55,6 +56,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPath(path string) *KubeDBClientBuilder {
 	o.path = path
 	return o
@@ -66,6 +74,9 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 }
 
 func (o *KubeDBClientBuilder) GetRestProxyClient() (*Client, error) {
+	if o.url == "" {
+		o.url = getConnectionURL(o.resolver, o.restproxy)
+	}
 	config := Config{
 		host: o.url,
 		api:  o.path,
@@ -91,6 +102,9 @@ func (o *KubeDBClientBuilder) GetRestProxyClient() (*Client, error) {
 }
 
 func GetConnectionURL(restproxy *kapi.RestProxy) string {
-	return fmt.Sprintf("%s://%s.%s.svc:%d", restproxy.GetConnectionScheme(), restproxy.ServiceName(), restproxy.Namespace, kapi.RestProxyRESTPort)
+	return getConnectionURL(nil, restproxy)
+}
 
+func getConnectionURL(r *endpoint.Resolver, restproxy *kapi.RestProxy) string {
+	return r.ServiceURL(restproxy.GetConnectionScheme(), kapi.ResourceKindRestProxy, restproxy.ServiceName(), restproxy.Namespace, kapi.RestProxyRESTPort)
 }
@@ -18,11 +18,12 @@ package schemaregistry
 
 import (
 	"context"
-	"fmt"
 	"net"
 	"net/http"
 	"time"
 
+	"kubedb.dev/db-client-go/endpoint"
+
 	"github.com/go-resty/resty/v2"
 	kapi "kubedb.dev/apimachinery/apis/kafka/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -32,6 +33,7 @@ type KubeDBClientBuilder struct {
 	kc       client.Client
 	dbSchema *kapi.SchemaRegistry
 	url      string
+	resolver *endpoint.Resolver
 	path     string
 	podName  string
 	ctx      context.Context
@@ -41,7 +43,6 @@ func NewKubeDBClientBuilder(kc client.Client, dbSchema *kapi.SchemaRegistry) *Ku
 	return &KubeDBClientBuilder{
 		kc:       kc,
 		dbSchema: dbSchema,
-		url:      GetConnectionURL(dbSchema),
 	}
 }
 
@@ -55,6 +56,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPath(path string) *KubeDBClientBuilder {
 	o.path = path
 	return o
@@ -66,6 +74,9 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 }
 
 func (o *KubeDBClientBuilder) GetSchemaRegistryClient() (*Client, error) {
+	if o.url == "" {
+		o.url = getConnectionURL(o.resolver, o.dbSchema)
+	}
 	config := Config{
 		host: o.url,
 		api:  o.path,
@@ -91,6 +102,10 @@ func (o *KubeDBClientBuilder) GetSchemaRegistryClient() (*Client, error) {
 }
 
 func GetConnectionURL(registry *kapi.SchemaRegistry) string {
-	return fmt.Sprintf("%s://%s.%s.svc:%d", registry.GetConnectionScheme(), registry.ServiceName(), registry.Namespace, kapi.ApicurioRegistryRESTPort)
+	return getConnectionURL(nil, registry)
+}
+
+func getConnectionURL(r *endpoint.Resolver, registry *kapi.SchemaRegistry) string {
+	return r.ServiceURL(registry.GetConnectionScheme(), kapi.ResourceKindSchemaRegistry, registry.ServiceName(), registry.Namespace, kapi.ApicurioRegistryRESTPort)
 
 }
@@ -21,12 +21,19 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"database/sql"
-	"fmt"
+
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
 
 	"kubedb.dev/apimachinery/apis/kubedb"
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
 	sql_driver "github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -38,11 +45,20 @@ const (
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *dbapi.MariaDB
-	url     string
-	podName string
-	ctx     context.Context
+	kc             client.Client
+	db             *dbapi.MariaDB
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	serverName     string
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
+	// tlsConfigKey is the name of the TLS config registered by the current
+	// build.
+	tlsConfigKey string
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.MariaDB) *KubeDBClientBuilder {
@@ -57,6 +73,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	return o
@@ -67,11 +90,40 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithServerName sets the name used to verify the server certificate.
+// By default, the host of the connection address is verified.
+func (o *KubeDBClientBuilder) WithServerName(serverName string) *KubeDBClientBuilder {
+	o.serverName = serverName
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetMariaDBClient() (*Client, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindMariaDB, o.db.Namespace, o.db.Name, o.podName)...)
 
+	defer o.deregisterTLSConfig()
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -84,12 +136,14 @@ func (o *KubeDBClientBuilder) GetMariaDBClient() (*Client, error) {
 	}
 
 	// ping to database to check the connection
-	if err := db.PingContext(o.ctx); err != nil {
+	if err := o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, db.PingContext)
+	}); err != nil {
 		closeErr := db.Close()
 		if closeErr != nil {
 			klog.Errorf("Failed to close client. error: %v", closeErr)
 		}
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	return &Client{db}, nil
@@ -99,7 +153,10 @@ func (o *KubeDBClientBuilder) GetMariaDBXormClient() (*XormClient, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindMariaDB, o.db.Namespace, o.db.Name, o.podName)...)
 
+	defer o.deregisterTLSConfig()
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -108,9 +165,14 @@ func (o *KubeDBClientBuilder) GetMariaDBXormClient() (*XormClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	_, err = engine.Query("SELECT 1")
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			_, err := engine.Query("SELECT 1")
+			return err
+		})
+	})
 	if err != nil {
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 	engine.SetDefaultContext(o.ctx)
 	return &XormClient{
@@ -124,17 +186,19 @@ func (o *KubeDBClientBuilder) getMariaDBBasicAuth() (string, string, error) {
 		secretName = o.db.GetAuthSecretName()
 	}
 	var secret core.Secret
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: secretName}, &secret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: secretName}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	user, ok := secret.Data[core.BasicAuthUsernameKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root user is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root user is not set")
 	}
 	pass, ok := secret.Data[core.BasicAuthPasswordKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root password is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root password is not set")
 	}
 	return string(user), string(pass), nil
 }
@@ -143,8 +207,21 @@ func (o *KubeDBClientBuilder) SSLEnabledMariaDB() bool {
 	return o.db.Spec.TLS != nil && o.db.Spec.RequireSSL
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.db.GoverningServiceName(), o.db.Namespace)
+// getTLSConfigKey returns the name the TLS config of this database is
+// registered with, so that databases with different CAs don't overwrite
+// each other's config.
+func (o *KubeDBClientBuilder) getTLSConfigKey() string {
+	return dsn.MySQLTLSConfigKey(kubedb.MariaDBTLSConfigCustom, o.db.Namespace, o.db.Name)
+}
+
+// deregisterTLSConfig removes the TLS config registered by
+// getConnectionString. The driver clones the config when the database is
+// opened, so it is not used afterwards.
+func (o *KubeDBClientBuilder) deregisterTLSConfig() {
+	if o.tlsConfigKey != "" {
+		sql_driver.DeregisterTLSConfig(o.tlsConfigKey)
+		o.tlsConfigKey = ""
+	}
 }
 
 func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
@@ -154,53 +231,61 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	}
 
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodHost(o.podName, o.db.GoverningServiceName(), o.db.Namespace)
 	}
 
 	tlsConfig := ""
 	if o.SSLEnabledMariaDB() {
-		// get client-secret
-		var clientSecret core.Secret
-		err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.GetCertSecretName(dbapi.MariaDBClientCert)}, &clientSecret)
-		if err != nil {
-			return "", err
-		}
+		err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			// get client-secret
+			var clientSecret core.Secret
+			err := o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.GetCertSecretName(dbapi.MariaDBClientCert)}, &clientSecret)
+			if err != nil {
+				return dberrors.SecretGetError(err)
+			}
 
-		value, exists := clientSecret.Data[rootCAKey]
-		if !exists {
-			return "", fmt.Errorf("%v in not present in client secret", rootCAKey)
-		}
-		cacrt := value
-		certPool := x509.NewCertPool()
-		certPool.AppendCertsFromPEM(cacrt)
+			value, exists := clientSecret.Data[rootCAKey]
+			if !exists {
+				return dberrors.Errorf(dberrors.ErrInvalidSecret, "%v in not present in client secret", rootCAKey)
+			}
+			cacrt := value
+			certPool := x509.NewCertPool()
+			certPool.AppendCertsFromPEM(cacrt)
 
-		value, exists = clientSecret.Data[core.TLSCertKey]
-		if !exists {
-			return "", fmt.Errorf("%v in not present in client secret", core.TLSCertKey)
-		}
-		crt := value
+			value, exists = clientSecret.Data[core.TLSCertKey]
+			if !exists {
+				return dberrors.Errorf(dberrors.ErrInvalidSecret, "%v in not present in client secret", core.TLSCertKey)
+			}
+			crt := value
 
-		value, exists = clientSecret.Data[core.TLSPrivateKeyKey]
-		if !exists {
-			return "", fmt.Errorf("%v in not present in client secret", core.TLSPrivateKeyKey)
-		}
-		key := value
+			value, exists = clientSecret.Data[core.TLSPrivateKeyKey]
+			if !exists {
+				return dberrors.Errorf(dberrors.ErrInvalidSecret, "%v in not present in client secret", core.TLSPrivateKeyKey)
+			}
+			key := value
 
-		cert, err := tls.X509KeyPair(crt, key)
-		if err != nil {
-			return "", err
-		}
-		var clientCert []tls.Certificate
-		clientCert = append(clientCert, cert)
-		err = sql_driver.RegisterTLSConfig(kubedb.MariaDBTLSConfigCustom, &tls.Config{
-			RootCAs:      certPool,
-			Certificates: clientCert,
+			cert, err := tls.X509KeyPair(crt, key)
+			if err != nil {
+				return err
+			}
+			var clientCert []tls.Certificate
+			clientCert = append(clientCert, cert)
+			// tls custom setup, registered under a key unique to this database
+			tlsConfig = o.getTLSConfigKey()
+			o.tlsConfigKey = tlsConfig
+			err = sql_driver.RegisterTLSConfig(tlsConfig, &tls.Config{
+				RootCAs:      certPool,
+				Certificates: clientCert,
+				ServerName:   o.serverName,
+			})
+			if err != nil {
+				return err
+			}
+			return nil
 		})
 		if err != nil {
 			return "", err
 		}
-		tlsConfig = fmt.Sprintf("tls=%s", kubedb.MariaDBTLSConfigCustom)
 	}
-	connector := fmt.Sprintf("%v:%v@tcp(%s:%d)/%s?%s", user, pass, o.url, 3306, "mysql", tlsConfig)
-	return connector, nil
+	return dsn.MySQL(user, pass, "tcp", endpoint.JoinHostPort(o.url, o.resolver.Port(dbapi.ResourceKindMariaDB, kubedb.MySQLDatabasePort)), "mysql", tlsConfig), nil
 }
@@ -18,33 +18,51 @@ package mongodb
 
 import (
 	"context"
-	"errors"
-	"fmt"
+	"crypto/tls"
+	"crypto/x509"
+	"net/url"
 	"strings"
 	"time"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+	"kubedb.dev/db-client-go/retry"
+
 	"kubedb.dev/apimachinery/apis/kubedb"
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
 	"go.mongodb.org/mongo-driver/mongo"
 	mgoptions "go.mongodb.org/mongo-driver/mongo/options"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"kmodules.xyz/client-go/tools/certholder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type KubeDBClientBuilder struct {
-	kc         client.Client
-	db         *dbapi.MongoDB
-	url        string
-	podName    string
-	repSetName string
-	direct     bool
-	certs      *certholder.ResourceCerts
-	ctx        context.Context
-	cred       string
-	authDB     string
+	kc             client.Client
+	db             *dbapi.MongoDB
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	repSetName     string
+	direct         bool
+	certs          *certholder.ResourceCerts
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	cred           string
+	authDB         string
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.MongoDB) *KubeDBClientBuilder {
@@ -72,6 +90,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	o.direct = true
@@ -88,6 +113,28 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithDirect() *KubeDBClientBuilder {
 	o.direct = true
 	return o
@@ -98,15 +145,29 @@ func (o *KubeDBClientBuilder) WithCerts(certs *certholder.ResourceCerts) *KubeDB
 	return o
 }
 
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetMongoClient() (*Client, error) {
 	db := o.db
 
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindMongoDB, o.db.Namespace, o.db.Name, o.podName)...)
 
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodAddress(dbapi.ResourceKindMongoDB, o.podName, o.getGoverningServiceName(), o.db.Namespace, kubedb.MongoDBDatabasePort)
 	}
 
 	if o.podName == "" && o.url == "" {
@@ -124,18 +185,27 @@ func (o *KubeDBClientBuilder) GetMongoClient() (*Client, error) {
 		return nil, err
 	}
 
-	client, err := mongo.Connect(o.ctx, clientOpts)
+	var client *mongo.Client
+	err = o.telemetry.Run(o.ctx, telemetry.OpDial, func(ctx context.Context) error {
+		var err error
+		client, err = mongo.Connect(ctx, clientOpts)
+		return err
+	})
 	if err != nil {
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
-	err = client.Ping(o.ctx, nil)
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			return client.Ping(ctx, nil)
+		})
+	})
 	if err != nil {
 		disconnectErr := client.Disconnect(o.ctx)
 		if disconnectErr != nil {
 			klog.Errorf("Failed to disconnect client. error: %v", disconnectErr)
 		}
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	return &Client{
@@ -143,94 +213,118 @@ func (o *KubeDBClientBuilder) GetMongoClient() (*Client, error) {
 	}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
+// getGoverningServiceName returns the governing service of the pod.
+func (o *KubeDBClientBuilder) getGoverningServiceName() string {
 	nodeType := o.podName[:strings.LastIndex(o.podName, "-")]
 	if strings.HasSuffix(nodeType, kubedb.NodeTypeArbiter) {
 		// nodeType looks like <DB_NAME>-shard<SHARD_NUMBER>-arbiter for shard, <DB_NAME>-arbiter otherwise.
 		// so excluding  '-arbiter' will give us the stsName where this arbiter belongs as a member of rs
 		nodeType = nodeType[:strings.LastIndex(nodeType, "-")]
 	}
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.db.GoverningServiceName(nodeType), o.db.Namespace)
+	return o.db.GoverningServiceName(nodeType)
 }
 
 func (o *KubeDBClientBuilder) getMongoDBClientOpts() (*mgoptions.ClientOptions, error) {
 	db := o.db
-	repSetConfig := ""
+	params := url.Values{}
 	if o.repSetName != "" {
-		repSetConfig = "replicaSet=" + o.repSetName + "&"
+		params.Set("replicaSet", o.repSetName)
 	}
-	authDatabase := ""
 	if o.authDB != "" {
-		authDatabase = "authSource=" + o.authDB
+		params.Set("authSource", o.authDB)
 	}
 
-	cred := o.cred
-	if cred == "" {
-		user, pass, err := o.getMongoDBRootCredentials()
+	var user, pass string
+	if o.cred != "" {
+		user, pass, _ = strings.Cut(o.cred, ":")
+	} else {
+		var err error
+		user, pass, err = o.getMongoDBRootCredentials()
 		if err != nil {
 			return nil, err
 		}
-		cred = fmt.Sprintf("%s:%s", user, pass)
 	}
 
-	var clientOpts *mgoptions.ClientOptions
+	var tlsConfig *tls.Config
 	if db.Spec.TLS != nil {
-		if o.authDB != "" {
-			authDatabase = "&" + authDatabase
-		}
-		secretName := db.GetCertSecretName(dbapi.MongoDBClientCert, "")
-		var (
-			paths *certholder.Paths
-			err   error
-		)
-		if o.certs == nil {
-			var certSecret core.Secret
-			err = o.kc.Get(o.ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &certSecret)
-			if err != nil {
-				klog.Error(err, "failed to get certificate secret. ", secretName)
-				return nil, err
-			}
-
-			certs, _ := certholder.DefaultHolder.
-				ForResource(dbapi.SchemeGroupVersion.WithResource(dbapi.ResourcePluralMongoDB), db.ObjectMeta)
-			_, err = certs.Save(&certSecret)
-			if err != nil {
-				klog.Error(err, "failed to save certificate")
-				return nil, err
-			}
-
-			paths, err = certs.Get(secretName)
-			if err != nil {
-				return nil, err
-			}
-		} else {
-			paths, err = o.certs.Get(secretName)
-			if err != nil {
-				return nil, err
+		err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			secretName := db.GetCertSecretName(dbapi.MongoDBClientCert, "")
+			if o.certs == nil {
+				var certSecret core.Secret
+				err := o.kc.Get(ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &certSecret)
+				if err != nil {
+					klog.Error(err, "failed to get certificate secret. ", secretName)
+					return dberrors.SecretGetError(err)
+				}
+				tlsConfig, err = getTLSConfig(&certSecret)
+				if err != nil {
+					return err
+				}
+			} else {
+				paths, err := o.certs.Get(secretName)
+				if err != nil {
+					return err
+				}
+				params.Set("tls", "true")
+				params.Set("tlsCAFile", paths.CACert)
+				params.Set("tlsCertificateKeyFile", paths.Pem)
 			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
 		}
-
-		uri := fmt.Sprintf("mongodb://%s@%s/admin?%vtls=true&tlsCAFile=%v&tlsCertificateKeyFile=%v%v", cred, o.url, repSetConfig, paths.CACert, paths.Pem, authDatabase)
-		clientOpts = mgoptions.Client().ApplyURI(uri)
-	} else {
-		clientOpts = mgoptions.Client().ApplyURI(fmt.Sprintf("mongodb://%s@%s/admin?%v%v", cred, o.url, repSetConfig, authDatabase))
 	}
 
+	uri := dsn.URL("mongodb", user, pass, o.url, "/admin", params)
+	clientOpts := mgoptions.Client().ApplyURI(uri)
+	if tlsConfig != nil {
+		clientOpts.SetTLSConfig(tlsConfig)
+	}
 	clientOpts.SetDirect(o.direct)
 	clientOpts.SetConnectTimeout(5 * time.Second)
 
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		clientOpts.SetDialer(dial)
+	}
+
 	return clientOpts, nil
 }
 
+func getTLSConfig(certSecret *core.Secret) (*tls.Config, error) {
+	caCert, ok := certSecret.Data[dbapi.TLSCACertFileName]
+	if !ok {
+		return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "%s is not present in secret %s/%s", dbapi.TLSCACertFileName, certSecret.Namespace, certSecret.Name)
+	}
+	certPool := x509.NewCertPool()
+	if !certPool.AppendCertsFromPEM(caCert) {
+		return nil, dberrors.Errorf(dberrors.ErrInvalidSecret, "failed to parse %s of secret %s/%s", dbapi.TLSCACertFileName, certSecret.Namespace, certSecret.Name)
+	}
+	cert, err := tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+	return &tls.Config{
+		RootCAs:      certPool,
+		Certificates: []tls.Certificate{cert},
+	}, nil
+}
+
 func (o *KubeDBClientBuilder) getMongoDBRootCredentials() (string, string, error) {
 	db := o.db
 	if db.Spec.AuthSecret == nil {
-		return "", "", errors.New("no database secret")
+		return "", "", dberrors.Errorf(dberrors.ErrSecretNotFound, "no database secret")
 	}
 	var secret core.Secret
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: db.Namespace, Name: db.Spec.AuthSecret.Name}, &secret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: db.Namespace, Name: db.Spec.AuthSecret.Name}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	return string(secret.Data[core.BasicAuthUsernameKey]), string(secret.Data[core.BasicAuthPasswordKey]), nil
 }
@@ -19,22 +19,36 @@ package mssql
 import (
 	"context"
 
-	"fmt"
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
+
+	"net/url"
 
 	_ "github.com/microsoft/go-mssqldb"
+	"kubedb.dev/apimachinery/apis/kubedb"
 	olddbapi "kubedb.dev/apimachinery/apis/kubedb/v1alpha2"
 
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"xorm.io/xorm"
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *olddbapi.MSSQLServer
-	url     string
-	podName string
-	ctx     context.Context
+	kc             client.Client
+	db             *olddbapi.MSSQLServer
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *olddbapi.MSSQLServer) *KubeDBClientBuilder {
@@ -49,6 +63,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	return o
@@ -59,10 +80,31 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetMSSQLXormClient() (*XormClient, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(olddbapi.ResourceKindMSSQLServer, o.db.Namespace, o.db.Name, o.podName)...)
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -73,9 +115,14 @@ func (o *KubeDBClientBuilder) GetMSSQLXormClient() (*XormClient, error) {
 		return nil, err
 	}
 
-	_, err = engine.Query("SELECT 1")
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			_, err := engine.Query("SELECT 1")
+			return err
+		})
+	})
 	if err != nil {
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	engine.SetDefaultContext(o.ctx)
@@ -84,10 +131,6 @@ func (o *KubeDBClientBuilder) GetMSSQLXormClient() (*XormClient, error) {
 	}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.db.GoverningServiceName(), o.db.Namespace)
-}
-
 func (o *KubeDBClientBuilder) getMSSQLSACredentials() (string, string, error) {
 	db := o.db
 	var secretName string
@@ -95,17 +138,19 @@ func (o *KubeDBClientBuilder) getMSSQLSACredentials() (string, string, error) {
 		secretName = db.GetAuthSecretName()
 	}
 	var secret core.Secret
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	user, ok := secret.Data[core.BasicAuthUsernameKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB SA user is not found in secret")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB SA user is not found in secret")
 	}
 	pass, ok := secret.Data[core.BasicAuthPasswordKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB  password is not set in secret")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB  password is not set in secret")
 	}
 	return string(user), string(pass), nil
 }
@@ -117,16 +162,17 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	}
 
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodAddress(olddbapi.ResourceKindMSSQLServer, o.podName, o.db.GoverningServiceName(), o.db.Namespace, kubedb.MSSQLDatabasePort)
 	}
 
+	params := url.Values{}
+	params.Set("database", "master")
 	// TLS Configuration
-	tlsConfig := ""
 	if o.db.Spec.TLS.ClientTLS != nil && *o.db.Spec.TLS.ClientTLS {
-		tlsConfig = "encrypt=true;TrustServerCertificate=true;"
+		params.Set("encrypt", "true")
+		params.Set("TrustServerCertificate", "true")
 	}
 
-	// The connection string in ADO format: key=value pairs separated by ;. Values may not contain ;, leading and trailing whitespace is ignored.
-	connectionString := fmt.Sprintf("server=%s;user id=%s;password=%s;database=master;%s", o.url, user, pass, tlsConfig)
-	return connectionString, nil
+	// The connection string in URL format, so that credentials are escaped properly
+	return dsn.URL("sqlserver", user, pass, o.url, "", params), nil
 }
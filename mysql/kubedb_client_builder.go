@@ -22,23 +22,45 @@ import (
 	"crypto/x509"
 	"database/sql"
 	"fmt"
+	"net"
+
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+	"kubedb.dev/db-client-go/retry"
 
 	"kubedb.dev/apimachinery/apis/kubedb"
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
 	sql_driver "github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"xorm.io/xorm"
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *dbapi.MySQL
-	url     string
-	podName string
-	ctx     context.Context
+	kc             client.Client
+	db             *dbapi.MySQL
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	serverName     string
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
+	// tlsConfigKey is the name of the TLS config registered by the current
+	// build.
+	tlsConfigKey string
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.MySQL) *KubeDBClientBuilder {
@@ -53,6 +75,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	return o
@@ -63,11 +92,57 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
+// WithServerName sets the name used to verify the server certificate.
+// By default, the host of the connection address is verified. The server
+// certificate is only verified when spec.requireSSL is set, otherwise the
+// skip-verify TLS config is used.
+func (o *KubeDBClientBuilder) WithServerName(serverName string) *KubeDBClientBuilder {
+	o.serverName = serverName
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetMySQLClient() (*Client, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindMySQL, o.db.Namespace, o.db.Name, o.podName)...)
 
+	defer o.deregisterTLSConfig()
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -80,12 +155,14 @@ func (o *KubeDBClientBuilder) GetMySQLClient() (*Client, error) {
 	}
 
 	// ping to database to check the connection
-	if err := db.PingContext(o.ctx); err != nil {
+	if err := o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, db.PingContext)
+	}); err != nil {
 		closeErr := db.Close()
 		if closeErr != nil {
 			klog.Errorf("Failed to close client. error: %v", closeErr)
 		}
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	return &Client{db}, nil
@@ -95,6 +172,9 @@ func (o *KubeDBClientBuilder) GetMySQLXormClient() (*XormClient, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindMySQL, o.db.Namespace, o.db.Name, o.podName)...)
+	defer o.deregisterTLSConfig()
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -103,9 +183,14 @@ func (o *KubeDBClientBuilder) GetMySQLXormClient() (*XormClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	_, err = engine.Query("SELECT 1")
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			_, err := engine.Query("SELECT 1")
+			return err
+		})
+	})
 	if err != nil {
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	engine.SetDefaultContext(o.ctx)
@@ -114,10 +199,6 @@ func (o *KubeDBClientBuilder) GetMySQLXormClient() (*XormClient, error) {
 	}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.db.GoverningServiceName(), o.db.Namespace)
-}
-
 func (o *KubeDBClientBuilder) getMySQLRootCredentials() (string, string, error) {
 	db := o.db
 	var secretName string
@@ -125,21 +206,46 @@ func (o *KubeDBClientBuilder) getMySQLRootCredentials() (string, string, error)
 		secretName = db.GetAuthSecretName()
 	}
 	var secret core.Secret
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	user, ok := secret.Data[core.BasicAuthUsernameKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root user is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root user is not set")
 	}
 	pass, ok := secret.Data[core.BasicAuthPasswordKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root password is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root password is not set")
 	}
 	return string(user), string(pass), nil
 }
 
+// getTLSConfigKey returns the name the TLS config of this database is
+// registered with, so that databases with different CAs don't overwrite
+// each other's config.
+func (o *KubeDBClientBuilder) getTLSConfigKey() string {
+	return dsn.MySQLTLSConfigKey(kubedb.MySQLTLSConfigCustom, o.db.Namespace, o.db.Name)
+}
+
+// deregisterTLSConfig removes the TLS config registered by
+// getConnectionString. The driver clones the config when the database is
+// opened, so it is not used afterwards.
+func (o *KubeDBClientBuilder) deregisterTLSConfig() {
+	if o.tlsConfigKey != "" {
+		sql_driver.DeregisterTLSConfig(o.tlsConfigKey)
+		o.tlsConfigKey = ""
+	}
+}
+
+// getDialNetwork returns the network name the dialer of this database is
+// registered with.
+func (o *KubeDBClientBuilder) getDialNetwork() string {
+	return fmt.Sprintf("kubedb-dial.%s.%s", o.db.Namespace, o.db.Name)
+}
+
 func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	user, pass, err := o.getMySQLRootCredentials()
 	if err != nil {
@@ -147,45 +253,65 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	}
 
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodHost(o.podName, o.db.GoverningServiceName(), o.db.Namespace)
 	}
 
 	tlsConfig := ""
 	if o.db.Spec.RequireSSL && o.db.Spec.TLS != nil {
-		// get client-secret
-		var clientSecret core.Secret
-		err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.GetNamespace(), Name: o.db.GetCertSecretName(dbapi.MySQLClientCert)}, &clientSecret)
-		if err != nil {
-			return "", err
-		}
-		cacrt := clientSecret.Data["ca.crt"]
-		certPool := x509.NewCertPool()
-		certPool.AppendCertsFromPEM(cacrt)
+		err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			// get client-secret
+			var clientSecret core.Secret
+			err := o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.GetNamespace(), Name: o.db.GetCertSecretName(dbapi.MySQLClientCert)}, &clientSecret)
+			if err != nil {
+				return dberrors.SecretGetError(err)
+			}
+			cacrt := clientSecret.Data["ca.crt"]
+			certPool := x509.NewCertPool()
+			certPool.AppendCertsFromPEM(cacrt)
 
-		crt := clientSecret.Data["tls.crt"]
-		key := clientSecret.Data["tls.key"]
-		cert, err := tls.X509KeyPair(crt, key)
-		if err != nil {
-			return "", err
-		}
-		var clientCert []tls.Certificate
-		clientCert = append(clientCert, cert)
-
-		// tls custom setup
-		if o.db.Spec.RequireSSL {
-			err = sql_driver.RegisterTLSConfig(kubedb.MySQLTLSConfigCustom, &tls.Config{
-				RootCAs:      certPool,
-				Certificates: clientCert,
-			})
+			crt := clientSecret.Data["tls.crt"]
+			key := clientSecret.Data["tls.key"]
+			cert, err := tls.X509KeyPair(crt, key)
 			if err != nil {
-				return "", err
+				return err
+			}
+			var clientCert []tls.Certificate
+			clientCert = append(clientCert, cert)
+
+			// tls custom setup, registered under a key unique to this database
+			if o.db.Spec.RequireSSL {
+				tlsConfig = o.getTLSConfigKey()
+				o.tlsConfigKey = tlsConfig
+				err = sql_driver.RegisterTLSConfig(tlsConfig, &tls.Config{
+					RootCAs:      certPool,
+					Certificates: clientCert,
+					ServerName:   o.serverName,
+				})
+				if err != nil {
+					return err
+				}
+			} else {
+				tlsConfig = kubedb.MySQLTLSConfigSkipVerify
 			}
-			tlsConfig = fmt.Sprintf("tls=%s", kubedb.MySQLTLSConfigCustom)
-		} else {
-			tlsConfig = fmt.Sprintf("tls=%s", kubedb.MySQLTLSConfigSkipVerify)
+			return nil
+		})
+		if err != nil {
+			return "", err
 		}
 	}
 
-	connector := fmt.Sprintf("%v:%v@tcp(%s:%d)/%s?%s", user, pass, o.url, 3306, "mysql", tlsConfig)
-	return connector, nil
+	network := "tcp"
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
+	if err != nil {
+		return "", err
+	}
+	if dial != nil {
+		// custom dialers are registered by network name, like the TLS configs
+		network = o.getDialNetwork()
+		sql_driver.RegisterDialContext(network, func(ctx context.Context, addr string) (net.Conn, error) {
+			return dial(ctx, "tcp", addr)
+		})
+	}
+	addr := endpoint.JoinHostPort(o.url, o.resolver.Port(dbapi.ResourceKindMySQL, kubedb.MySQLDatabasePort))
+	return dsn.MySQL(user, pass, network, addr, "mysql", tlsConfig), nil
 }
@@ -18,12 +18,21 @@ package pgbouncer
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"strconv"
+
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
 
 	"kubedb.dev/apimachinery/apis/kubedb"
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
@@ -31,6 +40,7 @@ import (
 	appbinding "kmodules.xyz/custom-resources/apis/appcatalog/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"xorm.io/xorm"
+	xormcore "xorm.io/xorm/core"
 )
 
 const (
@@ -46,12 +56,18 @@ type KubeDBClientBuilder struct {
 	kc              client.Client
 	pgbouncer       *dbapi.PgBouncer
 	url             string
+	resolver        *endpoint.Resolver
 	podName         string
 	pbContainerPort *int32
 	backendDBName   string
 	ctx             context.Context
 	databaseRef     *dbapi.Database
 	auth            *Auth
+	certs           *certholder.ResourceCerts
+	tlsConfig       *tls.Config
+	tracerProvider  trace.TracerProvider
+	meterProvider   metric.MeterProvider
+	telemetry       *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, pb *dbapi.PgBouncer) *KubeDBClientBuilder {
@@ -66,6 +82,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPbPort(listenPort int32) *KubeDBClientBuilder {
 	o.pbContainerPort = &listenPort
 	return o
@@ -102,17 +125,43 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithCerts makes the builder write the client certificate secret to the
+// given certholder and pass the file paths to the driver, instead of keeping
+// the certificates in memory.
+func (o *KubeDBClientBuilder) WithCerts(certs *certholder.ResourceCerts) *KubeDBClientBuilder {
+	o.certs = certs
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetPgBouncerXormClient() (*XormClient, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindPgBouncer, o.pgbouncer.Namespace, o.pgbouncer.Name, o.podName)...)
 
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
 	}
 
-	engine, err := xorm.NewEngine(DefaultBackendDBType, connector)
+	db, err := dsn.OpenPostgres(connector, nil, o.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := xorm.NewEngineWithDB(DefaultBackendDBType, dsn.XormPostgres(o.backendDBName), xormcore.FromDB(db))
 	if err != nil {
 		return nil, err
 	}
@@ -126,10 +175,6 @@ func (o *KubeDBClientBuilder) GetPgBouncerXormClient() (*XormClient, error) {
 	}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.pgbouncer.GoverningServiceName(), o.pgbouncer.Namespace)
-}
-
 func (o *KubeDBClientBuilder) getBackendAuth() (string, string, error) {
 	if o.auth != nil {
 		return o.auth.UserName, o.auth.Password, nil
@@ -149,45 +194,62 @@ func (o *KubeDBClientBuilder) getBackendAuth() (string, string, error) {
 		return "", "", err
 	}
 	if appBinding.Spec.Secret == nil {
-		return "", "", fmt.Errorf("backend postgres auth secret unspecified for pgBouncer %s/%s", o.pgbouncer.Namespace, o.pgbouncer.Name)
+		return "", "", dberrors.Errorf(dberrors.ErrSecretNotFound, "backend postgres auth secret unspecified for pgBouncer %s/%s", o.pgbouncer.Namespace, o.pgbouncer.Name)
 	}
 
 	var secret core.Secret
-	err = o.kc.Get(o.ctx, client.ObjectKey{Namespace: appBinding.Namespace, Name: appBinding.Spec.Secret.Name}, &secret)
+	err = o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: appBinding.Namespace, Name: appBinding.Spec.Secret.Name}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 
 	user, present := secret.Data[core.BasicAuthUsernameKey]
 	if !present {
-		return "", "", fmt.Errorf("error getting backend username")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "error getting backend username")
 	}
 
 	pass, present := secret.Data[core.BasicAuthPasswordKey]
 	if !present {
-		return "", "", fmt.Errorf("error getting backend password")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "error getting backend password")
 	}
 
 	return string(user), string(pass), nil
 }
 
-func (o *KubeDBClientBuilder) getTLSConfig(ctx context.Context) (*certholder.Paths, error) {
+// getTLSConfig returns params with the TLS parameters of the certificate
+// secret. Without a certificate store, the TLS config is kept for the dialer
+// of the connections instead.
+func (o *KubeDBClientBuilder) getTLSConfig(ctx context.Context, params []dsn.PostgresParam) ([]dsn.PostgresParam, error) {
 	secretName := o.pgbouncer.GetCertSecretName(dbapi.PgBouncerClientCert)
 
 	var certSecret core.Secret
 	err := o.kc.Get(ctx, client.ObjectKey{Namespace: o.pgbouncer.Namespace, Name: secretName}, &certSecret)
 	if err != nil {
 		klog.Error(err, "failed to get certificate secret.", secretName)
-		return nil, err
+		return nil, dberrors.SecretGetError(err)
 	}
 
-	certs, _ := certholder.DefaultHolder.ForResource(dbapi.SchemeGroupVersion.WithResource(dbapi.ResourcePluralPgBouncer), o.pgbouncer.ObjectMeta)
-	paths, err := certs.Save(&certSecret)
+	clientCert := o.pgbouncer.Spec.SSLMode == dbapi.PgBouncerSSLModeVerifyCA || o.pgbouncer.Spec.SSLMode == dbapi.PgBouncerSSLModeVerifyFull
+	if o.certs == nil {
+		params, o.tlsConfig, err = dsn.PostgresTLS(&certSecret, params, clientCert)
+		return params, err
+	}
+
+	paths, err := o.certs.Save(&certSecret)
 	if err != nil {
 		klog.Error(err, "failed to save certificate")
 		return nil, err
 	}
-	return paths, nil
+	params = append(params, dsn.PostgresParam{Key: "sslrootcert", Value: paths.CACert})
+	if clientCert {
+		params = append(params,
+			dsn.PostgresParam{Key: "sslcert", Value: paths.Cert},
+			dsn.PostgresParam{Key: "sslkey", Value: paths.Key},
+		)
+	}
+	return params, nil
 }
 
 func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
@@ -197,10 +259,10 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	}
 
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodHost(o.podName, o.pgbouncer.GoverningServiceName(), o.pgbouncer.Namespace)
 	}
 
-	var listeningPort int = kubedb.PgBouncerDatabasePort
+	listeningPort := o.resolver.Port(dbapi.ResourceKindPgBouncer, kubedb.PgBouncerDatabasePort)
 	if o.pbContainerPort != nil {
 		listeningPort = int(*o.pbContainerPort)
 	}
@@ -209,21 +271,26 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	if sslMode == "" {
 		sslMode = dbapi.PgBouncerSSLModeDisable
 	}
-	connector := ""
+	params := []dsn.PostgresParam{
+		{Key: "user", Value: user},
+		{Key: "password", Value: pass},
+		{Key: "host", Value: o.url},
+		{Key: "port", Value: strconv.Itoa(listeningPort)},
+		{Key: "connect_timeout", Value: "10"},
+		{Key: "dbname", Value: o.backendDBName},
+		{Key: "sslmode", Value: string(sslMode)},
+	}
 	if o.pgbouncer.Spec.TLS != nil {
-		paths, err := o.getTLSConfig(o.ctx)
+		err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			var err error
+			params, err = o.getTLSConfig(ctx, params)
+			return err
+		})
 		if err != nil {
 			return "", err
 		}
-		if o.pgbouncer.Spec.SSLMode == dbapi.PgBouncerSSLModeVerifyCA || o.pgbouncer.Spec.SSLMode == dbapi.PgBouncerSSLModeVerifyFull {
-			connector = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s sslrootcert=%s sslcert=%s sslkey=%s", user, pass, o.url, listeningPort, o.backendDBName, sslMode, paths.CACert, paths.Cert, paths.Key)
-		} else {
-			connector = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s sslrootcert=%s", user, pass, o.url, listeningPort, o.backendDBName, sslMode, paths.CACert)
-		}
-	} else {
-		connector = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s", user, pass, o.url, listeningPort, o.backendDBName, sslMode)
 	}
-	return connector, nil
+	return dsn.FormatPostgres(params...), nil
 }
 
 func GetXormClientList(kc client.Client, pb *dbapi.PgBouncer, ctx context.Context, auth *Auth, dbName string, listenPort rune) (*XormClientList, error) {
@@ -18,12 +18,21 @@ package pgpool
 
 import (
 	"context"
-
+	"crypto/tls"
 	"fmt"
+	"strconv"
+
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
 
 	olddbapi "kubedb.dev/apimachinery/apis/kubedb/v1alpha2"
 
 	_ "github.com/lib/pq"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/klog/v2"
@@ -31,6 +40,7 @@ import (
 	appbinding "kmodules.xyz/custom-resources/apis/appcatalog/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"xorm.io/xorm"
+	xormcore "xorm.io/xorm/core"
 )
 
 const (
@@ -39,12 +49,19 @@ const (
 )
 
 type KubeDBClientBuilder struct {
-	kc            client.Client
-	pgpool        *olddbapi.Pgpool
-	url           string
-	podName       string
-	backendDBName string
-	ctx           context.Context
+	kc             client.Client
+	pgpool         *olddbapi.Pgpool
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	backendDBName  string
+	certs          *certholder.ResourceCerts
+	tlsConfig      *tls.Config
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, pp *olddbapi.Pgpool) *KubeDBClientBuilder {
@@ -59,6 +76,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	return o
@@ -74,28 +98,66 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithCerts makes the builder write the client certificate secret to the
+// given certholder and pass the file paths to the driver, instead of keeping
+// the certificates in memory.
+func (o *KubeDBClientBuilder) WithCerts(certs *certholder.ResourceCerts) *KubeDBClientBuilder {
+	o.certs = certs
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetPgpoolXormClient() (*XormClient, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(olddbapi.ResourceKindPgpool, o.pgpool.Namespace, o.pgpool.Name, o.podName)...)
 
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
 	}
 
-	engine, err := xorm.NewEngine("postgres", connector)
+	db, err := dsn.OpenPostgres(connector, nil, o.tlsConfig)
 	if err != nil {
 		return nil, err
 	}
-	_, err = engine.Query("SELECT 1")
+	engine, err := xorm.NewEngineWithDB("postgres", dsn.XormPostgres(o.backendDBName), xormcore.FromDB(db))
 	if err != nil {
-		err = engine.Close()
-		if err != nil {
-			return nil, err
-		}
 		return nil, err
 	}
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			_, err := engine.Query("SELECT 1")
+			return err
+		})
+	})
+	if err != nil {
+		closeErr := engine.Close()
+		if closeErr != nil {
+			klog.Errorf("Failed to close client. error: %v", closeErr)
+		}
+		return nil, dberrors.Classify(err)
+	}
 
 	engine.SetDefaultContext(o.ctx)
 	return &XormClient{
@@ -103,10 +165,6 @@ func (o *KubeDBClientBuilder) GetPgpoolXormClient() (*XormClient, error) {
 	}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.pgpool.GoverningServiceName(), o.pgpool.Namespace)
-}
-
 func (o *KubeDBClientBuilder) getBackendAuth() (string, string, error) {
 	pp := o.pgpool
 	if pp.Spec.PostgresRef == nil {
@@ -121,28 +179,30 @@ func (o *KubeDBClientBuilder) getBackendAuth() (string, string, error) {
 		return "", "", err
 	}
 	if apb.Spec.Secret == nil {
-		return "", "", fmt.Errorf("backend postgres auth secret unspecified for pgpool %s/%s", pp.Namespace, pp.Name)
+		return "", "", dberrors.Errorf(dberrors.ErrSecretNotFound, "backend postgres auth secret unspecified for pgpool %s/%s", pp.Namespace, pp.Name)
 	}
 
 	var secret core.Secret
-	err = o.kc.Get(o.ctx, client.ObjectKey{Namespace: pp.Spec.PostgresRef.Namespace, Name: apb.Spec.Secret.Name}, &secret)
+	err = o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: pp.Spec.PostgresRef.Namespace, Name: apb.Spec.Secret.Name}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	user, ok := secret.Data[core.BasicAuthUsernameKey]
 	if !ok {
-		return "", "", fmt.Errorf("error getting backend username")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "error getting backend username")
 	}
 	pass, ok := secret.Data[core.BasicAuthPasswordKey]
 	if !ok {
-		return "", "", fmt.Errorf("error getting backend password")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "error getting backend password")
 	}
 	return string(user), string(pass), nil
 }
 
 func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodHost(o.podName, o.pgpool.GoverningServiceName(), o.pgpool.Namespace)
 	}
 	dnsName := o.url
 
@@ -152,9 +212,8 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 
 	user, pass, err := o.getBackendAuth()
 	if err != nil {
-		return "", fmt.Errorf("DB basic auth is not found for backend PostgreSQL %v/%v", o.pgpool.Namespace, o.pgpool.Name)
+		return "", fmt.Errorf("DB basic auth is not found for backend PostgreSQL %v/%v: %w", o.pgpool.Namespace, o.pgpool.Name, err)
 	}
-	cnnstr := ""
 	sslMode := o.pgpool.Spec.SSLMode
 
 	//  sslMode == "prefer" and sslMode == "allow"  don't have support for github.com/lib/pq postgres client. as we are using
@@ -163,29 +222,50 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	if sslMode == olddbapi.PgpoolSSLModePrefer || sslMode == olddbapi.PgpoolSSLModeAllow {
 		sslMode = olddbapi.PgpoolSSLModeRequire
 	}
+	params := []dsn.PostgresParam{
+		{Key: "user", Value: user},
+		{Key: "password", Value: pass},
+		{Key: "host", Value: dnsName},
+		{Key: "port", Value: strconv.Itoa(o.resolver.Port(olddbapi.ResourceKindPgpool, DefaultPgpoolPort))},
+		{Key: "connect_timeout", Value: "10"},
+		{Key: "dbname", Value: o.backendDBName},
+		{Key: "sslmode", Value: string(sslMode)},
+	}
 	if o.pgpool.Spec.TLS != nil {
-		secretName := o.pgpool.GetCertSecretName(olddbapi.PgpoolClientCert)
+		err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			secretName := o.pgpool.GetCertSecretName(olddbapi.PgpoolClientCert)
 
-		var certSecret core.Secret
-		err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.pgpool.Namespace, Name: secretName}, &certSecret)
-		if err != nil {
-			klog.Error(err, "failed to get certificate secret.", secretName)
-			return "", err
-		}
+			var certSecret core.Secret
+			err := o.kc.Get(ctx, client.ObjectKey{Namespace: o.pgpool.Namespace, Name: secretName}, &certSecret)
+			if err != nil {
+				klog.Error(err, "failed to get certificate secret.", secretName)
+				return dberrors.SecretGetError(err)
+			}
 
-		certs, _ := certholder.DefaultHolder.ForResource(olddbapi.SchemeGroupVersion.WithResource(olddbapi.ResourcePluralPgpool), o.pgpool.ObjectMeta)
-		paths, err := certs.Save(&certSecret)
+			if o.certs == nil {
+				params, o.tlsConfig, err = dsn.PostgresTLS(&certSecret, params, o.pgpool.Spec.ClientAuthMode == olddbapi.PgpoolClientAuthModeCert)
+				if err != nil {
+					return err
+				}
+			} else {
+				paths, err := o.certs.Save(&certSecret)
+				if err != nil {
+					klog.Error(err, "failed to save certificate")
+					return err
+				}
+				params = append(params, dsn.PostgresParam{Key: "sslrootcert", Value: paths.CACert})
+				if o.pgpool.Spec.ClientAuthMode == olddbapi.PgpoolClientAuthModeCert {
+					params = append(params,
+						dsn.PostgresParam{Key: "sslcert", Value: paths.Cert},
+						dsn.PostgresParam{Key: "sslkey", Value: paths.Key},
+					)
+				}
+			}
+			return nil
+		})
 		if err != nil {
-			klog.Error(err, "failed to save certificate")
 			return "", err
 		}
-		if o.pgpool.Spec.ClientAuthMode == olddbapi.PgpoolClientAuthModeCert {
-			cnnstr = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s sslrootcert=%s sslcert=%s sslkey=%s", user, pass, dnsName, DefaultPgpoolPort, o.backendDBName, sslMode, paths.CACert, paths.Cert, paths.Key)
-		} else {
-			cnnstr = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s sslrootcert=%s", user, pass, dnsName, DefaultPgpoolPort, o.backendDBName, sslMode, paths.CACert)
-		}
-	} else {
-		cnnstr = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s", user, pass, dnsName, DefaultPgpoolPort, o.backendDBName, sslMode)
 	}
-	return cnnstr, nil
+	return dsn.FormatPostgres(params...), nil
 }
@@ -0,0 +1,144 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	"k8s.io/klog/v2"
+)
+
+// conn is the data stream of a forwarded port. The stream does not support
+// deadlines, so it is copied through a pipe, which does.
+type conn struct {
+	net.Conn
+	data       httpstream.Stream
+	connection httpstream.Connection
+	target     *podTarget
+
+	mu        sync.Mutex
+	err       error
+	closeOnce sync.Once
+}
+
+var _ net.Conn = &conn{}
+
+func newConn(connection httpstream.Connection, target *podTarget, requestID int) (*conn, error) {
+	headers := http.Header{}
+	headers.Set(core.StreamType, core.StreamTypeError)
+	headers.Set(core.PortHeader, strconv.Itoa(int(target.port)))
+	headers.Set(core.PortForwardRequestIDHeader, strconv.Itoa(requestID))
+	errorStream, err := connection.CreateStream(headers)
+	if err != nil {
+		_ = connection.Close()
+		return nil, fmt.Errorf("failed to create error stream for pod %s: %w", target, err)
+	}
+	// nothing is written to the error stream
+	_ = errorStream.Close()
+
+	headers.Set(core.StreamType, core.StreamTypeData)
+	data, err := connection.CreateStream(headers)
+	if err != nil {
+		_ = connection.Close()
+		return nil, fmt.Errorf("failed to create data stream for pod %s: %w", target, err)
+	}
+
+	local, remote := net.Pipe()
+	c := &conn{
+		Conn:       local,
+		data:       data,
+		connection: connection,
+		target:     target,
+	}
+	go func() {
+		_, _ = io.Copy(remote, data)
+		_ = remote.Close()
+	}()
+	go func() {
+		_, _ = io.Copy(data, remote)
+		_ = data.Close()
+	}()
+	go c.watchErrors(errorStream)
+	return c, nil
+}
+
+func (c *conn) watchErrors(errorStream httpstream.Stream) {
+	message, err := io.ReadAll(errorStream)
+	switch {
+	case err != nil:
+		err = fmt.Errorf("failed to read error stream of pod %s: %w", c.target, err)
+	case len(message) > 0:
+		err = fmt.Errorf("failed to forward port of pod %s: %s", c.target, string(message))
+	default:
+		return
+	}
+	klog.Errorln(err)
+	c.mu.Lock()
+	c.err = err
+	c.mu.Unlock()
+	_ = c.Close()
+}
+
+func (c *conn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if err != nil && !errors.Is(err, os.ErrDeadlineExceeded) {
+		c.mu.Lock()
+		if c.err != nil {
+			err = c.err
+		}
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+func (c *conn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		_ = c.Conn.Close()
+		_ = c.data.Close()
+		c.connection.RemoveStreams(c.data)
+		err = c.connection.Close()
+	})
+	return err
+}
+
+func (c *conn) LocalAddr() net.Addr {
+	return addr("localhost")
+}
+
+func (c *conn) RemoteAddr() net.Addr {
+	return addr(c.target.String())
+}
+
+type addr string
+
+func (a addr) Network() string {
+	return "portforward"
+}
+
+func (a addr) String() string {
+	return string(a)
+}
@@ -0,0 +1,119 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+)
+
+// fakeStream is a stream backed by one end of a pipe, the other end is the
+// pod.
+type fakeStream struct {
+	net.Conn
+	headers http.Header
+}
+
+func (s *fakeStream) Reset() error         { return s.Close() }
+func (s *fakeStream) Headers() http.Header { return s.headers }
+func (s *fakeStream) Identifier() uint32   { return 0 }
+
+// emptyStream is an error stream without errors. Like a stream, its Close
+// only closes the write side.
+type emptyStream struct {
+	fakeStream
+}
+
+func (s *emptyStream) Read([]byte) (int, error)    { return 0, io.EOF }
+func (s *emptyStream) Write(b []byte) (int, error) { return len(b), nil }
+func (s *emptyStream) Close() error                { return nil }
+
+// fakeConnection creates an empty error stream and a data stream connected
+// to pod.
+type fakeConnection struct {
+	pod    net.Conn
+	closed chan bool
+}
+
+func (f *fakeConnection) CreateStream(headers http.Header) (httpstream.Stream, error) {
+	if headers.Get(core.StreamType) == core.StreamTypeError {
+		return &emptyStream{fakeStream{headers: headers.Clone()}}, nil
+	}
+	stream, pod := net.Pipe()
+	f.pod = pod
+	return &fakeStream{Conn: stream, headers: headers.Clone()}, nil
+}
+
+func (f *fakeConnection) Close() error {
+	close(f.closed)
+	return nil
+}
+
+func (f *fakeConnection) CloseChan() <-chan bool             { return f.closed }
+func (f *fakeConnection) SetIdleTimeout(time.Duration)       {}
+func (f *fakeConnection) RemoveStreams(...httpstream.Stream) {}
+
+func TestConnDeadline(t *testing.T) {
+	connection := &fakeConnection{closed: make(chan bool)}
+	c, err := newConn(connection, &podTarget{namespace: "demo", name: "mysql-0", port: 3306}, 0)
+	if err != nil {
+		t.Fatalf("failed to create conn: %v", err)
+	}
+	defer c.Close()
+
+	go func() {
+		_, _ = connection.pod.Write([]byte("hello"))
+	}()
+	buf := make([]byte, 5)
+	if _, err := io.ReadFull(c, buf); err != nil || string(buf) != "hello" {
+		t.Fatalf("got %q and error %v, want hello", buf, err)
+	}
+
+	if err := c.SetReadDeadline(time.Now().Add(50 * time.Millisecond)); err != nil {
+		t.Fatalf("failed to set read deadline: %v", err)
+	}
+	_, err = c.Read(buf)
+	var netErr net.Error
+	if !errors.Is(err, os.ErrDeadlineExceeded) || !errors.As(err, &netErr) || !netErr.Timeout() {
+		t.Errorf("got error %v, want a timeout", err)
+	}
+
+	if err := c.SetDeadline(time.Time{}); err != nil {
+		t.Fatalf("failed to clear deadline: %v", err)
+	}
+	go func() {
+		_, _ = c.Write([]byte("ping"))
+	}()
+	if _, err := io.ReadFull(connection.pod, buf[:4]); err != nil || string(buf[:4]) != "ping" {
+		t.Fatalf("got %q and error %v, want ping", buf[:4], err)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatalf("failed to close conn: %v", err)
+	}
+	if _, err := connection.pod.Read(buf); !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("got error %v after close, want EOF", err)
+	}
+}
@@ -0,0 +1,284 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package portforward dials database pods through the port-forward API of
+// the Kubernetes API server, so that the clients of this library can be used
+// from outside the cluster.
+package portforward
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/httpstream"
+	pfconst "k8s.io/apimachinery/pkg/util/portforward"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/transport/spdy"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DialFunc connects to address on the named network, like net.Dialer.DialContext.
+// It also implements the dialer interfaces expected by the database drivers.
+type DialFunc func(ctx context.Context, network, address string) (net.Conn, error)
+
+func (d DialFunc) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	return d(ctx, network, address)
+}
+
+func (d DialFunc) Dial(network, address string) (net.Conn, error) {
+	return d(context.Background(), network, address)
+}
+
+func (d DialFunc) DialTimeout(network, address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return d(ctx, network, address)
+}
+
+// Dialer opens one port-forward stream per connection. The target pod is
+// resolved from the in-cluster address being dialed:
+//
+//	<pod>[.<cluster-id>].<governing-service>.<namespace>.svc[.<cluster-domain>]:<port>
+//	<service>.<namespace>.svc[.<cluster-domain>]:<port>
+//	<pod-ip>:<port>
+//
+// Addresses of a service are forwarded to one of its ready endpoints, pod IPs
+// are looked up in the default namespace of the Dialer. Other addresses are
+// dialed directly.
+type Dialer struct {
+	config    *rest.Config
+	kc        client.Client
+	namespace string
+	requestID atomic.Int32
+}
+
+// NewDialer returns a Dialer that uses config to connect to the API server and
+// kc to resolve services and pod IPs. namespace is used for pod IPs.
+func NewDialer(config *rest.Config, kc client.Client, namespace string) (*Dialer, error) {
+	if config == nil {
+		return nil, fmt.Errorf("rest config is nil")
+	}
+	if kc == nil {
+		return nil, fmt.Errorf("kubernetes client is nil")
+	}
+	return &Dialer{
+		config:    config,
+		kc:        kc,
+		namespace: namespace,
+	}, nil
+}
+
+func (d *Dialer) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	switch network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return nil, fmt.Errorf("network %s is not supported by port-forward", network)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	host, portStr, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid port in address %s: %w", address, err)
+	}
+
+	target, err := d.resolve(ctx, host, int32(port))
+	if err != nil {
+		return nil, err
+	}
+	if target == nil {
+		var nd net.Dialer
+		return nd.DialContext(ctx, network, address)
+	}
+	return d.dialPod(ctx, target)
+}
+
+type podTarget struct {
+	namespace string
+	name      string
+	port      int32
+}
+
+func (t *podTarget) String() string {
+	return fmt.Sprintf("%s/%s:%d", t.namespace, t.name, t.port)
+}
+
+// resolve returns nil when host is not an in-cluster address.
+func (d *Dialer) resolve(ctx context.Context, host string, port int32) (*podTarget, error) {
+	if ip := net.ParseIP(host); ip != nil {
+		return d.resolvePodIP(ctx, host, port)
+	}
+
+	labels := strings.Split(strings.TrimSuffix(host, "."), ".")
+	svc := -1
+	for i, l := range labels {
+		if l == "svc" {
+			svc = i
+			break
+		}
+	}
+	switch svc {
+	case 4:
+		return &podTarget{namespace: labels[3], name: labels[0], port: port}, nil
+	case 3:
+		return &podTarget{namespace: labels[2], name: labels[0], port: port}, nil
+	case 2:
+		return d.resolveService(ctx, labels[1], labels[0], port)
+	}
+	return nil, nil
+}
+
+// podIPField is the field selector of the pod ip, supported by the API
+// server. A cache must index the pods with it, e.g. with
+// IndexField(ctx, &core.Pod{}, "status.podIP", ...).
+const podIPField = "status.podIP"
+
+func (d *Dialer) resolvePodIP(ctx context.Context, ip string, port int32) (*podTarget, error) {
+	if d.namespace == "" {
+		return nil, nil
+	}
+	// a cached client only supports the field selector when the pods are
+	// indexed by ip, otherwise all the pods are listed
+	var pods core.PodList
+	err := d.kc.List(ctx, &pods, client.InNamespace(d.namespace), client.MatchingFields{podIPField: ip})
+	if err != nil {
+		klog.V(5).Infof("failed to list pods of namespace %s by ip, listing all the pods, reason: %s", d.namespace, err)
+		pods = core.PodList{}
+		if err := d.kc.List(ctx, &pods, client.InNamespace(d.namespace)); err != nil {
+			return nil, err
+		}
+	}
+	for _, pod := range pods.Items {
+		if pod.Status.PodIP == ip {
+			return &podTarget{namespace: pod.Namespace, name: pod.Name, port: port}, nil
+		}
+	}
+	return nil, nil
+}
+
+// resolveService returns a ready pod of the service and the target port of
+// the service port.
+func (d *Dialer) resolveService(ctx context.Context, namespace, name string, port int32) (*podTarget, error) {
+	var svc core.Service
+	if err := d.kc.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &svc); err != nil {
+		return nil, fmt.Errorf("failed to get service %s/%s: %w", namespace, name, err)
+	}
+	portName := ""
+	for _, p := range svc.Spec.Ports {
+		if p.Port == port {
+			portName = p.Name
+			break
+		}
+	}
+
+	var endpoints core.Endpoints
+	if err := d.kc.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, &endpoints); err != nil {
+		return nil, fmt.Errorf("failed to get endpoints of service %s/%s: %w", namespace, name, err)
+	}
+	for _, subset := range endpoints.Subsets {
+		targetPort := port
+		for _, p := range subset.Ports {
+			if p.Name == portName {
+				targetPort = p.Port
+				break
+			}
+		}
+		for _, addr := range subset.Addresses {
+			if addr.TargetRef != nil && addr.TargetRef.Kind == "Pod" {
+				return &podTarget{namespace: namespace, name: addr.TargetRef.Name, port: targetPort}, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("service %s/%s has no ready pod", namespace, name)
+}
+
+// dialPod opens a port-forward connection to the target pod. ctx bounds the
+// SPDY upgrade only, not the lifetime of the returned connection.
+func (d *Dialer) dialPod(ctx context.Context, target *podTarget) (net.Conn, error) {
+	transport, upgrader, err := spdy.RoundTripperFor(d.config)
+	if err != nil {
+		return nil, err
+	}
+	hostURL, _, err := rest.DefaultServerUrlFor(d.config)
+	if err != nil {
+		return nil, err
+	}
+	hostURL.Path = path.Join(hostURL.Path, "api", "v1", "namespaces", target.namespace, "pods", target.name, "portforward")
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hostURL.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	// the upgrade response is read from the raw connection, which ignores
+	// the request context, so the upgrade is abandoned when ctx is done
+	ch := make(chan upgradeResult, 1)
+	go func() {
+		connection, protocol, err := spdy.Negotiate(upgrader, &http.Client{Transport: transport}, req, pfconst.PortForwardV1Name)
+		ch <- upgradeResult{connection: connection, protocol: protocol, err: err}
+	}()
+	var r upgradeResult
+	select {
+	case r = <-ch:
+	case <-ctx.Done():
+		go func() {
+			if r := <-ch; r.connection != nil {
+				_ = r.connection.Close()
+			}
+		}()
+		return nil, fmt.Errorf("failed to port-forward to pod %s: %w", target, ctx.Err())
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("failed to port-forward to pod %s: %w", target, r.err)
+	}
+	if r.protocol != pfconst.PortForwardV1Name {
+		_ = r.connection.Close()
+		return nil, fmt.Errorf("unexpected port-forward protocol %q for pod %s", r.protocol, target)
+	}
+	return newConn(r.connection, target, int(d.requestID.Add(1)))
+}
+
+type upgradeResult struct {
+	connection httpstream.Connection
+	protocol   string
+	err        error
+}
+
+// DialFuncFor returns the dialer configured on a client builder: dial when it
+// is set, else a port-forward Dialer when config is set, else nil.
+func DialFuncFor(dial DialFunc, config *rest.Config, kc client.Client, namespace string) (DialFunc, error) {
+	if dial != nil || config == nil {
+		return dial, nil
+	}
+	d, err := NewDialer(config, kc, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return d.DialContext, nil
+}
@@ -0,0 +1,238 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package portforward
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	core "k8s.io/api/core/v1"
+	kerr "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// fakeClient serves the objects used by the Dialer, the other methods of
+// client.Client are not implemented.
+type fakeClient struct {
+	client.Client
+	services  map[client.ObjectKey]core.Service
+	endpoints map[client.ObjectKey]core.Endpoints
+	pods      []core.Pod
+	// unindexed fails the lists with a field selector, like a cache without
+	// the index
+	unindexed bool
+	lists     int
+}
+
+func (f *fakeClient) Get(_ context.Context, key client.ObjectKey, obj client.Object, _ ...client.GetOption) error {
+	switch o := obj.(type) {
+	case *core.Service:
+		if svc, ok := f.services[key]; ok {
+			*o = svc
+			return nil
+		}
+		return kerr.NewNotFound(schema.GroupResource{Resource: "services"}, key.Name)
+	case *core.Endpoints:
+		if ep, ok := f.endpoints[key]; ok {
+			*o = ep
+			return nil
+		}
+		return kerr.NewNotFound(schema.GroupResource{Resource: "endpoints"}, key.Name)
+	}
+	return kerr.NewNotFound(schema.GroupResource{}, key.Name)
+}
+
+func (f *fakeClient) List(_ context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	var lo client.ListOptions
+	lo.ApplyOptions(opts)
+	var ip string
+	if lo.FieldSelector != nil {
+		if f.unindexed {
+			return errors.New("index with name field:status.podIP does not exist")
+		}
+		ip, _ = lo.FieldSelector.RequiresExactMatch(podIPField)
+	}
+	f.lists++
+	pods := list.(*core.PodList)
+	for _, pod := range f.pods {
+		if pod.Namespace == lo.Namespace && (ip == "" || pod.Status.PodIP == ip) {
+			pods.Items = append(pods.Items, pod)
+		}
+	}
+	return nil
+}
+
+func newFakeClient() *fakeClient {
+	key := client.ObjectKey{Namespace: "demo", Name: "mysql"}
+	return &fakeClient{
+		services: map[client.ObjectKey]core.Service{
+			key: {
+				ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "mysql"},
+				Spec: core.ServiceSpec{
+					Ports: []core.ServicePort{{Name: "primary", Port: 3306}},
+				},
+			},
+		},
+		endpoints: map[client.ObjectKey]core.Endpoints{
+			key: {
+				ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "mysql"},
+				Subsets: []core.EndpointSubset{{
+					Addresses: []core.EndpointAddress{{
+						IP:        "10.0.0.2",
+						TargetRef: &core.ObjectReference{Kind: "Pod", Name: "mysql-1"},
+					}},
+					Ports: []core.EndpointPort{{Name: "primary", Port: 13306}},
+				}},
+			},
+		},
+		pods: []core.Pod{{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "demo", Name: "mysql-0"},
+			Status:     core.PodStatus{PodIP: "10.0.0.1"},
+		}},
+	}
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		name string
+		host string
+		want *podTarget
+	}{
+		{
+			name: "pod",
+			host: "mysql-0.mysql-pods.demo.svc",
+			want: &podTarget{namespace: "demo", name: "mysql-0", port: 3306},
+		},
+		{
+			name: "pod with cluster domain",
+			host: "mysql-0.mysql-pods.demo.svc.cluster.local.",
+			want: &podTarget{namespace: "demo", name: "mysql-0", port: 3306},
+		},
+		{
+			name: "pod with cluster id",
+			host: "mysql-0.west.mysql-pods.demo.svc",
+			want: &podTarget{namespace: "demo", name: "mysql-0", port: 3306},
+		},
+		{
+			name: "service",
+			host: "mysql.demo.svc.cluster.local",
+			want: &podTarget{namespace: "demo", name: "mysql-1", port: 13306},
+		},
+		{
+			name: "pod ip",
+			host: "10.0.0.1",
+			want: &podTarget{namespace: "demo", name: "mysql-0", port: 3306},
+		},
+		{
+			name: "unknown pod ip",
+			host: "10.0.0.9",
+		},
+		{
+			name: "external host",
+			host: "mysql.example.com",
+		},
+	}
+	d, err := NewDialer(&rest.Config{}, newFakeClient(), "demo")
+	if err != nil {
+		t.Fatalf("failed to create dialer: %v", err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := d.resolve(context.Background(), tt.host, 3306)
+			if err != nil {
+				t.Fatalf("failed to resolve %s: %v", tt.host, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got target %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolvePodIPUnindexed(t *testing.T) {
+	kc := newFakeClient()
+	kc.unindexed = true
+	d, err := NewDialer(&rest.Config{}, kc, "demo")
+	if err != nil {
+		t.Fatalf("failed to create dialer: %v", err)
+	}
+	got, err := d.resolve(context.Background(), "10.0.0.1", 3306)
+	if err != nil {
+		t.Fatalf("failed to resolve pod ip: %v", err)
+	}
+	want := &podTarget{namespace: "demo", name: "mysql-0", port: 3306}
+	if !reflect.DeepEqual(got, want) || kc.lists != 1 {
+		t.Errorf("got target %v after %d lists, want %v after 1", got, kc.lists, want)
+	}
+}
+
+func TestResolveServiceWithoutPods(t *testing.T) {
+	kc := newFakeClient()
+	key := client.ObjectKey{Namespace: "demo", Name: "mysql"}
+	kc.endpoints[key] = core.Endpoints{}
+	d, err := NewDialer(&rest.Config{}, kc, "demo")
+	if err != nil {
+		t.Fatalf("failed to create dialer: %v", err)
+	}
+	if _, err := d.resolve(context.Background(), "mysql.demo.svc", 3306); err == nil {
+		t.Error("expected an error for a service without ready pods")
+	}
+	if _, err := d.resolve(context.Background(), "missing.demo.svc", 3306); err == nil {
+		t.Error("expected an error for a missing service")
+	}
+}
+
+func TestDialPodContext(t *testing.T) {
+	done := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}))
+	defer server.Close()
+	defer close(done)
+
+	d, err := NewDialer(&rest.Config{Host: server.URL}, newFakeClient(), "demo")
+	if err != nil {
+		t.Fatalf("failed to create dialer: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, err := d.dialPod(ctx, &podTarget{namespace: "demo", name: "mysql-0", port: 3306})
+		errCh <- err
+	}()
+	select {
+	case err := <-errCh:
+		if err == nil {
+			t.Error("expected an error when the context expires")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("dialPod did not return after the context expired")
+	}
+}
@@ -18,17 +18,28 @@ package postgres
 
 import (
 	"context"
-	"database/sql"
-	"errors"
+	"crypto/tls"
 	"fmt"
+	"strconv"
+
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+	"kubedb.dev/db-client-go/retry"
 
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 	"kmodules.xyz/client-go/tools/certholder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"xorm.io/xorm"
+	xormcore "xorm.io/xorm/core"
 )
 
 const (
@@ -36,12 +47,21 @@ const (
 )
 
 type KubeDBClientBuilder struct {
-	kc         client.Client
-	db         *dbapi.Postgres
-	url        string
-	podName    string
-	postgresDB string
-	ctx        context.Context
+	kc             client.Client
+	db             *dbapi.Postgres
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	postgresDB     string
+	certs          *certholder.ResourceCerts
+	tlsConfig      *tls.Config
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.Postgres) *KubeDBClientBuilder {
@@ -61,6 +81,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPostgresDB(pgDB string) *KubeDBClientBuilder {
 	o.postgresDB = pgDB
 	return o
@@ -71,39 +98,94 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
+// WithCerts makes the builder write the client certificate secret to the
+// given certholder and pass the file paths to the driver, instead of keeping
+// the certificates in memory.
+func (o *KubeDBClientBuilder) WithCerts(certs *certholder.ResourceCerts) *KubeDBClientBuilder {
+	o.certs = certs
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetPostgresXormClient() (*XormClient, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindPostgres, o.db.Namespace, o.db.Name, o.podName)...)
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
 	}
 
-	engine, err := xorm.NewEngine("postgres", connector)
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	db, err := dsn.OpenPostgres(connector, dial, o.tlsConfig)
+	if err != nil {
+		return nil, err
+	}
+	engine, err := xorm.NewEngineWithDB("postgres", dsn.XormPostgres(o.postgresDB), xormcore.FromDB(db))
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate postgres client using connection string: %v", err)
 	}
-	_, err = engine.Query("SELECT 1")
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			_, err := engine.Query("SELECT 1")
+			return err
+		})
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to run query: %v", err)
+		return nil, fmt.Errorf("failed to run query: %w", dberrors.Classify(err))
 	}
 	engine.SetDefaultContext(o.ctx)
 	return &XormClient{engine}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.db.GoverningServiceName(), o.db.Namespace)
-}
-
 func (o *KubeDBClientBuilder) getPostgresAuthCredentials() (string, string, error) {
 	if o.db.Spec.AuthSecret == nil {
-		return "", "", errors.New("no database secret")
+		return "", "", dberrors.Errorf(dberrors.ErrSecretNotFound, "no database secret")
 	}
 	var secret core.Secret
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.AuthSecret.Name}, &secret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.AuthSecret.Name}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	return string(secret.Data[core.BasicAuthUsernameKey]), string(secret.Data[core.BasicAuthPasswordKey]), nil
 }
@@ -112,12 +194,18 @@ func (o *KubeDBClientBuilder) GetPostgresClient() (*Client, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindPostgres, o.db.Namespace, o.db.Name, o.podName)...)
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
 	}
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
+	if err != nil {
+		return nil, err
+	}
 	// connect to database
-	db, err := sql.Open("postgres", connector)
+	db, err := dsn.OpenPostgres(connector, dial, o.tlsConfig)
 	if err != nil {
 		return nil, err
 	}
@@ -125,12 +213,14 @@ func (o *KubeDBClientBuilder) GetPostgresClient() (*Client, error) {
 	// ping to database to check the connection
 	// ctx, cancel := context.WithTimeout(context.Background(), time.Second)
 	// defer cancel()
-	if err := db.PingContext(o.ctx); err != nil {
+	if err := o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, db.PingContext)
+	}); err != nil {
 		closeErr := db.Close()
 		if closeErr != nil {
 			klog.Errorf("Failed to close client. error: %v", closeErr)
 		}
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	return &Client{db}, nil
@@ -138,10 +228,10 @@ func (o *KubeDBClientBuilder) GetPostgresClient() (*Client, error) {
 
 func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodHost(o.podName, o.db.GoverningServiceName(), o.db.Namespace)
 	}
 	dnsName := o.url
-	port := 5432
+	port := o.resolver.Port(dbapi.ResourceKindPostgres, 5432)
 
 	if o.postgresDB == "" {
 		o.postgresDB = DefaultPostgresDB
@@ -149,9 +239,8 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 
 	user, pass, err := o.getPostgresAuthCredentials()
 	if err != nil {
-		return "", fmt.Errorf("DB basic auth is not found for PostgreSQL %v/%v", o.db.Namespace, o.db.Name)
+		return "", fmt.Errorf("DB basic auth is not found for PostgreSQL %v/%v: %w", o.db.Namespace, o.db.Name, err)
 	}
-	cnnstr := ""
 	sslMode := o.db.Spec.SSLMode
 
 	//  sslMode == "prefer" and sslMode == "allow"  don't have support for github.com/lib/pq postgres client. as we are using
@@ -160,29 +249,50 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	if sslMode == "prefer" || sslMode == "allow" {
 		sslMode = "require"
 	}
+	params := []dsn.PostgresParam{
+		{Key: "user", Value: user},
+		{Key: "password", Value: pass},
+		{Key: "host", Value: dnsName},
+		{Key: "port", Value: strconv.Itoa(port)},
+		{Key: "connect_timeout", Value: "10"},
+		{Key: "dbname", Value: o.postgresDB},
+		{Key: "sslmode", Value: string(sslMode)},
+	}
 	if o.db.Spec.TLS != nil {
-		secretName := o.db.GetCertSecretName(dbapi.PostgresClientCert)
+		err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			secretName := o.db.GetCertSecretName(dbapi.PostgresClientCert)
 
-		var certSecret core.Secret
-		err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: secretName}, &certSecret)
-		if err != nil {
-			klog.Error(err, "failed to get certificate secret.", secretName)
-			return "", err
-		}
+			var certSecret core.Secret
+			err := o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: secretName}, &certSecret)
+			if err != nil {
+				klog.Error(err, "failed to get certificate secret.", secretName)
+				return dberrors.SecretGetError(err)
+			}
 
-		certs, _ := certholder.DefaultHolder.ForResource(dbapi.SchemeGroupVersion.WithResource(dbapi.ResourcePluralPostgres), o.db.ObjectMeta)
-		paths, err := certs.Save(&certSecret)
+			if o.certs == nil {
+				params, o.tlsConfig, err = dsn.PostgresTLS(&certSecret, params, o.db.Spec.ClientAuthMode == dbapi.ClientAuthModeCert)
+				if err != nil {
+					return err
+				}
+			} else {
+				paths, err := o.certs.Save(&certSecret)
+				if err != nil {
+					klog.Error(err, "failed to save certificate")
+					return err
+				}
+				params = append(params, dsn.PostgresParam{Key: "sslrootcert", Value: paths.CACert})
+				if o.db.Spec.ClientAuthMode == dbapi.ClientAuthModeCert {
+					params = append(params,
+						dsn.PostgresParam{Key: "sslcert", Value: paths.Cert},
+						dsn.PostgresParam{Key: "sslkey", Value: paths.Key},
+					)
+				}
+			}
+			return nil
+		})
 		if err != nil {
-			klog.Error(err, "failed to save certificate")
 			return "", err
 		}
-		if o.db.Spec.ClientAuthMode == dbapi.ClientAuthModeCert {
-			cnnstr = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s sslrootcert=%s sslcert=%s sslkey=%s", user, pass, dnsName, port, o.postgresDB, sslMode, paths.CACert, paths.Cert, paths.Key)
-		} else {
-			cnnstr = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s sslrootcert=%s", user, pass, dnsName, port, o.postgresDB, sslMode, paths.CACert)
-		}
-	} else {
-		cnnstr = fmt.Sprintf("user=%s password=%s host=%s port=%d connect_timeout=10 dbname=%s sslmode=%s", user, pass, dnsName, port, o.postgresDB, sslMode)
 	}
-	return cnnstr, nil
+	return dsn.FormatPostgres(params...), nil
 }
@@ -19,11 +19,18 @@ package proxysql
 import (
 	"context"
 	"database/sql"
-	"fmt"
+
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
 
 	"kubedb.dev/apimachinery/apis/kubedb"
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,11 +38,16 @@ import (
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *dbapi.ProxySQL
-	url     string
-	podName string
-	ctx     context.Context
+	kc             client.Client
+	db             *dbapi.ProxySQL
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.ProxySQL) *KubeDBClientBuilder {
@@ -50,6 +62,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	return o
@@ -60,10 +79,31 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetProxySQLClient() (*Client, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindProxySQL, o.db.Namespace, o.db.Name, o.podName)...)
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -76,12 +116,14 @@ func (o *KubeDBClientBuilder) GetProxySQLClient() (*Client, error) {
 	}
 
 	// ping to database to check the connection
-	if err := db.PingContext(o.ctx); err != nil {
+	if err := o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, db.PingContext)
+	}); err != nil {
 		closeErr := db.Close()
 		if closeErr != nil {
 			klog.Errorf("Failed to close client. error: %v", closeErr)
 		}
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	return &Client{db}, nil
@@ -91,6 +133,8 @@ func (o *KubeDBClientBuilder) GetProxySQLXormClient() (*XormClient, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindProxySQL, o.db.Namespace, o.db.Name, o.podName)...)
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -99,9 +143,14 @@ func (o *KubeDBClientBuilder) GetProxySQLXormClient() (*XormClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	_, err = engine.Query("SELECT 1")
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			_, err := engine.Query("SELECT 1")
+			return err
+		})
+	})
 	if err != nil {
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 	engine.SetDefaultContext(o.ctx)
 	return &XormClient{
@@ -109,10 +158,6 @@ func (o *KubeDBClientBuilder) GetProxySQLXormClient() (*XormClient, error) {
 	}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.db.GoverningServiceName(), o.db.Namespace)
-}
-
 func (o *KubeDBClientBuilder) getProxySQLRootCredentials() (string, string, error) {
 	db := o.db
 	var secretName string
@@ -120,17 +165,19 @@ func (o *KubeDBClientBuilder) getProxySQLRootCredentials() (string, string, erro
 		secretName = db.GetAuthSecretName()
 	}
 	var secret core.Secret
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	user, ok := secret.Data[core.BasicAuthUsernameKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root user is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root user is not set")
 	}
 	pass, ok := secret.Data[core.BasicAuthPasswordKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root password is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root password is not set")
 	}
 	return string(user), string(pass), nil
 }
@@ -142,9 +189,8 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	}
 
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodHost(o.podName, o.db.GoverningServiceName(), o.db.Namespace)
 	}
 
-	connector := fmt.Sprintf("%v:%v@tcp(%s:%d)/", user, pass, o.url, kubedb.ProxySQLAdminPort)
-	return connector, nil
+	return dsn.MySQL(user, pass, "tcp", endpoint.JoinHostPort(o.url, o.resolver.Port(dbapi.ResourceKindProxySQL, kubedb.ProxySQLAdminPort)), "", ""), nil
 }
@@ -21,6 +21,7 @@ import (
 	"errors"
 
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/attribute"
 	"k8s.io/klog/v2"
 )
 
@@ -29,9 +30,9 @@ func (c *AMQPClient) GetMessagingChannel() *Channel {
 	messagingCh, err := c.Channel()
 	if err != nil {
 		klog.Error(err, "Failed to Open a Messaging Channel")
-		return &Channel{nil}
+		return &Channel{telemetry: c.telemetry}
 	}
-	return &Channel{messagingCh}
+	return &Channel{Channel: messagingCh, telemetry: c.telemetry}
 }
 
 func (ch *Channel) GetNewQueue(name string, isDurable bool, isTypeQuoeum bool) (*amqp.Queue, error) {
@@ -58,6 +59,13 @@ func (ch *Channel) GetNewQueue(name string, isDurable bool, isTypeQuoeum bool) (
 }
 
 func (ch *Channel) PublishMessageOnce(ctx context.Context, queueName string, message string) error {
+	ctx, end := ch.telemetry.Start(ctx, "publish", attribute.String("messaging.destination.name", queueName))
+	err := ch.publishMessageOnce(ctx, queueName, message)
+	end(err)
+	return err
+}
+
+func (ch *Channel) publishMessageOnce(ctx context.Context, queueName string, message string) error {
 	// Declare a non-persistent queue, where messages will be sent
 	err := ch.PublishWithContext(
 		ctx,
@@ -77,6 +85,13 @@ func (ch *Channel) PublishMessageOnce(ctx context.Context, queueName string, mes
 }
 
 func (ch *Channel) ConsumeMessageOnce(ctx context.Context, cancelFunc context.CancelFunc, queueName string) error {
+	ctx, end := ch.telemetry.Start(ctx, "consume", attribute.String("messaging.destination.name", queueName))
+	err := ch.consumeMessageOnce(ctx, cancelFunc, queueName)
+	end(err)
+	return err
+}
+
+func (ch *Channel) consumeMessageOnce(ctx context.Context, cancelFunc context.CancelFunc, queueName string) error {
 	// start delivering messages through the channel,
 	// a  goroutine will be collecting messages with the context timeout
 	deliveryCh, err := ch.ConsumeWithContext(
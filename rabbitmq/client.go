@@ -17,6 +17,8 @@ limitations under the License.
 package rabbitmq
 
 import (
+	"kubedb.dev/db-client-go/internal/telemetry"
+
 	rmqhttp "github.com/michaelklishin/rabbit-hole/v3"
 	amqp "github.com/rabbitmq/amqp091-go"
 )
@@ -29,6 +31,7 @@ type Client struct {
 
 type AMQPClient struct {
 	*amqp.Connection
+	telemetry *telemetry.Telemetry
 }
 
 type HTTPClient struct {
@@ -37,6 +40,7 @@ type HTTPClient struct {
 
 type Channel struct {
 	*amqp.Channel
+	telemetry *telemetry.Telemetry
 }
 
 type ConnectionQueue struct {
@@ -20,15 +20,21 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"errors"
-	"fmt"
 	"net"
 	"net/http"
 	"strings"
 	"time"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
+
 	rmqhttp "github.com/michaelklishin/rabbit-hole/v3"
 	amqp "github.com/rabbitmq/amqp091-go"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
@@ -43,13 +49,17 @@ type KubeDBClientBuilder struct {
 	kc                client.Client
 	db                *olddbapi.RabbitMQ
 	ctx               context.Context
+	retryPolicy       *retry.Policy
 	amqpURL           string
 	httpURL           string
+	resolver          *endpoint.Resolver
 	podName           string
 	vhost             string
 	connName          string
 	enableHTTPClient  bool
 	disableAMQPClient bool
+	tracerProvider    trace.TracerProvider
+	meterProvider     metric.MeterProvider
 }
 
 const (
@@ -88,6 +98,13 @@ func (o *KubeDBClientBuilder) WithHTTPURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithVHost(vhost string) *KubeDBClientBuilder {
 	o.vhost = vhost
 	return o
@@ -103,6 +120,13 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithHTTPClientEnabled() *KubeDBClientBuilder {
 	o.enableHTTPClient = true
 	return o
@@ -113,21 +137,40 @@ func (o *KubeDBClientBuilder) WithAMQPClientDisabled() *KubeDBClientBuilder {
 	return o
 }
 
+// WithTracerProvider enables tracing of the builder phases and of the
+// instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases
+// and of the instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetRabbitMQClient() (*Client, error) {
 	if o.ctx == nil {
 		o.ctx = context.TODO()
 	}
+	tm := telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(olddbapi.ResourceKindRabbitmq, o.db.Namespace, o.db.Name, o.podName)...)
+
 	authSecret := &core.Secret{}
 	var username, password string
 	if !o.db.Spec.DisableSecurity {
-		err := o.kc.Get(o.ctx, types.NamespacedName{
-			Namespace: o.db.Namespace,
-			Name:      o.db.GetAuthSecretName(),
-		}, authSecret)
+		err := tm.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+			return o.kc.Get(ctx, types.NamespacedName{
+				Namespace: o.db.Namespace,
+				Name:      o.db.GetAuthSecretName(),
+			}, authSecret)
+		})
 		if err != nil {
 			if kerr.IsNotFound(err) {
 				klog.Error(err, "Auth-secret not found")
-				return nil, errors.New("auth-secret is not found")
+				return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "auth-secret is not found")
 			}
 			klog.Error(err, "Failed to get auth-secret")
 			return nil, err
@@ -139,39 +182,45 @@ func (o *KubeDBClientBuilder) GetRabbitMQClient() (*Client, error) {
 
 	var tlsConfig *tls.Config
 	if o.db.Spec.EnableSSL && !o.db.Spec.DisableSecurity {
-		certSecret := &core.Secret{}
-		err := o.kc.Get(o.ctx, types.NamespacedName{
-			Namespace: o.db.Namespace,
-			Name:      o.db.GetCertSecretName(olddbapi.RabbitmqClientCert),
-		}, certSecret)
-		if err != nil {
-			if kerr.IsNotFound(err) {
-				klog.Error(err, "Client certificate secret not found")
-				return nil, errors.New("client certificate secret is not found")
+		err := tm.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			certSecret := &core.Secret{}
+			err := o.kc.Get(ctx, types.NamespacedName{
+				Namespace: o.db.Namespace,
+				Name:      o.db.GetCertSecretName(olddbapi.RabbitmqClientCert),
+			}, certSecret)
+			if err != nil {
+				if kerr.IsNotFound(err) {
+					klog.Error(err, "Client certificate secret not found")
+					return dberrors.Errorf(dberrors.ErrSecretNotFound, "client certificate secret is not found")
+				}
+				klog.Error(err, "Failed to get client certificate Secret")
+				return err
 			}
-			klog.Error(err, "Failed to get client certificate Secret")
-			return nil, err
-		}
 
-		// get tls cert, clientCA and rootCA for tls config
-		clientCA := x509.NewCertPool()
-		rootCA := x509.NewCertPool()
+			// get tls cert, clientCA and rootCA for tls config
+			clientCA := x509.NewCertPool()
+			rootCA := x509.NewCertPool()
 
-		crt, err := tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
+			crt, err := tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
+			if err != nil {
+				klog.Error(err, "Failed to parse private key pair")
+				return err
+			}
+			clientCA.AppendCertsFromPEM(certSecret.Data[dbapi.TLSCACertFileName])
+			rootCA.AppendCertsFromPEM(certSecret.Data[dbapi.TLSCACertFileName])
+
+			tlsConfig = &tls.Config{
+				Certificates: []tls.Certificate{crt},
+				ClientAuth:   tls.RequireAndVerifyClientCert,
+				ClientCAs:    clientCA,
+				RootCAs:      rootCA,
+				MaxVersion:   tls.VersionTLS13,
+			}
+			return nil
+		})
 		if err != nil {
-			klog.Error(err, "Failed to parse private key pair")
 			return nil, err
 		}
-		clientCA.AppendCertsFromPEM(certSecret.Data[dbapi.TLSCACertFileName])
-		rootCA.AppendCertsFromPEM(certSecret.Data[dbapi.TLSCACertFileName])
-
-		tlsConfig = &tls.Config{
-			Certificates: []tls.Certificate{crt},
-			ClientAuth:   tls.RequireAndVerifyClientCert,
-			ClientCAs:    clientCA,
-			RootCAs:      rootCA,
-			MaxVersion:   tls.VersionTLS13,
-		}
 	}
 
 	rmqClient := &Client{}
@@ -199,10 +248,16 @@ func (o *KubeDBClientBuilder) GetRabbitMQClient() (*Client, error) {
 			return nil, err
 		}
 
-		vhosts, err := httpClient.ListVhosts()
+		var vhosts []rmqhttp.VhostInfo
+		err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+			return tm.Run(ctx, telemetry.OpPing, func(context.Context) error {
+				vhosts, err = httpClient.ListVhosts()
+				return err
+			})
+		})
 		if err != nil {
 			klog.Error(err, "Failed to list virtual hosts")
-			return nil, err
+			return nil, dberrors.Classify(err)
 		}
 		for _, vhost := range vhosts {
 			if vhost.Description == "Default virtual host" {
@@ -226,14 +281,21 @@ func (o *KubeDBClientBuilder) GetRabbitMQClient() (*Client, error) {
 			extraConfigProperties.SetClientConnectionName(o.connName)
 		}
 
-		rabbitConnection, err := amqp.DialConfig(o.amqpURL, amqp.Config{
-			Vhost:      o.vhost,
-			Locale:     "en_US",
-			Properties: extraConfigProperties,
+		var rabbitConnection *amqp.Connection
+		err := o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+			return tm.Run(ctx, telemetry.OpDial, func(context.Context) error {
+				var err error
+				rabbitConnection, err = amqp.DialConfig(o.amqpURL, amqp.Config{
+					Vhost:      o.vhost,
+					Locale:     "en_US",
+					Properties: extraConfigProperties,
+				})
+				return err
+			})
 		})
 		if err != nil {
 			klog.Error(err, "Failed to connect to rabbitmq")
-			return nil, err
+			return nil, dberrors.Classify(err)
 		}
 		klog.Info("Successfully created AMQP client for RabbitMQ")
 
@@ -244,15 +306,17 @@ func (o *KubeDBClientBuilder) GetRabbitMQClient() (*Client, error) {
 		}
 		klog.Info("Successfully created AMQP channel for RabbitMQ")
 
-		rmqClient.AMQPClient = AMQPClient{rabbitConnection}
-		rmqClient.Channel = Channel{msgChannel}
+		rmqClient.AMQPClient = AMQPClient{Connection: rabbitConnection, telemetry: tm}
+		rmqClient.Channel = Channel{Channel: msgChannel, telemetry: tm}
 	}
 
 	return rmqClient, nil
 }
 
 func (o *KubeDBClientBuilder) GetAMQPconnURL(username string, password string, vhost string) string {
-	return fmt.Sprintf("amqp://%s:%s@%s.%s.svc.cluster.local:%d%s", username, password, o.db.ServiceName(), o.db.Namespace, kubedb.RabbitMQAMQPPort, vhost)
+	// the service host ends with .svc.cluster.local unless the cluster domain is set
+	host := o.resolver.OrClusterDomain(endpoint.DefaultClusterDomain).ServiceAddress(olddbapi.ResourceKindRabbitmq, o.db.ServiceName(), o.db.Namespace, kubedb.RabbitMQAMQPPort)
+	return dsn.URL("amqp", username, password, host, vhost, nil)
 }
 
 func (o *KubeDBClientBuilder) GetHTTPconnURL() string {
@@ -264,10 +328,14 @@ func (o *KubeDBClientBuilder) GetHTTPconnURL() string {
 			return kubedb.RabbitMQManagementUIPortWithSSL
 		}
 	}(protocolScheme)
+	// the management port is not overridden by the port of the resolver,
+	// which is the AMQP port. The service host ends with .svc.cluster.local
+	// unless the cluster domain is set.
+	host := o.resolver.OrClusterDomain(endpoint.DefaultClusterDomain).ServiceHost(o.db.DashboardServiceName(), o.db.Namespace)
 	if o.podName != "" {
-		return fmt.Sprintf("%s://%s.%s.%s.svc:%d", protocolScheme, o.podName, o.db.GoverningServiceName(), o.db.Namespace, connectionPort)
+		host = o.resolver.PodHost(o.podName, o.db.GoverningServiceName(), o.db.Namespace)
 	}
-	return fmt.Sprintf("%s://%s.%s.svc.cluster.local:%d", protocolScheme, o.db.DashboardServiceName(), o.db.Namespace, connectionPort)
+	return endpoint.URL(protocolScheme, endpoint.JoinHostPort(host, connectionPort))
 }
 
 // RabbitMQ server have a default virtual host "/"
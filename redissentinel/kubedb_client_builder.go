@@ -20,14 +20,20 @@ import (
 	"context"
 	"crypto/tls"
 	"crypto/x509"
-	"errors"
 	"fmt"
 	"time"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
+
 	"kubedb.dev/apimachinery/apis/kubedb"
 	dbapi "kubedb.dev/apimachinery/apis/kubedb/v1"
 
 	rd "github.com/redis/go-redis/v9"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -40,10 +46,15 @@ const (
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *dbapi.RedisSentinel
-	podName string
-	url     string
+	kc             client.Client
+	db             *dbapi.RedisSentinel
+	podName        string
+	retryPolicy    *retry.Policy
+	url            string
+	resolver       *endpoint.Resolver
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.RedisSentinel) *KubeDBClientBuilder {
@@ -58,18 +69,46 @@ func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	o.url = url
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetRedisSentinelClient(ctx context.Context) (*Client, error) {
 	var err error
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindRedisSentinel, o.db.Namespace, o.db.Name, o.podName)...)
 	if o.podName != "" {
-		o.url = o.getSentinelPodURL()
+		o.url = o.resolver.PodAddress(dbapi.ResourceKindRedisSentinel, o.podName, o.db.GoverningServiceName(), o.db.Namespace, kubedb.RedisSentinelPort)
 	}
 	if o.url == "" {
-		o.url = o.db.Address()
+		o.url = o.resolver.ServiceAddress(dbapi.ResourceKindRedisSentinel, o.db.OffshootName(), o.db.Namespace, kubedb.RedisSentinelPort)
 	}
 	rdOpts := &rd.Options{
 		DialTimeout:     DefaultDialTimeout,
@@ -91,13 +130,17 @@ func (o *KubeDBClientBuilder) GetRedisSentinelClient(ctx context.Context) (*Clie
 		rdOpts.TLSConfig.InsecureSkipVerify = true
 	}
 	rdClient := rd.NewSentinelClient(rdOpts)
-	_, err = rdClient.Ping(ctx).Result()
+	err = o.retryPolicy.Do(ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			return rdClient.Ping(ctx).Err()
+		})
+	})
 	if err != nil {
 		closeErr := rdClient.Close()
 		if closeErr != nil {
 			klog.Errorf("Failed to close client. error: %v", closeErr)
 		}
-		return nil, fmt.Errorf("failed to connect to database: %v", err)
+		return nil, fmt.Errorf("failed to connect to database: %w", dberrors.Classify(err))
 	}
 	return &Client{
 		rdClient,
@@ -106,20 +149,25 @@ func (o *KubeDBClientBuilder) GetRedisSentinelClient(ctx context.Context) (*Clie
 
 func (o *KubeDBClientBuilder) getClientPassword(ctx context.Context) (string, error) {
 	if o.db.Spec.AuthSecret == nil || o.db.Spec.AuthSecret.Name == "" {
-		return "", errors.New("no database secret")
+		return "", dberrors.Errorf(dberrors.ErrSecretNotFound, "no database secret")
 	}
 	var authSecret core.Secret
-	err := o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.AuthSecret.Name}, &authSecret)
+	err := o.telemetry.Run(ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.Spec.AuthSecret.Name}, &authSecret)
+	})
 	if err != nil {
-		return "", err
+		return "", dberrors.SecretGetError(err)
 	}
 	clientPass := string(authSecret.Data[core.BasicAuthPasswordKey])
 	return clientPass, nil
 }
 
-func (o *KubeDBClientBuilder) getTLSConfig(ctx context.Context) (*tls.Config, error) {
+func (o *KubeDBClientBuilder) getTLSConfig(ctx context.Context) (_ *tls.Config, err error) {
+	ctx, end := o.telemetry.Start(ctx, telemetry.OpTLSLoad)
+	defer func() { end(err) }()
+
 	var sec core.Secret
-	err := o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.CertificateName(dbapi.RedisClientCert)}, &sec)
+	err = o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.Namespace, Name: o.db.CertificateName(dbapi.RedisClientCert)}, &sec)
 	if err != nil {
 		klog.Error(err, "error in getting the secret")
 		return nil, err
@@ -140,7 +188,3 @@ func (o *KubeDBClientBuilder) getTLSConfig(ctx context.Context) (*tls.Config, er
 	}
 	return clientTlS, nil
 }
-
-func (o *KubeDBClientBuilder) getSentinelPodURL() string {
-	return fmt.Sprintf("%v.%v.%v.svc:%d", o.podName, o.db.GoverningServiceName(), o.db.Namespace, kubedb.RedisSentinelPort)
-}
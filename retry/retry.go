@@ -0,0 +1,228 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package retry retries the connection establishment and the admin calls of
+// the database clients with exponential backoff.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"syscall"
+	"time"
+
+	"kubedb.dev/db-client-go/dberrors"
+
+	"k8s.io/klog/v2"
+)
+
+// ErrNotDone is returned by Poll when the condition is not met before the
+// policy gives up.
+var ErrNotDone = errors.New("condition not met")
+
+// Policy is an exponential backoff retry policy. The wait before the n-th
+// retry is InitialInterval * Multiplier^(n-1), capped at MaxInterval and
+// randomized by Jitter. Retrying stops after MaxAttempts attempts or
+// MaxElapsedTime, whichever comes first, or when the context is done.
+// With neither limit set, it retries until the context is done.
+//
+// A nil *Policy is valid and makes a single attempt.
+type Policy struct {
+	// InitialInterval is the wait before the first retry.
+	InitialInterval time.Duration
+	// Multiplier scales the wait after every retry. Values below 1 are
+	// treated as 1, i.e. a constant wait.
+	Multiplier float64
+	// MaxInterval caps the wait between retries, when set.
+	MaxInterval time.Duration
+	// Jitter randomizes every wait by up to this fraction, e.g. 0.2 for ±20%.
+	Jitter float64
+	// MaxElapsedTime stops retrying when the next attempt would start after
+	// this time since the first attempt, when set.
+	MaxElapsedTime time.Duration
+	// MaxAttempts stops retrying after this many attempts, when set.
+	MaxAttempts int
+	// Retryable reports whether an error is worth retrying.
+	// IsRetryable is used when it is nil.
+	Retryable func(err error) bool
+}
+
+// DefaultPolicy returns a policy that retries for up to a minute, waiting
+// from 500ms up to 10s between the attempts.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		InitialInterval: 500 * time.Millisecond,
+		Multiplier:      2,
+		MaxInterval:     10 * time.Second,
+		Jitter:          0.2,
+		MaxElapsedTime:  time.Minute,
+	}
+}
+
+// ConstantPolicy returns a policy that makes up to attempts attempts, waiting
+// interval between them.
+func ConstantPolicy(interval time.Duration, attempts int) *Policy {
+	return &Policy{
+		InitialInterval: interval,
+		Multiplier:      1,
+		MaxAttempts:     attempts,
+	}
+}
+
+// Backoff returns the wait before the given retry, starting from 1.
+func (p *Policy) Backoff(retry int) time.Duration {
+	if p == nil || retry < 1 {
+		return 0
+	}
+	multiplier := math.Max(p.Multiplier, 1)
+	wait := float64(p.InitialInterval) * math.Pow(multiplier, float64(retry-1))
+	if p.MaxInterval > 0 && wait > float64(p.MaxInterval) {
+		wait = float64(p.MaxInterval)
+	}
+	if p.Jitter > 0 {
+		wait += wait * p.Jitter * (2*rand.Float64() - 1)
+	}
+	if wait > math.MaxInt64 {
+		return time.Duration(math.MaxInt64)
+	}
+	return time.Duration(wait)
+}
+
+// MaxRetries returns the number of retries after the first attempt, 0 when
+// it is not limited by MaxAttempts.
+func (p *Policy) MaxRetries() int {
+	if p == nil || p.MaxAttempts < 1 {
+		return 0
+	}
+	return p.MaxAttempts - 1
+}
+
+func (p *Policy) isRetryable(err error) bool {
+	if p != nil && p.Retryable != nil {
+		var pe *permanentError
+		return !errors.As(err, &pe) && p.Retryable(err)
+	}
+	return IsRetryable(err)
+}
+
+// Do calls fn until it succeeds, it returns an error that is not retryable,
+// or the policy gives up. The last error of fn is returned.
+func (p *Policy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return p.Poll(ctx, func(ctx context.Context) (bool, error) {
+		err := fn(ctx)
+		return err == nil, err
+	})
+}
+
+// Poll calls condition until it is done, it returns an error that is not
+// retryable, or the policy gives up. ErrNotDone is returned when the last
+// call was not done without an error.
+func (p *Policy) Poll(ctx context.Context, condition func(ctx context.Context) (done bool, err error)) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	start := time.Now()
+	for attempt := 1; ; attempt++ {
+		done, err := condition(ctx)
+		if err == nil && done {
+			return nil
+		}
+		if err != nil && !p.isRetryable(err) {
+			var pe *permanentError
+			if errors.As(err, &pe) {
+				return pe.err
+			}
+			return err
+		}
+		if err == nil {
+			err = ErrNotDone
+		}
+
+		if p == nil || (p.MaxAttempts > 0 && attempt >= p.MaxAttempts) {
+			return err
+		}
+		wait := p.Backoff(attempt)
+		if p.MaxElapsedTime > 0 && time.Since(start)+wait > p.MaxElapsedTime {
+			return err
+		}
+		klog.V(5).Infof("Attempt %d failed, retrying in %v. error: %v", attempt, wait, err)
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w, last error: %w", ctx.Err(), err)
+		case <-timer.C:
+		}
+	}
+}
+
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks err as not retryable. The policies return err itself.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable reports whether err may be resolved by trying again. Errors
+// marked with Permanent, cancelled contexts and the failures that need a
+// change in the database or its secrets, i.e. rejected credentials, TLS
+// handshake failures, missing or invalid secrets and unsupported versions,
+// are not retryable. All other errors, e.g. an unreachable database or a
+// database that is still starting, are.
+func IsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	var pe *permanentError
+	if errors.As(err, &pe) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) || errors.Is(err, syscall.EPIPE) {
+		return true
+	}
+	err = dberrors.Classify(err)
+	for _, kind := range []error{
+		dberrors.ErrAuthRejected,
+		dberrors.ErrTLSHandshake,
+		dberrors.ErrSecretNotFound,
+		dberrors.ErrInvalidSecret,
+		dberrors.ErrUnsupportedVersion,
+	} {
+		if errors.Is(err, kind) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,244 @@
+/*
+Copyright AppsCode Inc. and Contributors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package retry
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"kubedb.dev/db-client-go/dberrors"
+)
+
+var errUnavailable = errors.New("connection refused")
+
+// counter returns a function that fails with err until the given attempt.
+func counter(err error, succeedAt int) (func(context.Context) error, *int) {
+	attempts := 0
+	return func(context.Context) error {
+		attempts++
+		if succeedAt > 0 && attempts >= succeedAt {
+			return nil
+		}
+		return err
+	}, &attempts
+}
+
+func TestDo(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    *Policy
+		err       error
+		succeedAt int
+		wantErr   error
+		attempts  int
+	}{
+		{
+			name:     "nil policy",
+			err:      errUnavailable,
+			wantErr:  errUnavailable,
+			attempts: 1,
+		},
+		{
+			name:      "success after retries",
+			policy:    ConstantPolicy(time.Millisecond, 5),
+			err:       errUnavailable,
+			succeedAt: 3,
+			attempts:  3,
+		},
+		{
+			name:     "max attempts",
+			policy:   ConstantPolicy(time.Millisecond, 3),
+			err:      errUnavailable,
+			wantErr:  errUnavailable,
+			attempts: 3,
+		},
+		{
+			name:     "auth rejected",
+			policy:   ConstantPolicy(time.Millisecond, 3),
+			err:      dberrors.Errorf(dberrors.ErrAuthRejected, "access denied"),
+			wantErr:  dberrors.ErrAuthRejected,
+			attempts: 1,
+		},
+		{
+			name:     "permanent",
+			policy:   ConstantPolicy(time.Millisecond, 3),
+			err:      Permanent(errUnavailable),
+			wantErr:  errUnavailable,
+			attempts: 1,
+		},
+		{
+			name: "custom retryable",
+			policy: &Policy{
+				InitialInterval: time.Millisecond,
+				MaxAttempts:     3,
+				Retryable: func(err error) bool {
+					return !errors.Is(err, errUnavailable)
+				},
+			},
+			err:      errUnavailable,
+			wantErr:  errUnavailable,
+			attempts: 1,
+		},
+		{
+			name: "max elapsed time",
+			policy: &Policy{
+				InitialInterval: 20 * time.Millisecond,
+				Multiplier:      1,
+				MaxElapsedTime:  30 * time.Millisecond,
+			},
+			err:      errUnavailable,
+			wantErr:  errUnavailable,
+			attempts: 2,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fn, attempts := counter(tt.err, tt.succeedAt)
+			err := tt.policy.Do(context.Background(), fn)
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("got error %v, want nil", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("got error %v, want %v", err, tt.wantErr)
+			}
+			if *attempts != tt.attempts {
+				t.Errorf("got %d attempts, want %d", *attempts, tt.attempts)
+			}
+		})
+	}
+}
+
+func TestPermanentIsUnwrapped(t *testing.T) {
+	err := ConstantPolicy(time.Millisecond, 3).Do(context.Background(), func(context.Context) error {
+		return Permanent(errUnavailable)
+	})
+	if err != errUnavailable {
+		t.Errorf("got error %#v, want the error marked as permanent", err)
+	}
+}
+
+func TestDoContextDone(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := ConstantPolicy(time.Hour, 0)
+	done := make(chan error, 1)
+	go func() {
+		done <- policy.Do(ctx, func(context.Context) error {
+			return errUnavailable
+		})
+	}()
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) || !errors.Is(err, errUnavailable) {
+			t.Errorf("got error %v, want the context error and the last error", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Do did not return after the context was cancelled")
+	}
+}
+
+func TestPoll(t *testing.T) {
+	calls := 0
+	err := ConstantPolicy(time.Millisecond, 5).Poll(context.Background(), func(context.Context) (bool, error) {
+		calls++
+		return calls == 3, nil
+	})
+	if err != nil || calls != 3 {
+		t.Errorf("got error %v after %d calls, want nil after 3", err, calls)
+	}
+
+	err = ConstantPolicy(time.Millisecond, 2).Poll(context.Background(), func(context.Context) (bool, error) {
+		return false, nil
+	})
+	if !errors.Is(err, ErrNotDone) {
+		t.Errorf("got error %v, want %v", err, ErrNotDone)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := &Policy{
+		InitialInterval: 100 * time.Millisecond,
+		Multiplier:      2,
+		MaxInterval:     time.Second,
+	}
+	for retry, want := range map[int]time.Duration{
+		0: 0,
+		1: 100 * time.Millisecond,
+		2: 200 * time.Millisecond,
+		4: 800 * time.Millisecond,
+		5: time.Second,
+		9: time.Second,
+	} {
+		if got := p.Backoff(retry); got != want {
+			t.Errorf("Backoff(%d) = %v, want %v", retry, got, want)
+		}
+	}
+
+	p.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		if got := p.Backoff(1); got < 50*time.Millisecond || got > 150*time.Millisecond {
+			t.Fatalf("Backoff(1) = %v with jitter, want within 50ms-150ms", got)
+		}
+	}
+
+	var nilPolicy *Policy
+	if got := nilPolicy.Backoff(1); got != 0 {
+		t.Errorf("got backoff %v for a nil policy, want 0", got)
+	}
+}
+
+func TestMaxRetries(t *testing.T) {
+	var nilPolicy *Policy
+	for _, tt := range []struct {
+		policy *Policy
+		want   int
+	}{
+		{policy: nilPolicy, want: 0},
+		{policy: DefaultPolicy(), want: 0},
+		{policy: ConstantPolicy(time.Second, 1), want: 0},
+		{policy: ConstantPolicy(time.Second, 4), want: 3},
+	} {
+		if got := tt.policy.MaxRetries(); got != tt.want {
+			t.Errorf("got %d retries, want %d", got, tt.want)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "nil", err: nil, want: false},
+		{name: "unavailable", err: errUnavailable, want: true},
+		{name: "cancelled", err: context.Canceled, want: false},
+		{name: "permanent", err: Permanent(errUnavailable), want: false},
+		{name: "invalid secret", err: dberrors.Errorf(dberrors.ErrInvalidSecret, "password is missing"), want: false},
+		{name: "service unavailable", err: dberrors.HTTPStatusError(http.StatusServiceUnavailable, "not ready"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := IsRetryable(tt.err); got != tt.want {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tt.err, got, tt.want)
+			}
+		})
+	}
+}
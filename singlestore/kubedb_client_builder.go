@@ -22,9 +22,15 @@ import (
 	"crypto/x509"
 	"database/sql"
 
-	"fmt"
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/dsn"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/retry"
 
 	sql_driver "github.com/go-sql-driver/mysql"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	"k8s.io/klog/v2"
 	"kubedb.dev/apimachinery/apis/kubedb"
@@ -34,11 +40,20 @@ import (
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *olddbapi.Singlestore
-	url     string
-	podName string
-	ctx     context.Context
+	kc             client.Client
+	db             *olddbapi.Singlestore
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	serverName     string
+	ctx            context.Context
+	retryPolicy    *retry.Policy
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	telemetry      *telemetry.Telemetry
+	// tlsConfigKey is the name of the TLS config registered by the current
+	// build.
+	tlsConfigKey string
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *olddbapi.Singlestore) *KubeDBClientBuilder {
@@ -53,6 +68,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithPod(podName string) *KubeDBClientBuilder {
 	o.podName = podName
 	return o
@@ -63,11 +85,40 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithRetryPolicy retries the connection to the database with the policy.
+// The connection is tried once by default.
+func (o *KubeDBClientBuilder) WithRetryPolicy(p *retry.Policy) *KubeDBClientBuilder {
+	o.retryPolicy = p
+	return o
+}
+
+// WithServerName sets the name used to verify the server certificate.
+// By default, the host of the connection address is verified.
+func (o *KubeDBClientBuilder) WithServerName(serverName string) *KubeDBClientBuilder {
+	o.serverName = serverName
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetSinglestoreClient() (*Client, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(olddbapi.ResourceKindSinglestore, o.db.Namespace, o.db.Name, o.podName)...)
 
+	defer o.deregisterTLSConfig()
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -80,12 +131,14 @@ func (o *KubeDBClientBuilder) GetSinglestoreClient() (*Client, error) {
 	}
 
 	// ping to database to check the connection
-	if err := db.PingContext(o.ctx); err != nil {
+	if err := o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, db.PingContext)
+	}); err != nil {
 		closeErr := db.Close()
 		if closeErr != nil {
 			klog.Errorf("Failed to close client. error: %v", closeErr)
 		}
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	return &Client{db}, nil
@@ -95,6 +148,9 @@ func (o *KubeDBClientBuilder) GetSinglestoreXormClient() (*XormClient, error) {
 	if o.ctx == nil {
 		o.ctx = context.Background()
 	}
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(olddbapi.ResourceKindSinglestore, o.db.Namespace, o.db.Name, o.podName)...)
+	defer o.deregisterTLSConfig()
 	connector, err := o.getConnectionString()
 	if err != nil {
 		return nil, err
@@ -103,9 +159,14 @@ func (o *KubeDBClientBuilder) GetSinglestoreXormClient() (*XormClient, error) {
 	if err != nil {
 		return nil, err
 	}
-	_, err = engine.Query("SELECT 1")
+	err = o.retryPolicy.Do(o.ctx, func(ctx context.Context) error {
+		return o.telemetry.Run(ctx, telemetry.OpPing, func(ctx context.Context) error {
+			_, err := engine.Query("SELECT 1")
+			return err
+		})
+	})
 	if err != nil {
-		return nil, err
+		return nil, dberrors.Classify(err)
 	}
 
 	engine.SetDefaultContext(o.ctx)
@@ -114,10 +175,6 @@ func (o *KubeDBClientBuilder) GetSinglestoreXormClient() (*XormClient, error) {
 	}, nil
 }
 
-func (o *KubeDBClientBuilder) getURL() string {
-	return fmt.Sprintf("%s.%s.%s.svc", o.podName, o.db.GoverningServiceName(), o.db.Namespace)
-}
-
 func (o *KubeDBClientBuilder) getSinglestoreRootCredentials() (string, string, error) {
 	db := o.db
 	var secretName string
@@ -125,21 +182,40 @@ func (o *KubeDBClientBuilder) getSinglestoreRootCredentials() (string, string, e
 		secretName = db.GetAuthSecretName()
 	}
 	var secret core.Secret
-	err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+		return o.kc.Get(ctx, client.ObjectKey{Namespace: db.Namespace, Name: secretName}, &secret)
+	})
 	if err != nil {
-		return "", "", err
+		return "", "", dberrors.SecretGetError(err)
 	}
 	user, ok := secret.Data[core.BasicAuthUsernameKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root user is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root user is not set")
 	}
 	pass, ok := secret.Data[core.BasicAuthPasswordKey]
 	if !ok {
-		return "", "", fmt.Errorf("DB root password is not set")
+		return "", "", dberrors.Errorf(dberrors.ErrInvalidSecret, "DB root password is not set")
 	}
 	return string(user), string(pass), nil
 }
 
+// getTLSConfigKey returns the name the TLS config of this database is
+// registered with, so that databases with different CAs don't overwrite
+// each other's config.
+func (o *KubeDBClientBuilder) getTLSConfigKey() string {
+	return dsn.MySQLTLSConfigKey(kubedb.SinglestoreTLSConfigCustom, o.db.Namespace, o.db.Name)
+}
+
+// deregisterTLSConfig removes the TLS config registered by
+// getConnectionString. The driver clones the config when the database is
+// opened, so it is not used afterwards.
+func (o *KubeDBClientBuilder) deregisterTLSConfig() {
+	if o.tlsConfigKey != "" {
+		sql_driver.DeregisterTLSConfig(o.tlsConfigKey)
+		o.tlsConfigKey = ""
+	}
+}
+
 func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	user, pass, err := o.getSinglestoreRootCredentials()
 	if err != nil {
@@ -147,41 +223,48 @@ func (o *KubeDBClientBuilder) getConnectionString() (string, error) {
 	}
 
 	if o.podName != "" {
-		o.url = o.getURL()
+		o.url = o.resolver.PodHost(o.podName, o.db.GoverningServiceName(), o.db.Namespace)
 	}
 
 	tlsConfig := ""
 	if o.db.Spec.TLS != nil {
-		// get client-secret
-		var clientSecret core.Secret
-		err := o.kc.Get(o.ctx, client.ObjectKey{Namespace: o.db.GetNamespace(), Name: o.db.GetCertSecretName(olddbapi.SinglestoreClientCert)}, &clientSecret)
-		if err != nil {
-			return "", err
-		}
-		cacrt := clientSecret.Data["ca.crt"]
-		certPool := x509.NewCertPool()
-		certPool.AppendCertsFromPEM(cacrt)
+		err := o.telemetry.Run(o.ctx, telemetry.OpTLSLoad, func(ctx context.Context) error {
+			// get client-secret
+			var clientSecret core.Secret
+			err := o.kc.Get(ctx, client.ObjectKey{Namespace: o.db.GetNamespace(), Name: o.db.GetCertSecretName(olddbapi.SinglestoreClientCert)}, &clientSecret)
+			if err != nil {
+				return dberrors.SecretGetError(err)
+			}
+			cacrt := clientSecret.Data["ca.crt"]
+			certPool := x509.NewCertPool()
+			certPool.AppendCertsFromPEM(cacrt)
 
-		crt := clientSecret.Data["tls.crt"]
-		key := clientSecret.Data["tls.key"]
-		cert, err := tls.X509KeyPair(crt, key)
-		if err != nil {
-			return "", err
-		}
-		var clientCert []tls.Certificate
-		clientCert = append(clientCert, cert)
+			crt := clientSecret.Data["tls.crt"]
+			key := clientSecret.Data["tls.key"]
+			cert, err := tls.X509KeyPair(crt, key)
+			if err != nil {
+				return err
+			}
+			var clientCert []tls.Certificate
+			clientCert = append(clientCert, cert)
 
-		// tls custom setup
-		err = sql_driver.RegisterTLSConfig(kubedb.SinglestoreTLSConfigCustom, &tls.Config{
-			RootCAs:      certPool,
-			Certificates: clientCert,
+			// tls custom setup, registered under a key unique to this database
+			tlsConfig = o.getTLSConfigKey()
+			o.tlsConfigKey = tlsConfig
+			err = sql_driver.RegisterTLSConfig(tlsConfig, &tls.Config{
+				RootCAs:      certPool,
+				Certificates: clientCert,
+				ServerName:   o.serverName,
+			})
+			if err != nil {
+				return err
+			}
+			return nil
 		})
 		if err != nil {
 			return "", err
 		}
-		tlsConfig = fmt.Sprintf("tls=%s", kubedb.SinglestoreTLSConfigCustom)
 	}
 
-	connector := fmt.Sprintf("%v:%v@tcp(%s:%d)/%s?%s", user, pass, o.url, 3306, "memsql", tlsConfig)
-	return connector, nil
+	return dsn.MySQL(user, pass, "tcp", endpoint.JoinHostPort(o.url, o.resolver.Port(olddbapi.ResourceKindSinglestore, kubedb.SinglestoreDatabasePort)), "memsql", tlsConfig), nil
 }
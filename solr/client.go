@@ -21,6 +21,8 @@ import (
 	"io"
 	"net/http"
 
+	"kubedb.dev/db-client-go/internal/telemetry"
+
 	"github.com/go-logr/logr"
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -28,6 +30,7 @@ import (
 
 type Client struct {
 	SLClient
+	telemetry *telemetry.Telemetry
 }
 
 type ClientOptions struct {
@@ -25,6 +25,11 @@ import (
 	"net/http"
 	"time"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+
 	"k8s.io/klog/v2"
 
 	"github.com/Masterminds/semver/v3"
@@ -33,21 +38,27 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"kubedb.dev/apimachinery/apis/kubedb"
 
-	"fmt"
-
 	"github.com/go-logr/logr"
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+	"k8s.io/client-go/rest"
 	api "kubedb.dev/apimachinery/apis/kubedb/v1alpha2"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 type KubeDBClientBuilder struct {
-	kc      client.Client
-	db      *api.Solr
-	url     string
-	podName string
-	ctx     context.Context
-	log     logr.Logger
+	kc             client.Client
+	db             *api.Solr
+	url            string
+	resolver       *endpoint.Resolver
+	podName        string
+	ctx            context.Context
+	log            logr.Logger
+	tracerProvider trace.TracerProvider
+	meterProvider  metric.MeterProvider
+	dialer         portforward.DialFunc
+	restConfig     *rest.Config
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *api.Solr) *KubeDBClientBuilder {
@@ -67,6 +78,13 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithLog(log logr.Logger) *KubeDBClientBuilder {
 	o.log = log
 	return o
@@ -77,9 +95,39 @@ func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuil
 	return o
 }
 
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
+// WithTracerProvider enables tracing of the builder phases and of the
+// instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases
+// and of the instrumented helpers of the returned client.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetSolrClient() (*Client, error) {
+	// the hosts end with .svc.cluster.local unless the cluster domain is set
 	if o.podName != "" {
-		o.url = fmt.Sprintf("%v://%s.%s.%s.svc.cluster.local:%d", o.db.GetConnectionScheme(), o.podName, o.db.GoverningServiceName(), o.db.GetNamespace(), kubedb.SolrRestPort)
+		o.url = o.resolver.OrClusterDomain(endpoint.DefaultClusterDomain).PodURL(o.db.GetConnectionScheme(), api.ResourceKindSolr, o.podName, o.db.GoverningServiceName(), o.db.GetNamespace(), kubedb.SolrRestPort)
 	}
 	if o.url == "" {
 		o.url = o.GetHostPath(o.db)
@@ -87,6 +135,8 @@ func (o *KubeDBClientBuilder) GetSolrClient() (*Client, error) {
 	if o.db == nil {
 		return nil, errors.New("db is empty")
 	}
+	tm := telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(api.ResourceKindSolr, o.db.Namespace, o.db.Name, o.podName)...)
 	config := Config{
 		host: o.url,
 		transport: &http.Transport{
@@ -103,16 +153,27 @@ func (o *KubeDBClientBuilder) GetSolrClient() (*Client, error) {
 		log:              o.log,
 	}
 
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	if dial != nil {
+		config.transport.DialContext = dial
+	}
+
 	// If EnableSSL is true set tls config,
 	// provide client certs and root CA
 	if o.db.Spec.EnableSSL {
+		ctx, end := tm.Start(o.ctx, telemetry.OpTLSLoad)
 		var certSecret core.Secret
-		err := o.kc.Get(o.ctx, types.NamespacedName{
+		err := o.kc.Get(ctx, types.NamespacedName{
 			Namespace: o.db.Namespace,
 			Name:      o.db.GetCertSecretName(api.SolrClientCert),
 		}, &certSecret)
 		if err != nil {
 			klog.Error(err, "failed to get serverCert secret")
+			err = dberrors.SecretGetError(err)
+			end(err)
 			return nil, err
 		}
 
@@ -124,6 +185,7 @@ func (o *KubeDBClientBuilder) GetSolrClient() (*Client, error) {
 		crt, err := tls.X509KeyPair(certSecret.Data[core.TLSCertKey], certSecret.Data[core.TLSPrivateKeyKey])
 		if err != nil {
 			klog.Error(err, "failed to create certificate for TLS config")
+			end(err)
 			return nil, err
 		}
 		clientCA.AppendCertsFromPEM(certSecret.Data[kubedb.CACert])
@@ -136,17 +198,20 @@ func (o *KubeDBClientBuilder) GetSolrClient() (*Client, error) {
 			RootCAs:      rootCA,
 			MaxVersion:   tls.VersionTLS13,
 		}
+		end(nil)
 	}
 
 	var authSecret core.Secret
 	if !o.db.Spec.DisableSecurity {
-		err := o.kc.Get(o.ctx, types.NamespacedName{
-			Name:      o.db.GetAuthSecretName(),
-			Namespace: o.db.Namespace,
-		}, &authSecret)
+		err := tm.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+			return o.kc.Get(ctx, types.NamespacedName{
+				Name:      o.db.GetAuthSecretName(),
+				Namespace: o.db.Namespace,
+			}, &authSecret)
+		})
 		if err != nil {
 			config.log.Error(err, "failed to get auth secret to get solr client")
-			return nil, err
+			return nil, dberrors.SecretGetError(err)
 		}
 	}
 	version, err := semver.NewVersion(o.db.Spec.Version)
@@ -163,10 +228,11 @@ func (o *KubeDBClientBuilder) GetSolrClient() (*Client, error) {
 		newClient.SetDisableWarn(true)
 		newClient.SetBasicAuth(string(authSecret.Data[core.BasicAuthUsernameKey]), string(authSecret.Data[core.BasicAuthPasswordKey]))
 		return &Client{
-			&SLClientV9{
+			SLClient: &SLClientV9{
 				Client: newClient,
 				Config: &config,
 			},
+			telemetry: tm,
 		}, nil
 	case version.Major() == 8:
 		newClient := resty.New()
@@ -176,17 +242,18 @@ func (o *KubeDBClientBuilder) GetSolrClient() (*Client, error) {
 		newClient.SetDisableWarn(true)
 		newClient.SetBasicAuth(string(authSecret.Data[core.BasicAuthUsernameKey]), string(authSecret.Data[core.BasicAuthPasswordKey]))
 		return &Client{
-			&SLClientV8{
+			SLClient: &SLClientV8{
 				Client: newClient,
 				Config: &config,
 			},
+			telemetry: tm,
 		}, nil
 	}
 
-	return nil, fmt.Errorf("unknown version: %s", o.db.Spec.Version)
+	return nil, dberrors.Errorf(dberrors.ErrUnsupportedVersion, "unknown version: %s", o.db.Spec.Version)
 
 }
 
 func (o *KubeDBClientBuilder) GetHostPath(db *api.Solr) string {
-	return fmt.Sprintf("%v://%s.%s.svc.cluster.local:%d", db.GetConnectionScheme(), db.ServiceName(), db.GetNamespace(), kubedb.SolrRestPort)
+	return o.resolver.OrClusterDomain(endpoint.DefaultClusterDomain).ServiceURL(db.GetConnectionScheme(), api.ResourceKindSolr, db.ServiceName(), db.GetNamespace(), kubedb.SolrRestPort)
 }
@@ -17,6 +17,7 @@ limitations under the License.
 package solr
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -302,6 +303,12 @@ func (sc *Client) CleanupAsync(async string) error {
 }
 
 func (sc *Client) Balance() error {
+	return sc.telemetry.Run(context.Background(), "balance", func(context.Context) error {
+		return sc.balance()
+	})
+}
+
+func (sc *Client) balance() error {
 	var errr error
 	async := "balance-replica"
 	err := sc.CleanupAsync(async)
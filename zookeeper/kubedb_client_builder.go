@@ -18,14 +18,22 @@ package zookeeper
 
 import (
 	"context"
-	"errors"
 	"fmt"
 	"log"
+	"net"
 	"time"
 
+	"kubedb.dev/db-client-go/dberrors"
+	"kubedb.dev/db-client-go/endpoint"
+	"kubedb.dev/db-client-go/internal/telemetry"
+	"kubedb.dev/db-client-go/portforward"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 	core "k8s.io/api/core/v1"
 	kerr "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
 	"github.com/Shopify/zk"
@@ -45,8 +53,14 @@ type KubeDBClientBuilder struct {
 	ctx               context.Context
 	podName           string
 	url               string
+	resolver          *endpoint.Resolver
 	enableHTTPClient  bool
 	disableAMQPClient bool
+	dialer            portforward.DialFunc
+	restConfig        *rest.Config
+	tracerProvider    trace.TracerProvider
+	meterProvider     metric.MeterProvider
+	telemetry         *telemetry.Telemetry
 }
 
 func NewKubeDBClientBuilder(kc client.Client, db *dbapi.ZooKeeper) *KubeDBClientBuilder {
@@ -74,11 +88,33 @@ func (o *KubeDBClientBuilder) WithURL(url string) *KubeDBClientBuilder {
 	return o
 }
 
+// WithResolver sets the resolver of the in-cluster addresses, e.g. to use a
+// custom cluster domain or port.
+func (o *KubeDBClientBuilder) WithResolver(r *endpoint.Resolver) *KubeDBClientBuilder {
+	o.resolver = r
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithContext(ctx context.Context) *KubeDBClientBuilder {
 	o.ctx = ctx
 	return o
 }
 
+// WithDialer sets the function used to open the connections to the database,
+// e.g. to reach it from outside the cluster.
+func (o *KubeDBClientBuilder) WithDialer(dial portforward.DialFunc) *KubeDBClientBuilder {
+	o.dialer = dial
+	return o
+}
+
+// WithPortForward tunnels the connections to the database through the
+// port-forward API of the Kubernetes API server. It is ignored when a dialer
+// is set with WithDialer.
+func (o *KubeDBClientBuilder) WithPortForward(config *rest.Config) *KubeDBClientBuilder {
+	o.restConfig = config
+	return o
+}
+
 func (o *KubeDBClientBuilder) WithHTTPClientEnabled() *KubeDBClientBuilder {
 	o.enableHTTPClient = true
 	return o
@@ -89,39 +125,71 @@ func (o *KubeDBClientBuilder) WithAMQPClientDisabled() *KubeDBClientBuilder {
 	return o
 }
 
+// WithTracerProvider enables tracing of the builder phases.
+func (o *KubeDBClientBuilder) WithTracerProvider(tp trace.TracerProvider) *KubeDBClientBuilder {
+	o.tracerProvider = tp
+	return o
+}
+
+// WithMeterProvider enables latency and error metrics of the builder phases.
+func (o *KubeDBClientBuilder) WithMeterProvider(mp metric.MeterProvider) *KubeDBClientBuilder {
+	o.meterProvider = mp
+	return o
+}
+
 func (o *KubeDBClientBuilder) GetZooKeeperClient() (*Client, error) {
 	var err error
+	o.telemetry = telemetry.New(o.tracerProvider, o.meterProvider,
+		telemetry.Attributes(dbapi.ResourceKindZooKeeper, o.db.Namespace, o.db.Name, o.podName)...)
 	if o.podName != "" {
-		o.url = o.getPodURL()
+		o.url = o.resolver.PodAddress(dbapi.ResourceKindZooKeeper, o.podName, o.db.GoverningServiceName(), o.db.Namespace, kubedb.ZooKeeperClientPort)
 	}
 	if o.url == "" {
-		o.url = o.db.Address()
+		o.url = o.resolver.ServiceAddress(dbapi.ResourceKindZooKeeper, o.db.ServiceName(), o.db.Namespace, kubedb.ZooKeeperClientPort)
 	}
-	zkConn, session, err := zk.Connect([]string{o.url}, defaultZooKeeperTimeout, zk.WithLogInfo(false))
+	var dialer zk.Dialer = net.DialTimeout
+	dial, err := portforward.DialFuncFor(o.dialer, o.restConfig, o.kc, o.db.Namespace)
 	if err != nil {
 		return nil, err
 	}
-	for event := range session {
-		if event.State == zk.StateConnected {
-			break
+	if dial != nil {
+		dialer = dial.DialTimeout
+	}
+	var zkConn *zk.Conn
+	err = o.telemetry.Run(o.ctx, telemetry.OpDial, func(context.Context) error {
+		conn, session, err := zk.Connect([]string{o.url}, defaultZooKeeperTimeout, zk.WithLogInfo(false), zk.WithDialer(dialer))
+		if err != nil {
+			return dberrors.Classify(err)
 		}
+		for event := range session {
+			if event.State == zk.StateConnected {
+				break
+			}
+		}
+		zkConn = conn
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	if !o.db.Spec.DisableAuth {
 		if o.db.Spec.AuthSecret == nil {
 			klog.Info("Auth-secret not set")
-			return nil, errors.New("auth-secret is not set")
+			return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "auth-secret is not set")
 		}
 
 		authSecret := core.Secret{}
-		err := o.kc.Get(o.ctx, types.NamespacedName{
-			Namespace: o.db.Namespace,
-			Name:      o.db.Spec.AuthSecret.Name,
-		}, &authSecret)
+		err := o.telemetry.Run(o.ctx, telemetry.OpSecretFetch, func(ctx context.Context) error {
+			return o.kc.Get(ctx, types.NamespacedName{
+				Namespace: o.db.Namespace,
+				Name:      o.db.Spec.AuthSecret.Name,
+			}, &authSecret)
+		})
 		if err != nil {
 			if kerr.IsNotFound(err) {
 				klog.Error(err, "Auth-secret not found")
-				return nil, errors.New("auth-secret is not found")
+				return nil, dberrors.Errorf(dberrors.ErrSecretNotFound, "auth-secret is not found")
 			}
 			klog.Error(err, "Failed to get auth-secret")
 			return nil, err
@@ -145,7 +213,3 @@ func (o *KubeDBClientBuilder) GetZooKeeperClient() (*Client, error) {
 		zkConn,
 	}, nil
 }
-
-func (o *KubeDBClientBuilder) getPodURL() string {
-	return fmt.Sprintf("%v.%v.%v.svc:%d", o.podName, o.db.GoverningServiceName(), o.db.Namespace, kubedb.ZooKeeperClientPort)
-}